@@ -0,0 +1,130 @@
+package authentication
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+	authz "todo-web-api/authorization"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AccessTokenPrefix marks personal access tokens so AuthMiddleware can tell
+// them from JWTs, and so they're easy to spot by secret scanners.
+const AccessTokenPrefix = "todo_pat_"
+
+const (
+	DefaultAccessTokenDays = 30
+	MaxAccessTokenDays     = 365
+	// Last-used timestamps are only written this often, so a busy script
+	// doesn't turn every request into a write.
+	lastUsedResolution = time.Minute
+)
+
+func IsAccessToken(token string) bool {
+	return strings.HasPrefix(token, AccessTokenPrefix)
+}
+
+// CreateAccessToken issues a token for user limited to scopes, each of which
+// must be a permission the user's role grants. The plain token is only
+// returned here; the database keeps its digest.
+func CreateAccessToken(user *models.User, name string, scopes []string, expiresInDays int) (string, *models.PersonalAccessToken, error) {
+	if expiresInDays < 1 || expiresInDays > MaxAccessTokenDays {
+		return "", nil, errors.New(messages.InvalidTokenExpiry)
+	}
+	granted := authz.PermissionsFor(Roles(user)...)
+	if len(scopes) == 0 {
+		return "", nil, errors.New(messages.InvalidScope)
+	}
+	for _, scope := range scopes {
+		if !authz.IsScopable(scope) || !authz.HasPermission(granted, scope) {
+			return "", nil, errors.New(messages.InvalidScope)
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, err
+	}
+	plain := AccessTokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	token := &models.PersonalAccessToken{
+		UserId:    user.Id,
+		Name:      name,
+		TokenHash: hashToken(plain),
+		Prefix:    plain[:len(AccessTokenPrefix)+4],
+		Scopes:    strings.Join(scopes, " "),
+		ExpiresAt: time.Now().AddDate(0, 0, expiresInDays),
+	}
+	if _, err := storage.AccessTokenManager.CreateAccessToken(token); err != nil {
+		return "", nil, err
+	}
+	return plain, token, nil
+}
+
+// AuthenticateAccessToken resolves a bearer personal access token to its
+// owner and the permissions the request gets: the token's scopes, narrowed
+// to what the owner's role allows right now.
+func AuthenticateAccessToken(plain string) (*models.User, []string, error) {
+	_, user, permissions, err := lookupAccessToken(plain)
+	return user, permissions, err
+}
+
+// lookupAccessToken is AuthenticateAccessToken, also returning the token
+// record itself.
+func lookupAccessToken(plain string) (*models.PersonalAccessToken, *models.User, []string, error) {
+	token, err := storage.AccessTokenManager.GetAccessTokenByHash(hashToken(plain))
+	if err != nil && err.Error() == messages.AccessTokenNotFoundInDb {
+		return nil, nil, nil, errors.New(messages.InvalidAccessToken)
+	} else if err != nil {
+		return nil, nil, nil, err
+	}
+
+	now := time.Now()
+	if !now.Before(token.ExpiresAt) {
+		return nil, nil, nil, errors.New(messages.InvalidAccessToken)
+	}
+
+	user, err := storage.UserManager.GetUser(token.UserId)
+	if err != nil {
+		return nil, nil, nil, errors.New(messages.InvalidAccessToken)
+	}
+	if user.IsDisabled {
+		return nil, nil, nil, errors.New(messages.AccountDisabled)
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > lastUsedResolution {
+		if err := storage.AccessTokenManager.TouchAccessToken(token.Id, now); err != nil {
+			log.WithFields(logrus.Fields{"LoggerName": "AccessTokens"}).Error(err.Error())
+		}
+	}
+
+	scopes := strings.Fields(token.Scopes)
+	return token, user, authz.Intersect(authz.PermissionsFor(Roles(user)...), scopes), nil
+}
+
+func GetAccessTokens(userId int) ([]models.PersonalAccessToken, error) {
+	return storage.AccessTokenManager.GetAccessTokensForUser(userId)
+}
+
+// RevokeAccessToken returns false when the token doesn't exist or belongs
+// to another user.
+func RevokeAccessToken(userId int, id int) (bool, error) {
+	return storage.AccessTokenManager.DeleteAccessToken(userId, id)
+}
+
+func PurgeExpiredAccessTokens() {
+	count, err := storage.AccessTokenManager.DeleteExpiredAccessTokens(time.Now())
+	if err != nil {
+		log.WithFields(logrus.Fields{"LoggerName": "AccessTokens"}).Error(err.Error())
+		return
+	}
+	if count > 0 {
+		log.WithFields(logrus.Fields{"LoggerName": "AccessTokens", "Count": count}).Info("expired access tokens removed")
+	}
+}
@@ -0,0 +1,89 @@
+package authentication
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"todo-web-api/mailer"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+
+	"github.com/sirupsen/logrus"
+)
+
+// accountDeletionGrace is how long a deletion request can still be
+// cancelled; zero deletes the account straight away.
+var accountDeletionGrace time.Duration
+
+func ConfigureAccountDeletion(grace time.Duration) {
+	if grace < 0 {
+		grace = 0
+	}
+	accountDeletionGrace = grace
+}
+
+// DeleteAccount deletes user and everything they own once password checks
+// out. With a grace period configured the deletion is only scheduled, and
+// the time it goes through is returned; nil means it's already done.
+func DeleteAccount(user *models.User, password string) (*time.Time, error) {
+	if match, _ := VerifyPassword(user.Password, password); !match {
+		return nil, errors.New(messages.InvalidCurrentPassword)
+	}
+
+	if accountDeletionGrace == 0 {
+		_, err := storage.UserManager.DeleteUser(user.Id)
+		return nil, err
+	}
+
+	// Asking again doesn't push a pending deletion further out.
+	if user.DeletionScheduledAt != nil {
+		return user.DeletionScheduledAt, nil
+	}
+	deleteAt := time.Now().Add(accountDeletionGrace)
+	user.DeletionScheduledAt = &deleteAt
+	if _, err := storage.UserManager.UpdateUser(user); err != nil {
+		return nil, err
+	}
+
+	if to := mailAddress(user); to != "" {
+		err := mailer.Send(mailer.Message{
+			To:      to,
+			Subject: "Your account is scheduled for deletion",
+			Body: fmt.Sprintf("The account %s and all of its lists and tasks will be deleted on %s.\n\n"+
+				"To keep it, sign in and cancel the deletion before then.\n",
+				user.Username, deleteAt.UTC().Format(time.RFC1123)),
+		})
+		if err != nil {
+			log.WithFields(logrus.Fields{"LoggerName": "AccountDeletion", "UserId": user.Id}).Error(err.Error())
+		}
+	}
+	return &deleteAt, nil
+}
+
+// CancelAccountDeletion keeps an account whose deletion is still pending.
+func CancelAccountDeletion(user *models.User) error {
+	if user.DeletionScheduledAt == nil {
+		return errors.New(messages.AccountDeletionNotScheduled)
+	}
+	user.DeletionScheduledAt = nil
+	_, err := storage.UserManager.UpdateUser(user)
+	return err
+}
+
+// PurgeScheduledAccountDeletions deletes the accounts whose grace period
+// has run out.
+func PurgeScheduledAccountDeletions() {
+	users, err := storage.UserManager.GetUsersDueForDeletion(time.Now())
+	if err != nil {
+		log.WithFields(logrus.Fields{"LoggerName": "SessionCleanup"}).Error(err.Error())
+		return
+	}
+	for _, user := range users {
+		if _, err := storage.UserManager.DeleteUser(user.Id); err != nil {
+			log.WithFields(logrus.Fields{"LoggerName": "SessionCleanup", "UserId": user.Id}).Error(err.Error())
+			continue
+		}
+		log.WithFields(logrus.Fields{"LoggerName": "SessionCleanup", "UserId": user.Id}).Info("scheduled account deletion carried out")
+	}
+}
@@ -0,0 +1,55 @@
+package authentication
+
+import (
+	"todo-web-api/models"
+	"todo-web-api/storage"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Audit event types.
+const (
+	AuditLogin        = "login"
+	AuditLogout       = "logout"
+	AuditTokenRefresh = "token_refresh"
+	AuditRegister     = "register"
+)
+
+// Audit outcomes. Pending is a correct password still waiting on the
+// second factor.
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+	AuditPending = "pending"
+)
+
+const MaxAuditEvents = 100
+
+// RecordAuditEvent appends event to the audit log. A failed write is
+// logged rather than returned, so it never decides the outcome of the
+// request it describes.
+func RecordAuditEvent(event *models.AuditEvent) {
+	// Both come straight from the client on failed logins.
+	if len(event.Username) > 100 {
+		event.Username = event.Username[:100]
+	}
+	if len(event.UserAgent) > 255 {
+		event.UserAgent = event.UserAgent[:255]
+	}
+	if _, err := storage.AuditEventManager.CreateAuditEvent(event); err != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "AuditLog",
+			"EventType":  event.EventType,
+			"Outcome":    event.Outcome,
+			"UserId":     event.UserId,
+		}).Error(err.Error())
+	}
+}
+
+// RecentAuditEvents returns up to limit of the user's newest events.
+func RecentAuditEvents(userId int, limit int) ([]models.AuditEvent, error) {
+	if limit <= 0 || limit > MaxAuditEvents {
+		limit = MaxAuditEvents
+	}
+	return storage.AuditEventManager.GetAuditEventsForUser(userId, limit)
+}
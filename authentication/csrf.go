@@ -0,0 +1,46 @@
+package authentication
+
+import (
+	"errors"
+	"time"
+	"todo-web-api/messages"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+const purposeCSRF = "csrf"
+
+// CSRFTokenExpiry is how long a CSRF token lasts; clients fetch a new one
+// from /CsrfToken when a request is refused.
+const CSRFTokenExpiry = 12 * time.Hour
+
+// GenerateCSRFToken issues the token cookie-authenticated requests must
+// echo in the X-CSRF-Token header. It is signed like access tokens and
+// bound to the session, so any instance can check it without storing it,
+// and it's useless once the session ends.
+func GenerateCSRFToken(sessionId string) (string, error) {
+	if sessionId == "" {
+		return "", errors.New(messages.InvalidCSRFToken)
+	}
+	claims := &Claims{
+		SessionID: sessionId,
+		Purpose:   purposeCSRF,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    "Todo-Service",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(CSRFTokenExpiry)),
+		},
+	}
+	return signToken(claims)
+}
+
+// VerifyCSRFToken reports whether token was issued for sessionId.
+func VerifyCSRFToken(token, sessionId string) bool {
+	if token == "" || sessionId == "" {
+		return false
+	}
+	claims := &Claims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, verificationKey, jwt.WithValidMethods(validMethods))
+	return err == nil && parsed.Valid && claims.Purpose == purposeCSRF && claims.SessionID == sessionId
+}
@@ -0,0 +1,148 @@
+package authentication
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"todo-web-api/mailer"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+)
+
+const PurposeEmailVerification = "email_verification"
+
+const (
+	DefaultEmailVerificationExpiry = 24 * time.Hour
+	DefaultVerificationResendDelay = time.Minute
+)
+
+var emailVerification = struct {
+	url         string
+	expiry      time.Duration
+	resendDelay time.Duration
+}{expiry: DefaultEmailVerificationExpiry, resendDelay: DefaultVerificationResendDelay}
+
+// ConfigureEmailVerification sets the page verification links point at,
+// which gets the token as ?token=, how long a link stays valid and how long
+// an account has to wait between verification mails. Zero keeps a default.
+func ConfigureEmailVerification(verifyURL string, expiry, resendDelay time.Duration) {
+	emailVerification.url = verifyURL
+	if expiry <= 0 {
+		expiry = DefaultEmailVerificationExpiry
+	}
+	if resendDelay <= 0 {
+		resendDelay = DefaultVerificationResendDelay
+	}
+	emailVerification.expiry = expiry
+	emailVerification.resendDelay = resendDelay
+}
+
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// IsEmailUnverified reports whether user gave an address that hasn't been
+// confirmed yet. Accounts without one aren't restricted.
+func IsEmailUnverified(user *models.User) bool {
+	return user.Email != "" && !user.EmailVerified
+}
+
+// SendEmailVerification mails a fresh verification link to user's address;
+// earlier links stop working.
+func SendEmailVerification(user *models.User) error {
+	if user.Email == "" {
+		return errors.New(messages.NoEmailAddress)
+	}
+	if user.EmailVerified {
+		return errors.New(messages.EmailAlreadyVerified)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	plain := base64.RawURLEncoding.EncodeToString(secret)
+
+	if _, err := storage.OneTimeTokenManager.DeleteOneTimeTokens(user.Id, PurposeEmailVerification); err != nil {
+		return err
+	}
+	token := &models.OneTimeToken{
+		UserId:    user.Id,
+		Purpose:   PurposeEmailVerification,
+		TokenHash: hashToken(plain),
+		ExpiresAt: time.Now().Add(emailVerification.expiry),
+		CreatedAt: time.Now(),
+	}
+	if _, err := storage.OneTimeTokenManager.SaveOneTimeToken(token); err != nil {
+		return err
+	}
+
+	return mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Confirm that this address belongs to %s by following this link within %d hours:\n\n%s\n\n"+
+			"If you didn't create an account, ignore this message.\n",
+			user.Username, int(emailVerification.expiry.Hours()), tokenLink(emailVerification.url, plain)),
+	})
+}
+
+// ResendEmailVerification is SendEmailVerification, refused with
+// TooManyVerificationEmails and the time left to wait when the previous
+// mail went out too recently.
+func ResendEmailVerification(user *models.User) (time.Duration, error) {
+	if user.Email != "" && !user.EmailVerified {
+		last, err := storage.OneTimeTokenManager.GetLatestOneTimeToken(user.Id, PurposeEmailVerification)
+		if err != nil && err.Error() != messages.OneTimeTokenNotFoundInDb {
+			return 0, err
+		}
+		if last != nil {
+			if wait := time.Until(last.CreatedAt.Add(emailVerification.resendDelay)); wait > 0 {
+				return wait, errors.New(messages.TooManyVerificationEmails)
+			}
+		}
+	}
+	return 0, SendEmailVerification(user)
+}
+
+// VerifyEmail redeems a verification token. The account's tokens still
+// carry the unverified role until they're refreshed.
+func VerifyEmail(plain string) error {
+	token, err := storage.OneTimeTokenManager.ConsumeOneTimeToken(PurposeEmailVerification, hashToken(plain), time.Now())
+	if err != nil && err.Error() == messages.OneTimeTokenNotFoundInDb {
+		return errors.New(messages.InvalidVerificationToken)
+	} else if err != nil {
+		return err
+	}
+
+	user, err := storage.UserManager.GetUser(token.UserId)
+	if err != nil && err.Error() == messages.UserNotFound {
+		return errors.New(messages.InvalidVerificationToken)
+	} else if err != nil {
+		return err
+	}
+
+	// Another account may have verified the address since this one asked.
+	holder, err := storage.UserManager.GetUserByVerifiedEmail(user.Email)
+	if err == nil && holder.Id != user.Id {
+		return errors.New(messages.EmailInUse)
+	} else if err != nil && err.Error() != messages.UserNotFound {
+		return err
+	}
+
+	user.EmailVerified = true
+	user.VerifiedEmail = &user.Email
+	_, err = storage.UserManager.UpdateUser(user)
+	return err
+}
+
+func tokenLink(base string, token string) string {
+	if base == "" {
+		return token
+	}
+	return base + "?token=" + url.QueryEscape(token)
+}
@@ -0,0 +1,180 @@
+package authentication
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	bcr "golang.org/x/crypto/bcrypt"
+)
+
+// PasswordHasher turns passwords into self-describing hash strings: the
+// algorithm and its parameters are encoded in the hash, so a hash can be
+// checked after the configured hasher has changed.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	// Verify checks password against encoded, a hash in this hasher's
+	// format, using the parameters stored in it.
+	Verify(encoded, password string) (bool, error)
+	// Owns reports whether encoded is in this hasher's format.
+	Owns(encoded string) bool
+	// Weaker reports whether encoded, one of this hasher's, was made with
+	// weaker parameters than the hasher now uses.
+	Weaker(encoded string) bool
+	// MaxPasswordBytes is the longest password the algorithm fully uses.
+	MaxPasswordBytes() int
+}
+
+// Argon2idHasher produces PHC-style strings:
+// $argon2id$v=19$m=<KiB>,t=<iterations>,p=<parallelism>$<salt>$<key>
+type Argon2idHasher struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2idHasher uses the parameters RFC 9106 recommends for
+// memory-constrained servers: 64 MiB, 3 passes.
+func DefaultArgon2idHasher() *Argon2idHasher {
+	return &Argon2idHasher{Memory: 64 * 1024, Iterations: 3, Parallelism: 2, SaltLength: 16, KeyLength: 32}
+}
+
+func (a *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, a.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, a.Iterations, a.Memory, a.Parallelism, a.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, a.Memory, a.Iterations, a.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (a *Argon2idHasher) Verify(encoded, password string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+	candidate := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(candidate, key) == 1, nil
+}
+
+func (a *Argon2idHasher) Owns(encoded string) bool {
+	return strings.HasPrefix(encoded, "$argon2id$")
+}
+
+func (a *Argon2idHasher) Weaker(encoded string) bool {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return params.Memory < a.Memory || params.Iterations < a.Iterations || params.Parallelism < a.Parallelism ||
+		uint32(len(salt)) < a.SaltLength || uint32(len(key)) < a.KeyLength
+}
+
+// argon2 takes any length; this only stops huge bodies from costing a
+// hash each.
+func (a *Argon2idHasher) MaxPasswordBytes() int {
+	return 1024
+}
+
+func decodeArgon2id(encoded string) (*Argon2idHasher, []byte, []byte, error) {
+	// "", "argon2id", "v=19", "m=..,t=..,p=..", salt, key
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, nil, nil, errors.New("not an argon2id hash")
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, nil, nil, errors.New("unsupported argon2id version")
+	}
+	params := &Argon2idHasher{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return nil, nil, nil, errors.New("malformed argon2id parameters")
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, nil, nil, errors.New("malformed argon2id salt")
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return nil, nil, nil, errors.New("malformed argon2id key")
+	}
+	return params, salt, key, nil
+}
+
+// BcryptHasher is what passwords were hashed with before argon2id; it is
+// kept so those hashes still verify, and can be configured again.
+type BcryptHasher struct {
+	Cost int
+}
+
+func (b *BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcr.GenerateFromPassword([]byte(password), b.Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func (b *BcryptHasher) Verify(encoded, password string) (bool, error) {
+	err := bcr.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcr.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (b *BcryptHasher) Owns(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+func (b *BcryptHasher) Weaker(encoded string) bool {
+	cost, err := bcr.Cost([]byte(encoded))
+	return err != nil || cost < b.Cost
+}
+
+func (b *BcryptHasher) MaxPasswordBytes() int {
+	return bcryptMaxBytes
+}
+
+var passwordHasher PasswordHasher = DefaultArgon2idHasher()
+
+// knownHashers verify stored hashes, whichever of them made the hash.
+var knownHashers = []PasswordHasher{&Argon2idHasher{}, &BcryptHasher{}}
+
+// ConfigurePasswordHasher sets the hasher new hashes are made with.
+func ConfigurePasswordHasher(hasher PasswordHasher) {
+	passwordHasher = hasher
+}
+
+func HashPassword(password string) (string, error) {
+	return passwordHasher.Hash(password)
+}
+
+// VerifyPassword checks password against a stored hash of any known
+// format. needsRehash is set on a match when the hash isn't what the
+// configured hasher would make now, so the caller can store a new one
+// while it has the plain password.
+func VerifyPassword(encoded, password string) (match bool, needsRehash bool) {
+	for _, hasher := range knownHashers {
+		if !hasher.Owns(encoded) {
+			continue
+		}
+		ok, err := hasher.Verify(encoded, password)
+		if err != nil {
+			log.Error(err.Error())
+			return false, false
+		}
+		if !ok {
+			return false, false
+		}
+		return true, !passwordHasher.Owns(encoded) || passwordHasher.Weaker(encoded)
+	}
+	return false, false
+}
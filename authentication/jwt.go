@@ -3,64 +3,47 @@ package authentication
 import (
 	"errors"
 	//"log"
-	"os"
-	"sync"
 	"time"
 	"todo-web-api/loggerutils"
+	"todo-web-api/messages"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
-var ActiveTokens = make(map[string]string)
-
-// jwtKey is read from the JWT_SECRET env var in production; the literal
-// fallback is for local dev only and must never be relied on in deployment.
-var jwtKey = loadJWTKey()
-
-func loadJWTKey() []byte {
-	if key := os.Getenv("JWT_SECRET"); key != "" {
-		return []byte(key)
-	}
-	return []byte("Secret_Key")
-}
-var refreshTokens = make(map[string]string)
-var mutex = &sync.Mutex{}
 var log = loggerutils.GetLogger()
 
+// refreshPurpose marks refresh tokens, so that no other token carrying a
+// session id can be mistaken for a rotated-away refresh token.
+const refreshPurpose = "refresh"
+
 type Claims struct {
-	Username string
-	UserID   int
+	Username  string
+	UserID    int
+	SessionID string   `json:"sid"`
+	Roles     []string `json:"roles,omitempty"`
+	// Purpose marks tokens that aren't access tokens, such as the mfa
+	// token handed out between the password and the second factor.
+	Purpose string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func IsTokenActive(username string) bool {
-	mutex.Lock()
-	defer mutex.Unlock()
-	_, exists := ActiveTokens[username]
-	return exists
-}
-
-func IsRefreshTokenActive(username string) bool {
-	mutex.Lock()
-	defer mutex.Unlock()
-	_, exists := refreshTokens[username]
-	return exists
-}
-
-func GenerateAccessToken(username string, userId int) (string, error) {
+func GenerateAccessToken(username string, userId int, sessionId string, roles []string) (string, error) {
 
 	claims := &Claims{
-		Username: username,
-		UserID:   userId,
+		Username:  username,
+		UserID:    userId,
+		SessionID: sessionId,
+		Roles:     roles,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			Issuer:    "Todo-Service",
 			ExpiresAt: &jwt.NumericDate{Time: time.Now().Add(30 * time.Minute)},
 		},
 	}
 
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := accessToken.SignedString(jwtKey)
+	tokenString, err := signToken(claims)
 	if err != nil {
 		log.Error(err.Error())
 		return "", errors.New("error while creating access token")
@@ -68,19 +51,21 @@ func GenerateAccessToken(username string, userId int) (string, error) {
 	return tokenString, nil
 }
 
-func GenerateRefreshToken(userID int, userName string) (string, error) {
+func GenerateRefreshToken(userID int, userName string, sessionId string) (string, error) {
 
 	claims := &Claims{
-		UserID:   userID,
-		Username: userName,
+		UserID:    userID,
+		Username:  userName,
+		SessionID: sessionId,
+		Purpose:   refreshPurpose,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			Issuer:    "Todo-Service",
 			ExpiresAt: &jwt.NumericDate{Time: time.Now().Add(1 * time.Hour)},
 		},
 	}
 
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := refreshToken.SignedString(jwtKey)
+	tokenString, err := signToken(claims)
 	if err != nil {
 		log.Error(err.Error())
 
@@ -91,9 +76,7 @@ func GenerateRefreshToken(userID int, userName string) (string, error) {
 
 func ParseToken(tokenStr string) (*Claims, error) {
 	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
-		return jwtKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenStr, claims, verificationKey, jwt.WithValidMethods(validMethods))
 
 	if err != nil && errors.Is(err, jwt.ErrSignatureInvalid) {
 		log.Error(err.Error())
@@ -105,7 +88,7 @@ func ParseToken(tokenStr string) (*Claims, error) {
 		return nil, err
 	}
 
-	if !token.Valid {
+	if !token.Valid || claims.Purpose != "" {
 
 		err := errors.New("invalid access token")
 		log.Error(err.Error())
@@ -118,9 +101,7 @@ func ParseToken(tokenStr string) (*Claims, error) {
 
 func ParseRefreshToken(tokenStr string) (*Claims, error) {
 	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
-		return jwtKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenStr, claims, verificationKey, jwt.WithValidMethods(validMethods))
 
 	if err != nil && errors.Is(err, jwt.ErrSignatureInvalid) {
 
@@ -132,45 +113,47 @@ func ParseRefreshToken(tokenStr string) (*Claims, error) {
 		return nil, err
 	}
 
-	if !token.Valid {
+	// Access and csrf tokens carry the session id too, but only a refresh
+	// token can have been rotated away.
+	if !token.Valid || claims.Purpose != refreshPurpose {
 		err := errors.New("invalid refresh token")
 		log.Error(err.Error())
 
 		return nil, err
 	}
 
-	if storedToken, ok := refreshTokens[claims.Username]; !ok || storedToken != tokenStr {
+	session, err := findSession(claims.SessionID)
+	if err != nil || session == nil {
 		return nil, errors.New("refresh token not found or mismatched")
 	}
 
+	// A correctly signed token for a live session that isn't its current
+	// refresh token has already been rotated away: someone kept a copy.
+	if session.RefreshToken != hashToken(tokenStr) {
+		revokeReusedSession(claims)
+		// The claims come back so the caller can say whose token it was.
+		return claims, errors.New(messages.RefreshTokenReused)
+	}
+
+	return claims, nil
+}
+
+// parseSessionToken parses an access or refresh token, whichever it is.
+func parseSessionToken(tokenStr string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, verificationKey, jwt.WithValidMethods(validMethods))
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid || (claims.Purpose != "" && claims.Purpose != refreshPurpose) {
+		return nil, errors.New("invalid token")
+	}
 	return claims, nil
 }
 
 func Payload(claims *Claims, c *gin.Context) {
 	c.Set("user_id", claims.UserID)
 	c.Set("username", claims.Username)
-}
-
-func SaveToken(username, token string) {
-	mutex.Lock()
-	defer mutex.Unlock()
-	ActiveTokens[username] = token
-}
-
-func RemoveToken(username string) {
-	mutex.Lock()
-	defer mutex.Unlock()
-	delete(ActiveTokens, username)
-}
-
-func SaveRefreshToken(username, token string) {
-	mutex.Lock()
-	defer mutex.Unlock()
-	refreshTokens[username] = token
-}
-
-func RemoveRefreshToken(username string) {
-	mutex.Lock()
-	defer mutex.Unlock()
-	delete(refreshTokens, username)
+	c.Set("session_id", claims.SessionID)
+	c.Set("roles", claims.Roles)
 }
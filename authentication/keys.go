@@ -0,0 +1,257 @@
+package authentication
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// SigningKey is one entry of the key ring. Only the active key signs; every
+// key that hasn't passed VerifyUntil still verifies, so tokens signed before
+// a rotation stay valid until they expire.
+type SigningKey struct {
+	Kid         string
+	Method      jwt.SigningMethod
+	PrivateKey  crypto.PrivateKey
+	PublicKey   crypto.PublicKey
+	VerifyUntil time.Time
+}
+
+// KeyConfig describes a key loaded from PEM files. A retired key only needs
+// its public key file. PrivateKeyEnv names an environment variable holding
+// the private key PEM instead of a file, for keys kept as secrets.
+type KeyConfig struct {
+	Kid            string
+	Algorithm      string
+	PrivateKeyFile string
+	PrivateKeyEnv  string
+	PublicKeyFile  string
+	VerifyUntil    time.Time
+}
+
+// JWK is the public half of a signing key as published in the JWKS.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+var keyRing = struct {
+	sync.RWMutex
+	active *SigningKey
+	keys   map[string]*SigningKey
+}{keys: map[string]*SigningKey{}}
+
+var validMethods = []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+
+// ConfigureKeys replaces the key ring with the configured keys and makes
+// activeKid the signing key.
+func ConfigureKeys(configs []KeyConfig, activeKid string) error {
+	keys := map[string]*SigningKey{}
+	for _, config := range configs {
+		key, err := loadKey(config)
+		if err != nil {
+			return fmt.Errorf("signing key %q: %w", config.Kid, err)
+		}
+		keys[key.Kid] = key
+	}
+
+	active, ok := keys[activeKid]
+	if !ok {
+		return fmt.Errorf("active signing key %q is not configured", activeKid)
+	}
+	if active.PrivateKey == nil {
+		return fmt.Errorf("active signing key %q has no private key", activeKid)
+	}
+
+	keyRing.Lock()
+	defer keyRing.Unlock()
+	keyRing.keys = keys
+	keyRing.active = active
+	return nil
+}
+
+// PublicJWKS returns the keys other services can verify our tokens with.
+func PublicJWKS() JWKS {
+	keyRing.RLock()
+	defer keyRing.RUnlock()
+
+	jwks := JWKS{Keys: []JWK{}}
+	now := time.Now()
+	for _, key := range keyRing.keys {
+		if !key.VerifyUntil.IsZero() && now.After(key.VerifyUntil) {
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, toJWK(key))
+	}
+	return jwks
+}
+
+func signToken(claims jwt.Claims) (string, error) {
+	key := activeKey()
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.Kid
+	return token.SignedString(key.PrivateKey)
+}
+
+// verificationKey is the jwt.Keyfunc for tokens signed by this service.
+func verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	keyRing.RLock()
+	key, ok := keyRing.keys[kid]
+	keyRing.RUnlock()
+
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+	if !key.VerifyUntil.IsZero() && time.Now().After(key.VerifyUntil) {
+		return nil, errors.New("signing key retired")
+	}
+	return key.PublicKey, nil
+}
+
+// activeKey falls back to a throwaway Ed25519 key when none is configured,
+// which is only good enough for tests and local development: tokens don't
+// survive a restart and can't be verified by other instances.
+func activeKey() *SigningKey {
+	keyRing.RLock()
+	active := keyRing.active
+	keyRing.RUnlock()
+	if active != nil {
+		return active
+	}
+
+	keyRing.Lock()
+	defer keyRing.Unlock()
+	if keyRing.active == nil {
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			log.WithFields(logrus.Fields{"LoggerName": "SigningKeys"}).Fatal(err.Error())
+		}
+		key := &SigningKey{Kid: "ephemeral-" + uuid.NewString(), Method: jwt.SigningMethodEdDSA,
+			PrivateKey: private, PublicKey: public}
+		keyRing.keys[key.Kid] = key
+		keyRing.active = key
+		log.WithFields(logrus.Fields{"LoggerName": "SigningKeys", "Kid": key.Kid}).
+			Warn("no signing keys configured, using an ephemeral key")
+	}
+	return keyRing.active
+}
+
+// HasConfiguredKeys reports whether ConfigureKeys has loaded a key ring.
+func HasConfiguredKeys() bool {
+	keyRing.RLock()
+	defer keyRing.RUnlock()
+	return keyRing.active != nil
+}
+
+func readPrivateKeyPEM(config KeyConfig) ([]byte, error) {
+	if config.PrivateKeyEnv == "" {
+		return os.ReadFile(config.PrivateKeyFile)
+	}
+	pem := os.Getenv(config.PrivateKeyEnv)
+	if pem == "" {
+		return nil, fmt.Errorf("environment variable %s is not set", config.PrivateKeyEnv)
+	}
+	return []byte(pem), nil
+}
+
+func loadKey(config KeyConfig) (*SigningKey, error) {
+	if config.Kid == "" {
+		return nil, errors.New("kid is required")
+	}
+
+	key := &SigningKey{Kid: config.Kid, VerifyUntil: config.VerifyUntil}
+	switch config.Algorithm {
+	case "RS256", "":
+		key.Method = jwt.SigningMethodRS256
+	case "EdDSA":
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", config.Algorithm)
+	}
+
+	if config.PrivateKeyFile != "" && config.PrivateKeyEnv != "" {
+		return nil, errors.New("set only one of the private key file and environment variable")
+	}
+	if config.PrivateKeyFile != "" || config.PrivateKeyEnv != "" {
+		pem, err := readPrivateKeyPEM(config)
+		if err != nil {
+			return nil, err
+		}
+		if key.Method == jwt.SigningMethodRS256 {
+			private, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+			if err != nil {
+				return nil, err
+			}
+			key.PrivateKey, key.PublicKey = private, &private.PublicKey
+		} else {
+			private, err := jwt.ParseEdPrivateKeyFromPEM(pem)
+			if err != nil {
+				return nil, err
+			}
+			key.PrivateKey, key.PublicKey = private, private.(ed25519.PrivateKey).Public()
+		}
+	}
+
+	if config.PublicKeyFile != "" {
+		pem, err := os.ReadFile(config.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if key.Method == jwt.SigningMethodRS256 {
+			key.PublicKey, err = jwt.ParseRSAPublicKeyFromPEM(pem)
+		} else {
+			key.PublicKey, err = jwt.ParseEdPublicKeyFromPEM(pem)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if key.PublicKey == nil {
+		return nil, errors.New("private_key_file or public_key_file is required")
+	}
+	return key, nil
+}
+
+func toJWK(key *SigningKey) JWK {
+	jwk := JWK{Kid: key.Kid, Use: "sig", Alg: key.Method.Alg()}
+	switch public := key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+	return jwk
+}
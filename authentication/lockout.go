@@ -0,0 +1,177 @@
+package authentication
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LockoutPolicy controls how failed logins are throttled. Each failure for
+// a username delays the next attempt exponentially (BaseDelay, doubling, up
+// to MaxDelay) and MaxFailures of them lock the username for
+// LockoutDuration. A client address is only throttled once it passes
+// IPMaxFailures, since many users can share one address. Failures older
+// than FailureWindow are forgotten.
+type LockoutPolicy struct {
+	MaxFailures     int
+	LockoutDuration time.Duration
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+	IPMaxFailures   int
+	FailureWindow   time.Duration
+}
+
+var lockoutPolicy = DefaultLockoutPolicy()
+
+func DefaultLockoutPolicy() LockoutPolicy {
+	return LockoutPolicy{
+		MaxFailures:     5,
+		LockoutDuration: 15 * time.Minute,
+		BaseDelay:       time.Second,
+		MaxDelay:        15 * time.Minute,
+		IPMaxFailures:   50,
+		FailureWindow:   time.Hour,
+	}
+}
+
+// ConfigureLockout replaces the lockout policy; zero fields keep their
+// defaults.
+func ConfigureLockout(policy LockoutPolicy) {
+	defaults := DefaultLockoutPolicy()
+	if policy.MaxFailures <= 0 {
+		policy.MaxFailures = defaults.MaxFailures
+	}
+	if policy.LockoutDuration <= 0 {
+		policy.LockoutDuration = defaults.LockoutDuration
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = defaults.BaseDelay
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = defaults.MaxDelay
+	}
+	if policy.IPMaxFailures <= 0 {
+		policy.IPMaxFailures = defaults.IPMaxFailures
+	}
+	if policy.FailureWindow <= 0 {
+		policy.FailureWindow = defaults.FailureWindow
+	}
+	lockoutPolicy = policy
+}
+
+// CheckLoginAllowed returns messages.AccountLocked or
+// messages.TooManyLoginAttempts, along with how long the caller has to
+// wait, when a login for username from ip must be refused without looking
+// at the password.
+func CheckLoginAllowed(username, ip string) (retryAfter time.Duration, err error) {
+	now := time.Now()
+
+	userAttempt, err := findLoginAttempt(userSubject(username))
+	if err != nil {
+		return 0, err
+	}
+	ipAttempt, err := findLoginAttempt(ipSubject(ip))
+	if err != nil {
+		return 0, err
+	}
+
+	if userAttempt != nil && now.Before(userAttempt.LockedUntil) && userAttempt.Failures >= lockoutPolicy.MaxFailures {
+		return userAttempt.LockedUntil.Sub(now), errors.New(messages.AccountLocked)
+	}
+	for _, attempt := range []*models.LoginAttempt{userAttempt, ipAttempt} {
+		if attempt != nil && now.Before(attempt.LockedUntil) {
+			retryAfter = max(retryAfter, attempt.LockedUntil.Sub(now))
+		}
+	}
+	if retryAfter > 0 {
+		return retryAfter, errors.New(messages.TooManyLoginAttempts)
+	}
+	return 0, nil
+}
+
+// RecordLoginFailure counts a failed login against both the username and
+// the client address and pushes their next allowed attempt back.
+func RecordLoginFailure(username, ip string) {
+	now := time.Now()
+	windowStart := now.Add(-lockoutPolicy.FailureWindow)
+
+	if attempt, err := storage.LoginAttemptManager.RecordFailedAttempt(userSubject(username), now, windowStart); err == nil {
+		until := now.Add(backoff(attempt.Failures))
+		if attempt.Failures >= lockoutPolicy.MaxFailures {
+			until = now.Add(lockoutPolicy.LockoutDuration)
+			log.WithFields(logrus.Fields{"LoggerName": "LoginLockout", "Username": username}).
+				Warn(messages.AccountLocked)
+		}
+		lockSubject(attempt.Subject, until)
+	}
+
+	if attempt, err := storage.LoginAttemptManager.RecordFailedAttempt(ipSubject(ip), now, windowStart); err == nil &&
+		attempt.Failures >= lockoutPolicy.IPMaxFailures {
+		lockSubject(attempt.Subject, now.Add(backoff(attempt.Failures-lockoutPolicy.IPMaxFailures+1)))
+	}
+}
+
+// ResetLoginFailures clears the username's counter after a successful
+// login. The address counter is left alone so an attacker can't reset it
+// by signing in to an account of their own.
+func ResetLoginFailures(username string) {
+	if _, err := storage.LoginAttemptManager.DeleteLoginAttempt(userSubject(username)); err != nil {
+		log.WithFields(logrus.Fields{"LoggerName": "LoginLockout"}).Error(err.Error())
+	}
+}
+
+func PurgeStaleLoginAttempts() {
+	count, err := storage.LoginAttemptManager.DeleteStaleLoginAttempts(time.Now().Add(-lockoutPolicy.FailureWindow))
+	if err != nil {
+		log.WithFields(logrus.Fields{"LoggerName": "LoginLockout"}).Error(err.Error())
+		return
+	}
+	if count > 0 {
+		log.WithFields(logrus.Fields{"LoggerName": "LoginLockout", "Count": count}).Info("stale login attempts removed")
+	}
+}
+
+// backoff is BaseDelay doubled for every failure after the first, capped
+// at MaxDelay.
+func backoff(failures int) time.Duration {
+	delay := lockoutPolicy.BaseDelay
+	for i := 1; i < failures && delay < lockoutPolicy.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, lockoutPolicy.MaxDelay)
+}
+
+func lockSubject(subject string, until time.Time) {
+	if err := storage.LoginAttemptManager.LockLoginAttempt(subject, until); err != nil {
+		log.WithFields(logrus.Fields{"LoggerName": "LoginLockout"}).Error(err.Error())
+	}
+}
+
+// findLoginAttempt returns nil, nil when the subject has no recent
+// failures.
+func findLoginAttempt(subject string) (*models.LoginAttempt, error) {
+	attempt, err := storage.LoginAttemptManager.GetLoginAttempt(subject)
+	if err != nil && err.Error() == messages.LoginAttemptNotFoundInDb {
+		return nil, nil
+	}
+	return attempt, err
+}
+
+// Subjects must fit the 191 character column; usernames longer than that
+// can't exist anyway.
+func userSubject(username string) string {
+	username = strings.ToLower(username)
+	if len(username) > 150 {
+		username = username[:150]
+	}
+	return "user:" + username
+}
+
+func ipSubject(ip string) string {
+	return "ip:" + ip
+}
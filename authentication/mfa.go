@@ -0,0 +1,186 @@
+package authentication
+
+import (
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+const (
+	mfaPurpose        = "mfa"
+	mfaTokenLifetime  = 5 * time.Minute
+	recoveryCodeCount = 10
+)
+
+var mfaIssuer = "Todo Manager"
+
+// ConfigureMfa sets the issuer name shown in authenticator apps.
+func ConfigureMfa(issuer string) {
+	if issuer != "" {
+		mfaIssuer = issuer
+	}
+}
+
+// EnrollTOTP gives the user a new, not yet enforced, TOTP secret. It only
+// takes effect once ConfirmTOTP sees a code generated from it.
+func EnrollTOTP(user *models.User) (secret, uri string, err error) {
+	if user.TOTPEnabled {
+		return "", "", errors.New(messages.MfaAlreadyEnabled)
+	}
+
+	secret, err = GenerateTOTPSecret()
+	if err != nil {
+		return "", "", err
+	}
+	user.TOTPSecret = secret
+	user.TOTPLastCounter = 0
+	if _, err := storage.UserManager.UpdateUser(user); err != nil {
+		return "", "", err
+	}
+	return secret, TOTPURI(mfaIssuer, user.Username, secret), nil
+}
+
+// ConfirmTOTP turns two-factor on once the user proves their authenticator
+// works, and returns their recovery codes. This is the only time the codes
+// are available in plain text.
+func ConfirmTOTP(user *models.User, code string) ([]string, error) {
+	if user.TOTPEnabled {
+		return nil, errors.New(messages.MfaAlreadyEnabled)
+	}
+	if user.TOTPSecret == "" {
+		return nil, errors.New(messages.MfaNotEnrolled)
+	}
+	if err := verifyTOTP(user, code); err != nil {
+		return nil, err
+	}
+
+	user.TOTPEnabled = true
+	if _, err := storage.UserManager.UpdateUser(user); err != nil {
+		return nil, err
+	}
+	return newRecoveryCodes(user.Id)
+}
+
+// DisableTOTP turns two-factor off; it needs a current code or a recovery
+// code, not just a session.
+func DisableTOTP(user *models.User, code, recoveryCode string) error {
+	if !user.TOTPEnabled {
+		return errors.New(messages.MfaNotEnrolled)
+	}
+	if err := VerifySecondFactor(user, code, recoveryCode); err != nil {
+		return err
+	}
+
+	user.TOTPEnabled = false
+	user.TOTPSecret = ""
+	user.TOTPLastCounter = 0
+	if _, err := storage.UserManager.UpdateUser(user); err != nil {
+		return err
+	}
+	_, err := storage.RecoveryCodeManager.DeleteRecoveryCodes(user.Id)
+	return err
+}
+
+// VerifySecondFactor checks a TOTP code, or failing that a recovery code,
+// which is used up by a successful check.
+func VerifySecondFactor(user *models.User, code, recoveryCode string) error {
+	if code != "" {
+		return verifyTOTP(user, code)
+	}
+	if recoveryCode == "" {
+		return errors.New(messages.MfaCodeRequired)
+	}
+
+	used, err := storage.RecoveryCodeManager.UseRecoveryCode(user.Id, hashToken(normalizeRecoveryCode(recoveryCode)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return errors.New(messages.InvalidMfaCode)
+	}
+	return nil
+}
+
+// GenerateMfaToken is what a correct password gets an account with
+// two-factor enabled: it carries no session and is only accepted by
+// ParseMfaToken.
+func GenerateMfaToken(user *models.User) (string, error) {
+	claims := &Claims{
+		Username: user.Username,
+		UserID:   user.Id,
+		Purpose:  mfaPurpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    "Todo-Service",
+			ExpiresAt: &jwt.NumericDate{Time: time.Now().Add(mfaTokenLifetime)},
+		},
+	}
+
+	tokenString, err := signToken(claims)
+	if err != nil {
+		log.Error(err.Error())
+		return "", errors.New("error while creating mfa token")
+	}
+	return tokenString, nil
+}
+
+func ParseMfaToken(tokenStr string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, verificationKey, jwt.WithValidMethods(validMethods))
+	if err != nil || !token.Valid || claims.Purpose != mfaPurpose {
+		return nil, errors.New(messages.InvalidMfaToken)
+	}
+	return claims, nil
+}
+
+// verifyTOTP accepts each time step at most once, so a code that has been
+// seen (or intercepted) can't be used again.
+func verifyTOTP(user *models.User, code string) error {
+	counter, ok := matchTOTP(user.TOTPSecret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return errors.New(messages.InvalidMfaCode)
+	}
+
+	advanced, err := storage.UserManager.AdvanceTOTPCounter(user.Id, counter)
+	if err != nil {
+		return err
+	}
+	if !advanced {
+		return errors.New(messages.InvalidMfaCode)
+	}
+	user.TOTPLastCounter = counter
+	return nil
+}
+
+func newRecoveryCodes(userId int) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw := make([]byte, 7)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(totpEncoding.EncodeToString(raw))[:10]
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, hashToken(code))
+	}
+
+	if err := storage.RecoveryCodeManager.ReplaceRecoveryCodes(userId, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// normalizeRecoveryCode accepts codes typed with or without the dash and in
+// any case.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
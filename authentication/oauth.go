@@ -0,0 +1,173 @@
+package authentication
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	authz "todo-web-api/authorization"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+)
+
+// Token types reported by introspection. Access tokens and personal access
+// tokens are both sent as bearer tokens.
+const (
+	TokenTypeBearer  = "Bearer"
+	TokenTypeRefresh = "refresh_token"
+)
+
+// Client secrets shorter than this are refused at startup; they are the
+// only thing between the internet and token introspection.
+const minOAuthClientSecretLength = 16
+
+// OAuthClient is a backend service allowed to introspect and revoke tokens.
+type OAuthClient struct {
+	ClientID     string
+	ClientSecret string
+}
+
+// oauthClients maps client ids to the SHA-256 of their secret, so secrets
+// are compared in constant time whatever their length.
+var oauthClients = map[string][]byte{}
+
+// ConfigureOAuthClients replaces the clients allowed to call /oauth/*.
+// With none configured every call is refused.
+func ConfigureOAuthClients(clients []OAuthClient) error {
+	configured := make(map[string][]byte, len(clients))
+	for _, client := range clients {
+		if client.ClientID == "" {
+			return errors.New("oauth client needs a client_id")
+		}
+		if len(client.ClientSecret) < minOAuthClientSecretLength {
+			return fmt.Errorf("oauth client %q needs a client_secret of at least %d characters", client.ClientID, minOAuthClientSecretLength)
+		}
+		if _, ok := configured[client.ClientID]; ok {
+			return fmt.Errorf("oauth client %q is listed twice", client.ClientID)
+		}
+		sum := sha256.Sum256([]byte(client.ClientSecret))
+		configured[client.ClientID] = sum[:]
+	}
+	oauthClients = configured
+	return nil
+}
+
+func AuthenticateOAuthClient(clientId, clientSecret string) bool {
+	expected, ok := oauthClients[clientId]
+	sum := sha256.Sum256([]byte(clientSecret))
+	return ok && subtle.ConstantTimeCompare(sum[:], expected) == 1
+}
+
+// TokenIntrospection describes an active token, in the terms of RFC 7662
+// section 2.2.
+type TokenIntrospection struct {
+	TokenType string
+	// Permissions the token grants; none for refresh tokens.
+	Scopes    []string
+	UserId    int
+	Username  string
+	SessionId string
+	Issuer    string
+	TokenId   string
+	IssuedAt  *time.Time
+	ExpiresAt time.Time
+}
+
+// Subject is the user id, as a string the way the sub claim carries it.
+func (t *TokenIntrospection) Subject() string {
+	return strconv.Itoa(t.UserId)
+}
+
+// IntrospectToken returns nil, without an error, for any token a resource
+// server should not accept: unknown, expired, revoked, rotated away or
+// belonging to a disabled account. It applies the same checks as
+// AuthMiddleware, plus the current refresh token of a session.
+func IntrospectToken(token string) (*TokenIntrospection, error) {
+	if IsAccessToken(token) {
+		record, user, permissions, err := lookupAccessToken(token)
+		if err != nil && (err.Error() == messages.InvalidAccessToken || err.Error() == messages.AccountDisabled) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		issuedAt := record.CreatedAt
+		return &TokenIntrospection{
+			TokenType: TokenTypeBearer,
+			Scopes:    permissions,
+			UserId:    user.Id,
+			Username:  user.Username,
+			IssuedAt:  &issuedAt,
+			ExpiresAt: record.ExpiresAt,
+		}, nil
+	}
+
+	claims, session, err := tokenSession(token)
+	if err != nil || session == nil {
+		return nil, err
+	}
+
+	introspection := &TokenIntrospection{
+		UserId:    claims.UserID,
+		Username:  claims.Username,
+		SessionId: claims.SessionID,
+		Issuer:    claims.Issuer,
+		TokenId:   claims.ID,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}
+	if hashToken(token) == session.AccessToken {
+		introspection.TokenType = TokenTypeBearer
+		introspection.Scopes = authz.PermissionsFor(claims.Roles...)
+	} else {
+		introspection.TokenType = TokenTypeRefresh
+	}
+	return introspection, nil
+}
+
+// RevokeToken invalidates token. A JWT ends the whole session it belongs
+// to, since its access and refresh token are only good together. Tokens
+// that are unknown, expired or already revoked are not an error (RFC 7009
+// section 2.2), so callers can't probe for valid ones.
+func RevokeToken(token string) error {
+	if IsAccessToken(token) {
+		record, err := storage.AccessTokenManager.GetAccessTokenByHash(hashToken(token))
+		if err != nil && err.Error() == messages.AccessTokenNotFoundInDb {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		_, err = storage.AccessTokenManager.DeleteAccessToken(record.UserId, record.Id)
+		return err
+	}
+
+	claims, session, err := tokenSession(token)
+	if err != nil || session == nil {
+		return err
+	}
+	return RevokeSession(claims.SessionID)
+}
+
+// tokenSession returns the live session whose current access or refresh
+// token is token, or nil when there is none. Unlike ParseRefreshToken it
+// doesn't treat a rotated-away refresh token as reuse: looking at a token
+// isn't using it.
+func tokenSession(token string) (*Claims, *models.Session, error) {
+	claims, err := parseSessionToken(token)
+	if err != nil || claims.ExpiresAt == nil {
+		return nil, nil, nil
+	}
+	session, err := findSession(claims.SessionID)
+	if err != nil || session == nil {
+		return nil, nil, err
+	}
+	if !time.Now().Before(session.ExpiresAt) {
+		return nil, nil, nil
+	}
+	digest := hashToken(token)
+	if digest != session.AccessToken && digest != session.RefreshToken {
+		return nil, nil, nil
+	}
+	return claims, session, nil
+}
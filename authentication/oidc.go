@@ -0,0 +1,507 @@
+package authentication
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	authz "todo-web-api/authorization"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	purposeOIDCState = "oidc_state"
+	// How long a user has to finish signing in at the provider.
+	OIDCStateExpiry = 10 * time.Minute
+	// The provider's keys are fetched again at most this often when an ID
+	// token names a key we don't have.
+	oidcKeyRefreshInterval = time.Minute
+)
+
+// OIDCProviderConfig is one OpenID Connect provider users can sign in with.
+type OIDCProviderConfig struct {
+	// Name identifies the provider in routes and in linked identities.
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is this service's callback, registered with the provider.
+	RedirectURL string
+	Scopes      []string
+	// AllowSignup creates an account for a provider user who doesn't match
+	// an existing one; otherwise only existing accounts can sign in.
+	AllowSignup bool
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oidcProvider struct {
+	config OIDCProviderConfig
+
+	mu            sync.Mutex
+	discovery     *oidcDiscovery
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+type oidcStateClaims struct {
+	Provider string `json:"provider"`
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	Purpose  string `json:"purpose"`
+	jwt.RegisteredClaims
+}
+
+type oidcIDTokenClaims struct {
+	Nonce             string `json:"nonce"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	PreferredUsername string `json:"preferred_username"`
+	jwt.RegisteredClaims
+}
+
+var oidc = struct {
+	sync.RWMutex
+	providers    map[string]*oidcProvider
+	postLoginURL string
+}{providers: map[string]*oidcProvider{}}
+
+var oidcHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+var oidcMethods = []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+
+// ConfigureOIDC replaces the configured providers. postLoginURL is where
+// the browser is sent once a provider login has opened a session.
+func ConfigureOIDC(configs []OIDCProviderConfig, postLoginURL string) error {
+	providers := map[string]*oidcProvider{}
+	for _, config := range configs {
+		if config.Name == "" || config.Issuer == "" || config.ClientID == "" || config.RedirectURL == "" {
+			return errors.New("oidc provider needs a name, issuer, client_id and redirect_url")
+		}
+		if _, ok := providers[config.Name]; ok {
+			return fmt.Errorf("oidc provider %q configured twice", config.Name)
+		}
+		if len(config.Scopes) == 0 {
+			config.Scopes = []string{"openid", "email", "profile"}
+		}
+		config.Issuer = strings.TrimSuffix(config.Issuer, "/")
+		providers[config.Name] = &oidcProvider{config: config}
+	}
+
+	oidc.Lock()
+	oidc.providers = providers
+	oidc.postLoginURL = postLoginURL
+	oidc.Unlock()
+	return nil
+}
+
+func OIDCPostLoginURL() string {
+	oidc.RLock()
+	defer oidc.RUnlock()
+	return oidc.postLoginURL
+}
+
+// BeginOIDCLogin returns the provider URL to send the browser to, and a
+// signed state token binding the state, nonce and PKCE verifier to this
+// browser; it goes in a cookie for CompleteOIDCLogin to check.
+func BeginOIDCLogin(ctx context.Context, providerName string) (authURL string, stateToken string, err error) {
+	provider, err := findOIDCProvider(providerName)
+	if err != nil {
+		return "", "", err
+	}
+	discovery, err := provider.discover(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	claims := &oidcStateClaims{
+		Provider: providerName,
+		State:    randomToken(),
+		Nonce:    randomToken(),
+		Verifier: randomToken(),
+		Purpose:  purposeOIDCState,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "Todo-Service",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(OIDCStateExpiry)),
+		},
+	}
+	stateToken, err = signToken(claims)
+	if err != nil {
+		return "", "", err
+	}
+
+	challenge := sha256.Sum256([]byte(claims.Verifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {provider.config.ClientID},
+		"redirect_uri":          {provider.config.RedirectURL},
+		"scope":                 {strings.Join(provider.config.Scopes, " ")},
+		"state":                 {claims.State},
+		"nonce":                 {claims.Nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.AuthorizationEndpoint + separator + query.Encode(), stateToken, nil
+}
+
+// CompleteOIDCLogin handles the provider's redirect back: it checks state
+// against the state token, redeems code, verifies the ID token and returns
+// the local user the identity belongs to, linking or creating one if needed.
+func CompleteOIDCLogin(ctx context.Context, providerName, stateToken, state, code string) (*models.User, error) {
+	provider, err := findOIDCProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &oidcStateClaims{}
+	_, err = jwt.ParseWithClaims(stateToken, claims, verificationKey, jwt.WithValidMethods(validMethods))
+	if err != nil || claims.Purpose != purposeOIDCState || claims.Provider != providerName ||
+		subtle.ConstantTimeCompare([]byte(claims.State), []byte(state)) != 1 {
+		return nil, errors.New(messages.InvalidOIDCState)
+	}
+
+	idToken, err := provider.exchangeCode(ctx, code, claims.Verifier)
+	if err != nil {
+		oidcLog(providerName).Error(err.Error())
+		return nil, errors.New(messages.OIDCLoginFailed)
+	}
+	identity, err := provider.verifyIDToken(ctx, idToken, claims.Nonce)
+	if err != nil {
+		oidcLog(providerName).Error(err.Error())
+		return nil, errors.New(messages.OIDCLoginFailed)
+	}
+
+	return linkOIDCIdentity(provider.config, identity)
+}
+
+// linkOIDCIdentity finds the user for a verified provider identity: the one
+// it was linked to before, else the account with the same verified email
+// address, else a new account when the provider allows signup.
+func linkOIDCIdentity(config OIDCProviderConfig, claims *oidcIDTokenClaims) (*models.User, error) {
+	identity, err := storage.ExternalIdentityManager.GetExternalIdentity(config.Name, claims.Subject)
+	if err == nil {
+		if err := storage.ExternalIdentityManager.TouchExternalIdentity(identity.Id, time.Now()); err != nil {
+			return nil, err
+		}
+		return storage.UserManager.GetUser(identity.UserId)
+	} else if err.Error() != messages.ExternalIdentityNotFoundInDb {
+		return nil, err
+	}
+
+	email := ""
+	if claims.EmailVerified {
+		email = NormalizeEmail(claims.Email)
+	}
+
+	var user *models.User
+	if email != "" {
+		// Only an address both sides have verified proves it's the same
+		// person; anyone can type an address into /Register.
+		existing, err := storage.UserManager.GetUserByVerifiedEmail(email)
+		if err != nil && err.Error() != messages.UserNotFound {
+			return nil, err
+		}
+		user = existing
+	}
+
+	if user == nil {
+		if !config.AllowSignup {
+			return nil, errors.New(messages.OIDCSignupDisabled)
+		}
+		user, err = createOIDCUser(config, claims, email)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = storage.ExternalIdentityManager.CreateExternalIdentity(&models.ExternalIdentity{
+		UserId:      user.Id,
+		Provider:    config.Name,
+		Subject:     claims.Subject,
+		Email:       email,
+		LastLoginAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// createOIDCUser makes an account for a provider user. It gets a random
+// password nobody knows; a verified email address can reset it.
+func createOIDCUser(config OIDCProviderConfig, claims *oidcIDTokenClaims, email string) (*models.User, error) {
+	username := email
+	if username == "" {
+		username = config.Name + ":" + claims.Subject
+	}
+	_, err := storage.UserManager.FindExistingAccount(username, "")
+	if err == nil {
+		username = config.Name + ":" + claims.Subject
+	} else if err.Error() != messages.AccountNotFound {
+		return nil, err
+	}
+
+	password, err := HashPassword(randomToken())
+	if err != nil {
+		return nil, err
+	}
+	user := &models.User{
+		Username:      username,
+		Password:      password,
+		Role:          authz.RoleUser,
+		Email:         email,
+		EmailVerified: email != "",
+		CreatedAt:     time.Now(),
+	}
+	if email != "" {
+		user.VerifiedEmail = &email
+	}
+	id, err := storage.UserManager.CreateUser(user)
+	if err != nil {
+		return nil, err
+	}
+	user.Id = id
+	return user, nil
+}
+
+func findOIDCProvider(name string) (*oidcProvider, error) {
+	oidc.RLock()
+	defer oidc.RUnlock()
+	provider, ok := oidc.providers[name]
+	if !ok {
+		return nil, errors.New(messages.UnknownOIDCProvider)
+	}
+	return provider, nil
+}
+
+// discover fetches the provider's metadata once and keeps it.
+func (p *oidcProvider) discover(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var discovery oidcDiscovery
+	if err := getJSON(ctx, p.config.Issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, err
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != p.config.Issuer {
+		return nil, fmt.Errorf("oidc discovery issuer %q doesn't match %q", discovery.Issuer, p.config.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, errors.New("oidc discovery document is missing endpoints")
+	}
+	p.discovery = &discovery
+	return p.discovery, nil
+}
+
+func (p *oidcProvider) exchangeCode(ctx context.Context, code, verifier string) (string, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"code_verifier": {verifier},
+		"client_id":     {p.config.ClientID},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	resp, err := oidcHTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint returned %d: %s %s", resp.StatusCode, token.Error, token.ErrorDescription)
+	}
+	if token.IDToken == "" {
+		return "", errors.New("token response has no id_token")
+	}
+	return token.IDToken, nil
+}
+
+func (p *oidcProvider) verifyIDToken(ctx context.Context, idToken, nonce string) (*oidcIDTokenClaims, error) {
+	claims := &oidcIDTokenClaims{}
+	_, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.publicKey(ctx, kid)
+	}, jwt.WithValidMethods(oidcMethods))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	switch {
+	case !claims.VerifyIssuer(p.config.Issuer, true):
+		return nil, errors.New("id token issuer mismatch")
+	case !claims.VerifyAudience(p.config.ClientID, true):
+		return nil, errors.New("id token audience mismatch")
+	case !claims.VerifyExpiresAt(now, true):
+		return nil, errors.New("id token expired")
+	case claims.Subject == "":
+		return nil, errors.New("id token has no subject")
+	case subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1:
+		return nil, errors.New("id token nonce mismatch")
+	}
+	return claims, nil
+}
+
+// publicKey finds the provider key kid, fetching the provider's JWKS again
+// when it's unknown, so the provider can rotate keys.
+func (p *oidcProvider) publicKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	if time.Since(p.keysFetchedAt) < oidcKeyRefreshInterval {
+		return nil, errors.New("unknown id token signing key")
+	}
+
+	var jwks JWKS
+	if err := getJSON(ctx, discovery.JWKSURI, &jwks); err != nil {
+		return nil, err
+	}
+	keys := map[string]crypto.PublicKey{}
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := parseJWK(jwk)
+		if err != nil {
+			oidcLog(p.config.Name).Warn(err.Error())
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+
+	key, ok := p.keys[kid]
+	if !ok {
+		return nil, errors.New("unknown id token signing key")
+	}
+	return key, nil
+}
+
+func parseJWK(jwk JWK) (crypto.PublicKey, error) {
+	decode := base64.RawURLEncoding.DecodeString
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decode(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if jwk.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported jwk curve %q", jwk.Crv)
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		x, err := decode(jwk.X)
+		if err != nil || jwk.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("unsupported jwk %q", jwk.Kid)
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported jwk type %q", jwk.Kty)
+}
+
+func getJSON(ctx context.Context, target string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := oidcHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %d", target, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func randomToken() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func oidcLog(provider string) *logrus.Entry {
+	return log.WithFields(logrus.Fields{"LoggerName": "OIDC", "Provider": provider})
+}
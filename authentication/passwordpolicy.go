@@ -0,0 +1,171 @@
+package authentication
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"todo-web-api/messages"
+	"unicode"
+
+	"github.com/sirupsen/logrus"
+)
+
+// bcrypt only looks at the first 72 bytes of a password; anything past
+// that would be silently ignored.
+const bcryptMaxBytes = 72
+
+// PasswordPolicy is what a new password has to satisfy. The Require fields
+// each ask for at least one character of that class. BreachedPasswordsDir,
+// when set, holds known-breached passwords as SHA-1 range files: one file
+// per 5-character hash prefix (e.g. 5BAA6 or 5BAA6.txt), each line the rest
+// of a hash, optionally followed by ":count", which is the format of the
+// Have I Been Pwned range API and its downloader.
+type PasswordPolicy struct {
+	MinLength            int
+	MaxLength            int
+	RequireUpper         bool
+	RequireLower         bool
+	RequireDigit         bool
+	RequireSymbol        bool
+	AllowUsername        bool
+	BreachedPasswordsDir string
+}
+
+// PasswordViolation is one rule a password broke.
+type PasswordViolation struct {
+	Rule    string
+	Message string
+}
+
+// PasswordPolicyError lists every rule a password broke; its Error is
+// messages.PasswordPolicyViolated.
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return messages.PasswordPolicyViolated
+}
+
+var passwordPolicy = DefaultPasswordPolicy()
+
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength: 8,
+		MaxLength: bcryptMaxBytes,
+	}
+}
+
+// ConfigurePasswordPolicy replaces the password policy; zero lengths keep
+// their defaults, and MaxLength can't go past what the password hasher
+// uses (72 bytes for bcrypt). Configure the hasher first.
+func ConfigurePasswordPolicy(policy PasswordPolicy) error {
+	defaults := DefaultPasswordPolicy()
+	if policy.MinLength <= 0 {
+		policy.MinLength = defaults.MinLength
+	}
+	if policy.MaxLength <= 0 {
+		policy.MaxLength = defaults.MaxLength
+	}
+	if ceiling := passwordHasher.MaxPasswordBytes(); policy.MaxLength > ceiling {
+		policy.MaxLength = ceiling
+	}
+	if policy.MinLength > policy.MaxLength {
+		return fmt.Errorf("password policy min_length %d is above max_length %d", policy.MinLength, policy.MaxLength)
+	}
+	if policy.BreachedPasswordsDir != "" {
+		info, err := os.Stat(policy.BreachedPasswordsDir)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("breached passwords path %s is not a directory", policy.BreachedPasswordsDir)
+		}
+	}
+	passwordPolicy = policy
+	return nil
+}
+
+// ValidatePassword checks password against the policy and returns a
+// *PasswordPolicyError listing each rule it breaks, or nil. With an empty
+// username the username rule is skipped.
+func ValidatePassword(username, password string) error {
+	policy := passwordPolicy
+	var violations []PasswordViolation
+	broke := func(rule, message string) {
+		violations = append(violations, PasswordViolation{Rule: rule, Message: message})
+	}
+
+	if len([]rune(password)) < policy.MinLength {
+		broke("min_length", fmt.Sprintf("must be at least %d characters", policy.MinLength))
+	}
+	if len(password) > policy.MaxLength {
+		broke("max_length", fmt.Sprintf("must be at most %d bytes", policy.MaxLength))
+	}
+	if policy.RequireUpper && strings.IndexFunc(password, unicode.IsUpper) < 0 {
+		broke("uppercase", "must contain an uppercase letter")
+	}
+	if policy.RequireLower && strings.IndexFunc(password, unicode.IsLower) < 0 {
+		broke("lowercase", "must contain a lowercase letter")
+	}
+	if policy.RequireDigit && strings.IndexFunc(password, unicode.IsDigit) < 0 {
+		broke("digit", "must contain a digit")
+	}
+	if policy.RequireSymbol && strings.IndexFunc(password, isSymbol) < 0 {
+		broke("symbol", "must contain a symbol")
+	}
+	if !policy.AllowUsername && username != "" &&
+		strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		broke("username", "must not contain the username")
+	}
+	if breached, err := isBreachedPassword(policy.BreachedPasswordsDir, password); err != nil {
+		// A broken list shouldn't stop everyone from setting a password.
+		log.WithFields(logrus.Fields{"LoggerName": "PasswordPolicy"}).Error(err.Error())
+	} else if breached {
+		broke("breached", "appears in a list of breached passwords, choose another")
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+func isSymbol(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsSpace(r)
+}
+
+// isBreachedPassword looks the password's SHA-1 up in the range file for
+// its first five hex characters, so only that one file is ever read.
+func isBreachedPassword(dir, password string) (bool, error) {
+	if dir == "" {
+		return false, nil
+	}
+	sum := sha1.Sum([]byte(password))
+	digest := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := digest[:5], digest[5:]
+
+	file, err := os.Open(filepath.Join(dir, prefix))
+	if os.IsNotExist(err) {
+		file, err = os.Open(filepath.Join(dir, prefix+".txt"))
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entry, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if strings.EqualFold(entry, suffix) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
@@ -0,0 +1,175 @@
+package authentication
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+	"todo-web-api/mailer"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+
+	"github.com/sirupsen/logrus"
+)
+
+const PurposePasswordReset = "password_reset"
+
+const DefaultPasswordResetExpiry = 30 * time.Minute
+
+var passwordReset = struct {
+	url    string
+	expiry time.Duration
+}{expiry: DefaultPasswordResetExpiry}
+
+// ConfigurePasswordReset sets the page reset links point at, which gets the
+// token as ?token=, and how long a link stays valid. Zero keeps the default.
+func ConfigurePasswordReset(resetURL string, expiry time.Duration) {
+	passwordReset.url = resetURL
+	if expiry <= 0 {
+		expiry = DefaultPasswordResetExpiry
+	}
+	passwordReset.expiry = expiry
+}
+
+// ChangePassword sets a new password after checking the current one and
+// the password policy, then signs the user out of every session but
+// keepSessionId.
+func ChangePassword(user *models.User, currentPassword, newPassword string, keepSessionId string) error {
+	if match, _ := VerifyPassword(user.Password, currentPassword); !match {
+		return errors.New(messages.InvalidCurrentPassword)
+	}
+	if err := ValidatePassword(user.Username, newPassword); err != nil {
+		return err
+	}
+	if err := setPassword(user, newPassword); err != nil {
+		return err
+	}
+	_, err := storage.SessionManager.DeleteOtherSessions(user.Id, keepSessionId)
+	return err
+}
+
+// RequestPasswordReset mails a reset link to the account's verified email
+// address. Unknown and disabled accounts, and ones without a verified
+// address, are skipped without an error, so the response can't be used to
+// find out which usernames exist.
+func RequestPasswordReset(username string) error {
+	user, err := storage.UserManager.FindExistingAccount(username, "")
+	if err != nil && err.Error() == messages.AccountNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if user.IsDisabled || mailAddress(user) == "" {
+		return nil
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	plain := base64.RawURLEncoding.EncodeToString(secret)
+
+	// Only the newest link works.
+	if _, err := storage.OneTimeTokenManager.DeleteOneTimeTokens(user.Id, PurposePasswordReset); err != nil {
+		return err
+	}
+	token := &models.OneTimeToken{
+		UserId:    user.Id,
+		Purpose:   PurposePasswordReset,
+		TokenHash: hashToken(plain),
+		ExpiresAt: time.Now().Add(passwordReset.expiry),
+	}
+	if _, err := storage.OneTimeTokenManager.SaveOneTimeToken(token); err != nil {
+		return err
+	}
+
+	return mailer.Send(mailer.Message{
+		To:      mailAddress(user),
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Someone asked to reset the password for %s.\n\n"+
+			"Follow this link within %d minutes to choose a new one:\n\n%s\n\n"+
+			"If it wasn't you, ignore this message; your password hasn't changed.\n",
+			user.Username, int(passwordReset.expiry.Minutes()), tokenLink(passwordReset.url, plain)),
+	})
+}
+
+// ResetPassword redeems a reset token. The token is spent even if the rest
+// fails, and every session of the account is ended, along with any lockout
+// the forgotten password caused. A password the policy refuses outright is
+// turned down before the token is spent; only the username rule has to
+// wait for the account.
+func ResetPassword(plain, newPassword string) error {
+	if err := ValidatePassword("", newPassword); err != nil {
+		return err
+	}
+
+	token, err := storage.OneTimeTokenManager.ConsumeOneTimeToken(PurposePasswordReset, hashToken(plain), time.Now())
+	if err != nil && err.Error() == messages.OneTimeTokenNotFoundInDb {
+		return errors.New(messages.InvalidResetToken)
+	} else if err != nil {
+		return err
+	}
+
+	user, err := storage.UserManager.GetUser(token.UserId)
+	if err != nil && err.Error() == messages.UserNotFound {
+		return errors.New(messages.InvalidResetToken)
+	} else if err != nil {
+		return err
+	}
+	if user.IsDisabled {
+		return errors.New(messages.InvalidResetToken)
+	}
+	if err := ValidatePassword(user.Username, newPassword); err != nil {
+		return err
+	}
+
+	if err := setPassword(user, newPassword); err != nil {
+		return err
+	}
+	if err := RevokeUserSessions(user.Id); err != nil {
+		return err
+	}
+	ResetLoginFailures(user.Username)
+	return nil
+}
+
+func PurgeExpiredOneTimeTokens() {
+	count, err := storage.OneTimeTokenManager.DeleteExpiredOneTimeTokens(time.Now())
+	if err != nil {
+		log.WithFields(logrus.Fields{"LoggerName": "SessionCleanup"}).Error(err.Error())
+		return
+	}
+	if count > 0 {
+		log.WithFields(logrus.Fields{"LoggerName": "SessionCleanup", "Count": count}).Info("expired one-time tokens removed")
+	}
+}
+
+// RehashPassword stores a new hash of password, which has just been
+// verified, made with the current hasher. A failure only means the old
+// hash stays for another login.
+func RehashPassword(user *models.User, password string) {
+	if err := setPassword(user, password); err != nil {
+		log.WithFields(logrus.Fields{"LoggerName": "PasswordRehash", "UserId": user.Id}).Error(err.Error())
+	}
+}
+
+func setPassword(user *models.User, password string) error {
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	user.Password = hash
+	_, err = storage.UserManager.UpdateUser(user)
+	return err
+}
+
+// mailAddress is where mail for user goes: its email address once verified,
+// otherwise nowhere.
+func mailAddress(user *models.User) string {
+	if !user.EmailVerified {
+		return ""
+	}
+	return user.Email
+}
@@ -0,0 +1,217 @@
+package authentication
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+	authz "todo-web-api/authorization"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// NewSession prepares a session for a fresh login. It is persisted by
+// StartSession once its tokens have been signed with its SessionId.
+func NewSession(userId int, username, userAgent, ipAddress string) *models.Session {
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+	now := time.Now()
+	return &models.Session{
+		SessionId:  uuid.NewString(),
+		UserId:     userId,
+		Username:   username,
+		UserAgent:  userAgent,
+		IPAddress:  ipAddress,
+		LastUsedAt: now,
+		CreatedAt:  now,
+	}
+}
+
+// StartSession stores the token pair issued for a new session.
+func StartSession(session *models.Session, accessToken, refreshToken string) error {
+	session.AccessToken = hashToken(accessToken)
+	session.RefreshToken = hashToken(refreshToken)
+	session.ExpiresAt = latestExpiry(session.ExpiresAt, accessToken, refreshToken)
+
+	_, err := storage.SessionManager.SaveSession(session)
+	return err
+}
+
+// IsTokenActive reports whether token is the current access token of a live
+// session.
+func IsTokenActive(sessionID, token string) bool {
+	session, err := findSession(sessionID)
+	return err == nil && session != nil && session.AccessToken == hashToken(token) && time.Now().Before(session.ExpiresAt)
+}
+
+func IsRefreshTokenActive(sessionID string) bool {
+	session, err := findSession(sessionID)
+	return err == nil && session != nil && session.RefreshToken != "" && time.Now().Before(session.ExpiresAt)
+}
+
+// RotateRefreshToken issues a new token pair for the session of claims and
+// invalidates oldToken. If oldToken is no longer the session's current
+// refresh token it has been used before, and the whole session is revoked.
+//
+// The user is reloaded so role changes apply and disabled accounts can't
+// keep their sessions alive.
+func RotateRefreshToken(claims *Claims, oldToken string) (accessToken, refreshToken string, err error) {
+	user, err := storage.UserManager.GetUser(claims.UserID)
+	if err != nil {
+		return "", "", err
+	}
+	if user.IsDisabled {
+		RevokeSession(claims.SessionID)
+		return "", "", errors.New(messages.AccountDisabled)
+	}
+
+	accessToken, err = GenerateAccessToken(user.Username, user.Id, claims.SessionID, Roles(user))
+	if err != nil {
+		return "", "", err
+	}
+	refreshToken, err = GenerateRefreshToken(claims.UserID, claims.Username, claims.SessionID)
+	if err != nil {
+		return "", "", err
+	}
+
+	expiresAt := latestExpiry(time.Time{}, accessToken, refreshToken)
+	rotated, err := storage.SessionManager.RotateSessionTokens(claims.SessionID, hashToken(oldToken),
+		hashToken(accessToken), hashToken(refreshToken), expiresAt)
+	if err != nil {
+		return "", "", err
+	}
+	if !rotated {
+		revokeReusedSession(claims)
+		return "", "", errors.New(messages.RefreshTokenReused)
+	}
+	return accessToken, refreshToken, nil
+}
+
+// Roles lists the roles carried in the user's access tokens. Users with an
+// unverified email address get the unverified role instead of their own.
+func Roles(user *models.User) []string {
+	if user.Role == "" {
+		return nil
+	}
+	if user.Role == authz.RoleUser && IsEmailUnverified(user) {
+		return []string{authz.RoleUnverified}
+	}
+	return []string{user.Role}
+}
+
+// GetSessions returns the user's sessions that have not expired yet.
+func GetSessions(userId int) ([]models.Session, error) {
+	sessions, err := storage.SessionManager.GetSessionsForUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	active := make([]models.Session, 0, len(sessions))
+	for _, session := range sessions {
+		if now.Before(session.ExpiresAt) {
+			active = append(active, session)
+		}
+	}
+	return active, nil
+}
+
+// GetUserSession returns nil when the session doesn't exist or belongs to
+// another user.
+func GetUserSession(userId int, sessionID string) (*models.Session, error) {
+	session, err := findSession(sessionID)
+	if err != nil || session == nil || session.UserId != userId {
+		return nil, err
+	}
+	return session, nil
+}
+
+func RevokeSession(sessionID string) error {
+	_, err := storage.SessionManager.DeleteSession(sessionID)
+	return err
+}
+
+// RevokeUserSessions signs the user out on every device.
+func RevokeUserSessions(userId int) error {
+	_, err := storage.SessionManager.DeleteSessionsForUser(userId)
+	return err
+}
+
+// StartSessionCleanup deletes expired sessions, access tokens and one-time
+// tokens, login attempt counters that no longer matter and accounts whose
+// deletion is due, every interval until the process exits.
+func StartSessionCleanup(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			PurgeExpiredSessions()
+			PurgeStaleLoginAttempts()
+			PurgeExpiredAccessTokens()
+			PurgeExpiredOneTimeTokens()
+			PurgeScheduledAccountDeletions()
+		}
+	}()
+}
+
+func PurgeExpiredSessions() {
+	count, err := storage.SessionManager.DeleteExpiredSessions(time.Now())
+	if err != nil {
+		log.WithFields(logrus.Fields{"LoggerName": "SessionCleanup"}).Error(err.Error())
+		return
+	}
+	if count > 0 {
+		log.WithFields(logrus.Fields{"LoggerName": "SessionCleanup", "Count": count}).Info("expired sessions removed")
+	}
+}
+
+// revokeReusedSession signs the user out everywhere when a refresh token is
+// replayed. Whoever stole it may have rotated it into a session of its own,
+// or stolen other tokens along with it, so ending just the one session
+// isn't enough.
+func revokeReusedSession(claims *Claims) {
+	log.WithFields(logrus.Fields{
+		"LoggerName": "RefreshToken",
+		"Username":   claims.Username,
+		"SessionID":  claims.SessionID,
+	}).Warn(messages.RefreshTokenReused)
+
+	if err := RevokeUserSessions(claims.UserID); err != nil {
+		log.WithFields(logrus.Fields{"LoggerName": "RefreshToken"}).Error(err.Error())
+	}
+}
+
+// findSession returns nil without an error when the session doesn't exist.
+func findSession(sessionID string) (*models.Session, error) {
+	session, err := storage.SessionManager.GetSession(sessionID)
+	if err != nil && err.Error() == messages.SessionNotFoundInDb {
+		return nil, nil
+	}
+	return session, err
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// latestExpiry extends current to the latest exp claim of the given tokens,
+// which this service has just signed.
+func latestExpiry(current time.Time, tokens ...string) time.Time {
+	for _, token := range tokens {
+		claims := &Claims{}
+		if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil || claims.ExpiresAt == nil {
+			continue
+		}
+		if claims.ExpiresAt.Time.After(current) {
+			current = claims.ExpiresAt.Time
+		}
+	}
+	return current
+}
@@ -0,0 +1,81 @@
+package authorization
+
+import (
+	"todo-web-api/messages"
+	"todo-web-api/storage"
+)
+
+// List roles, checked per route by middleware.RequireListRole. The owner
+// is the list's UserId; editors and viewers are invited as list members.
+const (
+	ListRoleOwner  = "owner"
+	ListRoleEditor = "editor"
+	ListRoleViewer = "viewer"
+)
+
+// Each role can do everything the roles ranked below it can.
+var listRoleRank = map[string]int{
+	ListRoleViewer: 1,
+	ListRoleEditor: 2,
+	ListRoleOwner:  3,
+}
+
+// IsInvitableListRole: a list has exactly one owner, so members can only
+// be editors or viewers.
+func IsInvitableListRole(role string) bool {
+	return role == ListRoleEditor || role == ListRoleViewer
+}
+
+// HasListRole reports whether role is at least required. No role, "",
+// grants nothing.
+func HasListRole(role string, required string) bool {
+	return listRoleRank[role] > 0 && listRoleRank[role] >= listRoleRank[required]
+}
+
+// ListResolver returns the id of the list the resource with the given id
+// belongs to.
+type ListResolver func(id int) (int, error)
+
+// ListById: a list belongs to itself.
+func ListById(id int) (int, error) {
+	return id, nil
+}
+
+func ListOfTask(id int) (int, error) {
+	task, err := storage.TaskManager.GetTask(id)
+	if err != nil {
+		return 0, err
+	}
+	return task.ListId, nil
+}
+
+func ListOfTrashedTask(id int) (int, error) {
+	task, err := storage.TaskManager.GetTrashedTask(id)
+	if err != nil {
+		return 0, err
+	}
+	return task.ListId, nil
+}
+
+// ListRole returns userId's role on the list, or "" when they have none.
+// Invitations that haven't been accepted grant nothing.
+func ListRole(listId int, userId int) (string, error) {
+	list, err := storage.ListManager.GetList(listId)
+	if err != nil {
+		return "", err
+	}
+	if list.UserId == userId {
+		return ListRoleOwner, nil
+	}
+
+	member, err := storage.ListMemberManager.GetListMember(listId, userId)
+	if err != nil && err.Error() == messages.ListMemberNotFoundInDb {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	if member.AcceptedAt == nil {
+		return "", nil
+	}
+	return member.Role, nil
+}
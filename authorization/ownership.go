@@ -0,0 +1,44 @@
+package authorization
+
+import (
+	"todo-web-api/messages"
+	"todo-web-api/storage"
+)
+
+// OwnerResolver returns the id of the user that owns the resource with the
+// given id.
+type OwnerResolver func(id int) (int, error)
+
+// IsNotFound reports whether a resolver's error is the store saying the
+// resource doesn't exist, rather than that the lookup failed.
+func IsNotFound(err error) bool {
+	switch err.Error() {
+	case messages.ListNotFoundInDb, messages.TaskNotFoundInDb, messages.ListTemplateNotFoundInDb:
+		return true
+	}
+	return false
+}
+
+// UserOwner: a user account is owned by that same user.
+func UserOwner(id int) (int, error) {
+	return id, nil
+}
+
+// TrashedListOwner: only the owner of a list in the trash can restore it.
+func TrashedListOwner(id int) (int, error) {
+	list, err := storage.ListManager.GetTrashedList(id)
+	if err != nil {
+		return 0, err
+	}
+	return list.UserId, nil
+}
+
+// TemplateOwner: list templates aren't shared, so only their owner can use
+// or delete them.
+func TemplateOwner(id int) (int, error) {
+	template, err := storage.ListTemplateManager.GetListTemplate(id)
+	if err != nil {
+		return 0, err
+	}
+	return template.UserId, nil
+}
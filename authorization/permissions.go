@@ -0,0 +1,94 @@
+package authorization
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+	// RoleUnverified stands in for RoleUser in the tokens of accounts whose
+	// email address hasn't been verified yet. It is never stored.
+	RoleUnverified = "unverified"
+)
+
+// Permissions are checked per route by middleware.RequirePermission.
+const (
+	PermAccountRead  = "account:read"
+	PermAccountWrite = "account:write"
+	PermListsRead    = "lists:read"
+	PermListsWrite   = "lists:write"
+	PermTasksWrite   = "tasks:write"
+	PermUsersRead    = "users:read"
+	PermUsersManage  = "users:manage"
+)
+
+var userPermissions = []string{
+	PermAccountRead,
+	PermAccountWrite,
+	PermListsRead,
+	PermListsWrite,
+	PermTasksWrite,
+}
+
+// Unverified accounts can look around and manage their account, but not
+// create anything until the address is confirmed.
+var unverifiedPermissions = []string{
+	PermAccountRead,
+	PermAccountWrite,
+	PermListsRead,
+}
+
+var rolePermissions = map[string][]string{
+	RoleUser:       userPermissions,
+	RoleUnverified: unverifiedPermissions,
+	RoleAdmin:      append(append([]string{}, userPermissions...), PermUsersRead, PermUsersManage),
+}
+
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// PermissionsFor returns the union of the permissions granted by roles.
+// Accounts created before roles existed have none and count as users.
+func PermissionsFor(roles ...string) []string {
+	if len(roles) == 0 {
+		roles = []string{RoleUser}
+	}
+
+	seen := map[string]bool{}
+	permissions := []string{}
+	for _, role := range roles {
+		for _, permission := range rolePermissions[role] {
+			if !seen[permission] {
+				seen[permission] = true
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions
+}
+
+func HasPermission(granted []string, permission string) bool {
+	for _, p := range granted {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Personal access tokens can carry any of their owner's permissions except
+// account:write, so a leaked token can't mint more tokens, turn off
+// two-factor or sign the owner out.
+func IsScopable(permission string) bool {
+	return permission != PermAccountWrite && HasPermission(PermissionsFor(RoleAdmin), permission)
+}
+
+// Intersect returns the permissions present in both lists.
+func Intersect(granted []string, scopes []string) []string {
+	permissions := []string{}
+	for _, permission := range granted {
+		if HasPermission(scopes, permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+	return permissions
+}
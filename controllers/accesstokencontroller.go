@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	auth "todo-web-api/authentication"
+	h "todo-web-api/helpers"
+	"todo-web-api/loggerutils"
+	msg "todo-web-api/messages"
+	models "todo-web-api/models"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+// List Access Tokens endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Get Access Tokens
+//	@Schemes
+//	@Description	List the current user's personal access tokens (never the token values)
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{array}		h.AccessTokenResult		"Successful"
+//	@Failure		401	{object}	h.UnauthorizedResponse	"Unauthorized"
+//	@Failure		500	{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/AccessTokens [get]
+func GetAccessTokens(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	tokens, err := auth.GetAccessTokens(c.GetInt("user_id"))
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.SomethingWentWrong})
+		return
+	}
+
+	results := make([]h.AccessTokenResult, 0, len(tokens))
+	for _, token := range tokens {
+		results = append(results, accessTokenResult(&token))
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// Create Access Token endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Create Access Token
+//	@Schemes
+//	@Description	Create a named, scoped personal access token for scripts. The token is only returned in this response; send it as "Authorization: Bearer <token>".
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			Request	body		h.CreateAccessToken				true	"Access Token Request"
+//	@Success		200		{object}	h.CreatedAccessTokenResponse	"Successful"
+//	@Failure		400		{object}	h.BadRequestResponse			"Bad Request"
+//	@Failure		500		{object}	h.ErrorResponse					"Internal Server Error"
+//	@Router			/AccessTokens [post]
+func CreateAccessToken(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req h.CreateAccessToken
+	if err := c.ShouldBindJSON(&req); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: err.Error()})
+		return
+	}
+	if req.ExpiresInDays == 0 {
+		req.ExpiresInDays = auth.DefaultAccessTokenDays
+	}
+
+	user, ok := findCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	plain, token, err := auth.CreateAccessToken(user, strings.TrimSpace(req.Name), req.Scopes, req.ExpiresInDays)
+	if err != nil && (err.Error() == msg.InvalidScope || err.Error() == msg.InvalidTokenExpiry) {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: err.Error()})
+		return
+	} else if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.SomethingWentWrong})
+		return
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, msg.SuccessAccessTokenCreate)
+	c.JSON(http.StatusOK, h.CreatedAccessTokenResponse{
+		Status:      200,
+		Message:     msg.SuccessAccessTokenCreate,
+		Token:       plain,
+		AccessToken: accessTokenResult(token)})
+}
+
+// Revoke Access Token endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Revoke Access Token
+//	@Schemes
+//	@Description	Revoke one of the current user's personal access tokens
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int						true	"Access Token ID"
+//	@Success		200	{object}	h.DeleteResult			"Successful"
+//	@Failure		400	{object}	h.BadRequestResponse	"Bad Request"
+//	@Failure		404	{object}	h.NotFoundResponse		"Not Found"
+//	@Failure		500	{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/AccessTokens/{id} [delete]
+func RevokeAccessToken(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: msg.InvalidResourceId})
+		return
+	}
+
+	revoked, err := auth.RevokeAccessToken(c.GetInt("user_id"), id)
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.SomethingWentWrong})
+		return
+	}
+	if !revoked {
+		loggerutils.ErrorLog(ctx, http.StatusNotFound, errors.New(msg.AccessTokenNotFound))
+		c.JSON(http.StatusNotFound, h.NotFoundResponse{
+			Status:  404,
+			Message: msg.AccessTokenNotFound})
+		return
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, msg.SuccessAccessTokenRevoke)
+	c.JSON(http.StatusOK, h.DeleteResult{
+		Status:  200,
+		Message: msg.SuccessAccessTokenRevoke,
+		Success: true})
+}
+
+func accessTokenResult(token *models.PersonalAccessToken) h.AccessTokenResult {
+	return h.AccessTokenResult{
+		Id:         token.Id,
+		Name:       token.Name,
+		Prefix:     token.Prefix,
+		Scopes:     strings.Fields(token.Scopes),
+		CreatedAt:  token.CreatedAt,
+		LastUsedAt: token.LastUsedAt,
+		ExpiresAt:  token.ExpiresAt,
+	}
+}
@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"net/http"
+	auth "todo-web-api/authentication"
+	h "todo-web-api/helpers"
+	"todo-web-api/loggerutils"
+	msg "todo-web-api/messages"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+// Delete Account endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Delete Account
+//	@Schemes
+//	@Description	Delete the account with all of its lists, tasks and sessions, given the password. With a grace period configured the deletion is scheduled instead, and can be cancelled until deletionScheduledAt.
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			Request	body		h.DeleteAccount				true	"Delete Account Request"
+//	@Success		200		{object}	h.AccountDeletionResponse	"Successful"
+//	@Failure		400		{object}	h.BadRequestResponse		"Bad Request"
+//	@Failure		423		{object}	h.ErrorResponse				"Account Locked"
+//	@Failure		429		{object}	h.ErrorResponse				"Too Many Attempts"
+//	@Failure		500		{object}	h.ErrorResponse				"Internal Server Error"
+//	@Router			/Account [delete]
+func DeleteAccount(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req h.DeleteAccount
+	if err := c.ShouldBindJSON(&req); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: err.Error()})
+		return
+	}
+
+	user, ok := findCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	// Guessing the password here is throttled like a login.
+	if loginThrottled(c, user.Username) {
+		return
+	}
+
+	deleteAt, err := auth.DeleteAccount(user, req.Password)
+	if err != nil && err.Error() == msg.InvalidCurrentPassword {
+		auth.RecordLoginFailure(user.Username, c.ClientIP())
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: msg.InvalidCurrentPassword})
+		return
+	} else if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.SomethingWentWrong})
+		return
+	}
+
+	if deleteAt != nil {
+		loggerutils.InfoLog(ctx, http.StatusOK, msg.SuccessAccountDeletionScheduled)
+		c.JSON(http.StatusOK, h.AccountDeletionResponse{
+			Status:              200,
+			Message:             msg.SuccessAccountDeletionScheduled,
+			DeletionScheduledAt: deleteAt})
+		return
+	}
+
+	// The sessions went with the account; the cookies are all that's left.
+	clearAuthCookies(c)
+
+	loggerutils.InfoLog(ctx, http.StatusOK, msg.SuccessAccountDeleted)
+	c.JSON(http.StatusOK, h.AccountDeletionResponse{
+		Status:  200,
+		Message: msg.SuccessAccountDeleted})
+}
+
+// Cancel Account Deletion endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Cancel Account Deletion
+//	@Schemes
+//	@Description	Keep an account whose deletion was scheduled and hasn't gone through yet
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	h.SuccessResponse		"Successful"
+//	@Failure		400	{object}	h.BadRequestResponse	"Not Scheduled"
+//	@Failure		500	{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/CancelAccountDeletion [post]
+func CancelAccountDeletion(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, ok := findCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	err := auth.CancelAccountDeletion(user)
+	if err != nil && err.Error() == msg.AccountDeletionNotScheduled {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: msg.AccountDeletionNotScheduled})
+		return
+	} else if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.SomethingWentWrong})
+		return
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, msg.SuccessAccountDeletionCancelled)
+	c.JSON(http.StatusOK, h.SuccessResponse{
+		Status:  200,
+		Message: msg.SuccessAccountDeletionCancelled})
+}
@@ -2,10 +2,12 @@ package controllers
 
 import (
 	"errors"
+	"math"
 	http "net/http"
 	"strconv"
 	"time"
 	auth "todo-web-api/authentication"
+	authz "todo-web-api/authorization"
 	h "todo-web-api/helpers"
 	"todo-web-api/loggerutils"
 	models "todo-web-api/models"
@@ -15,7 +17,6 @@ import (
 	msg "todo-web-api/messages"
 
 	gin "github.com/gin-gonic/gin"
-	bcr "golang.org/x/crypto/bcrypt"
 )
 
 var log = loggerutils.GetLogger()
@@ -25,12 +26,15 @@ var log = loggerutils.GetLogger()
 //	@BasePath	/api/v1
 //	@Summary	Login
 //	@Schemes
-//	@Description	Sign-In with user credentials, for generated access token
+//	@Description	Sign-In with user credentials, for generated access token. Accounts with two-factor enabled get an mfaToken instead, to exchange at /LoginMfa.
 //	@Accept			json
 //	@Produce		json
 //	@Param			Request	body		h.User					true	"Login Request"
 //	@Success		200		{object}	h.SuccessResponse		"Successful"
 //	@Failure		400		{object}	h.BadRequestResponse	"Bad Request"
+//	@Failure		403		{object}	h.ForbiddenResponse		"Account Disabled"
+//	@Failure		423		{object}	h.ErrorResponse			"Account Locked"
+//	@Failure		429		{object}	h.ErrorResponse			"Too Many Attempts"
 //	@Failure		500		{object}	h.ErrorResponse			"Internal Server Error"
 //	@Router			/Login [post]
 func Login(c *gin.Context) {
@@ -45,13 +49,17 @@ func Login(c *gin.Context) {
 			return
 	}
 
-	if auth.IsTokenActive(req.Username) || auth.IsRefreshTokenActive(req.Username) {
-		auth.RemoveToken(req.Username)
-		auth.RemoveRefreshToken(req.Username)
+	// Refuse throttled logins before the password is even looked at, so a
+	// locked account can't be probed.
+	if loginThrottled(c, req.Username) {
+		recordAudit(c, auth.AuditLogin, auth.AuditFailure, "throttled", 0, req.Username)
+		return
 	}
 
 	existingAccount, err := s.UserManager.FindExistingAccount(req.Username, req.Password)
 	if err != nil && err.Error() == msg.AccountNotFound {
+		auth.RecordLoginFailure(req.Username, c.ClientIP())
+		recordAudit(c, auth.AuditLogin, auth.AuditFailure, "account_not_found", 0, req.Username)
 
 		loggerutils.ErrorLog(ctx, http.StatusNotFound, err)
 
@@ -59,13 +67,22 @@ func Login(c *gin.Context) {
 			Status:  404,
 			Message: msg.AccountNotFound})
 		return
+	} else if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.SomethingWentWrong})
+		return
 	}
 
-	err = bcr.CompareHashAndPassword([]byte(existingAccount.Password), []byte(req.Password))
-	matchingPassword := err == nil
+	matchingPassword, needsRehash := auth.VerifyPassword(existingAccount.Password, req.Password)
 
 	if !matchingPassword {
-		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+		auth.RecordLoginFailure(req.Username, c.ClientIP())
+		recordAudit(c, auth.AuditLogin, auth.AuditFailure, "invalid_password", existingAccount.Id, existingAccount.Username)
+
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, errors.New(msg.InvalidPassword))
 
 		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
 			Status:  400,
@@ -73,47 +90,86 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	token, err := auth.GenerateAccessToken(existingAccount.Username, existingAccount.Id)
-	if err != nil {
-		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+	if existingAccount.IsDisabled {
+		recordAudit(c, auth.AuditLogin, auth.AuditFailure, "account_disabled", existingAccount.Id, existingAccount.Username)
+		loggerutils.ErrorLog(ctx, http.StatusForbidden, errors.New(msg.AccountDisabled))
 
-		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
-			Status:  500,
-			Message: msg.AccessTokenError})
+		c.JSON(http.StatusForbidden, h.ForbiddenResponse{
+			Status:  403,
+			Message: msg.AccountDisabled})
 		return
 	}
 
-	refreshToken, err := auth.GenerateRefreshToken(existingAccount.Id, existingAccount.Username)
-	if err != nil {
-		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Error while generating refresh token."})
+	// The plain password is only at hand here, so hashes made with an older
+	// algorithm or weaker parameters are upgraded as users sign in.
+	if needsRehash {
+		auth.RehashPassword(existingAccount, req.Password)
+	}
+
+	// With two-factor on, the password only earns a short-lived mfa token;
+	// LoginMfa issues the session once the code checks out.
+	if existingAccount.TOTPEnabled {
+		mfaToken, err := auth.GenerateMfaToken(existingAccount)
+		if err != nil {
+			loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+			c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+				Status:  500,
+				Message: msg.SomethingWentWrong})
+			return
+		}
+
+		recordAudit(c, auth.AuditLogin, auth.AuditPending, "mfa_required", existingAccount.Id, existingAccount.Username)
+		loggerutils.InfoLog(ctx, http.StatusOK, msg.MfaRequired)
+		c.JSON(http.StatusOK, h.MfaChallengeResponse{
+			Status:      200,
+			Message:     msg.MfaRequired,
+			MfaRequired: true,
+			MfaToken:    mfaToken})
 		return
 	}
 
-	http.SetCookie(c.Writer, &http.Cookie{
-	Name:     "access_token",
-	Value:    token,
-	Path:     "/",
-	Domain:   "",
-	MaxAge:   3600,
-	HttpOnly: true,
-	Secure:   true,
-	SameSite: http.SameSiteNoneMode,
-})
-
-	cookie := &http.Cookie{
-		Name:     "refresh_token",
-		Value:    refreshToken,
-		Path:     "/",
-		Domain:   "",
-		Secure:   true,
-		HttpOnly: true,
-		SameSite: http.SameSiteNoneMode,
+	startLoginSession(c, existingAccount)
+}
+
+// loginThrottled writes a 423 or 429 with Retry-After, and returns true,
+// when failed attempts for username or this client mean it has to wait.
+func loginThrottled(c *gin.Context, username string) bool {
+	ctx := c.Request.Context()
+
+	retryAfter, err := auth.CheckLoginAllowed(username, c.ClientIP())
+	if err != nil && (err.Error() == msg.AccountLocked || err.Error() == msg.TooManyLoginAttempts) {
+		status := http.StatusTooManyRequests
+		if err.Error() == msg.AccountLocked {
+			status = http.StatusLocked
+		}
+		loggerutils.ErrorLog(ctx, status, err)
+
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		c.JSON(status, h.ErrorResponse{
+			Status:  status,
+			Message: err.Error()})
+		return true
+	} else if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.SomethingWentWrong})
+		return true
+	}
+	return false
+}
+
+// startLoginSession finishes a login once every factor has been checked:
+// it opens a session, sets the auth cookies and clears the failure count.
+func startLoginSession(c *gin.Context, existingAccount *models.User) {
+	ctx := c.Request.Context()
+
+	if !openSession(c, existingAccount) {
+		return
 	}
-	http.SetCookie(c.Writer, cookie)
 
-	auth.SaveToken(existingAccount.Username, token)
-	auth.SaveRefreshToken(existingAccount.Username, refreshToken)
 	loggerutils.InfoLog(ctx, http.StatusOK, msg.SuccessLogin)
 	resp := h.AuthStatusResponse{Status: 200,
 		Message: "Successful Login",
@@ -128,23 +184,65 @@ func Login(c *gin.Context) {
 		resp)
 }
 
+// openSession does the work of startLoginSession short of the response,
+// for callers that answer differently. It writes the error response itself
+// and returns false when the session can't be opened.
+func openSession(c *gin.Context, existingAccount *models.User) bool {
+	ctx := c.Request.Context()
+
+	auth.ResetLoginFailures(existingAccount.Username)
+
+	// Every login is its own session, so signing in on another device
+	// leaves existing sessions untouched.
+	session := auth.NewSession(existingAccount.Id, existingAccount.Username, c.Request.UserAgent(), c.ClientIP())
+
+	token, err := auth.GenerateAccessToken(existingAccount.Username, existingAccount.Id, session.SessionId, auth.Roles(existingAccount))
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.AccessTokenError})
+		return false
+	}
+
+	refreshToken, err := auth.GenerateRefreshToken(existingAccount.Id, existingAccount.Username, session.SessionId)
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"message": msg.RefreshTokenError})
+		return false
+	}
+
+	if err := auth.StartSession(session, token, refreshToken); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.SessionSaveError})
+		return false
+	}
+
+	setAuthCookies(c, token, refreshToken)
+	recordAudit(c, auth.AuditLogin, auth.AuditSuccess, "", existingAccount.Id, existingAccount.Username)
+	return true
+}
+
 // Register endpoint for Todo godoc
 //
 //	@BasePath	/api/v1
 //	@Summary	Register
 //	@Schemes
-//	@Description	Create User Account
+//	@Description	Create User Account. The password has to meet the password policy; a 400 lists each rule it breaks. With an email address the account is restricted until the address is verified through the link mailed to it.
 //	@Accept			json
 //	@Produce		json
-//	@Param			Request	body		h.User					true	"Login Request"
-//	@Success		200		{object}	h.SaveResponse			"Success"
-//	@Failure		400		{object}	h.BadRequestResponse	"Bad Request"
+//	@Param			Request	body		h.Register					true	"Register Request"
+//	@Success		200		{object}	h.SaveResponse				"Success"
+//	@Failure		400		{object}	h.PasswordPolicyResponse	"Bad Request Or Password Policy"
 //	@Failure		500		{object}	h.ErrorResponse			"Internal Server Error"
 //	@Router			/Register [post]
 func Register(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	var req h.User
+	var req h.Register
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
@@ -153,9 +251,48 @@ func Register(c *gin.Context) {
 		return
 	}
 
-	user := &models.User{Username: req.Username, Password: string(Hash(req.Password)), CreatedAt: time.Now()}
+	if err := auth.ValidatePassword(req.Username, req.Password); err != nil {
+		recordAudit(c, auth.AuditRegister, auth.AuditFailure, "password_policy", 0, req.Username)
+		respondPasswordPolicyError(c, err)
+		return
+	}
+
+	email := auth.NormalizeEmail(req.Email)
+	if email != "" {
+		_, err := s.UserManager.GetUserByVerifiedEmail(email)
+		if err == nil {
+			recordAudit(c, auth.AuditRegister, auth.AuditFailure, "email_in_use", 0, req.Username)
+			loggerutils.ErrorLog(ctx, http.StatusBadRequest, errors.New(msg.EmailInUse))
+
+			c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+				Status:  400,
+				Message: msg.EmailInUse})
+			return
+		} else if err.Error() != msg.UserNotFound {
+			loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+			c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+				Status:  500,
+				Message: msg.SomethingWentWrong})
+			return
+		}
+	}
+
+	hash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.SomethingWentWrong,
+		})
+		return
+	}
+
+	user := &models.User{Username: req.Username, Password: hash, Role: authz.RoleUser, Email: email, CreatedAt: time.Now()}
 	id, err := s.UserManager.CreateUser(user)
 	if err != nil {
+		recordAudit(c, auth.AuditRegister, auth.AuditFailure, "create_failed", 0, req.Username)
 		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
 
 		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
@@ -165,6 +302,16 @@ func Register(c *gin.Context) {
 		return
 	}
 
+	// The account exists either way; a failed mail can be sent again
+	// from /ResendVerification.
+	if email != "" {
+		user.Id = id
+		if err := auth.SendEmailVerification(user); err != nil {
+			loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+		}
+	}
+
+	recordAudit(c, auth.AuditRegister, auth.AuditSuccess, "", id, req.Username)
 	loggerutils.InfoLog(ctx, http.StatusOK, msg.SuccessUserCreate)
 	c.JSON(http.StatusOK, h.SaveResponse{
 		Status: 200,
@@ -186,6 +333,7 @@ func Register(c *gin.Context) {
 //	@Success		200	{object}	h.UserResult			"Success"
 //	@Success		200	{object}	h.SuccessResponse		"Successful"
 //	@Failure		400	{object}	h.BadRequestResponse	"Bad Request"	//	Failed	due	to	bad	request	(e.g., validation error)
+//	@Failure		403	{object}	h.ForbiddenResponse	"Forbidden"
 //	@Failure		500	{object}	h.ErrorResponse			"Internal Server Error"
 //	@Router			/GetUser/{id} [get]
 func GetUserById(c *gin.Context) {
@@ -218,8 +366,11 @@ func GetUserById(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, h.UserResult{
-		Username:  user.Username,
-		CreatedAt: user.CreatedAt,
+		Username:            user.Username,
+		Email:               user.Email,
+		EmailVerified:       user.EmailVerified,
+		DeletionScheduledAt: user.DeletionScheduledAt,
+		CreatedAt:           user.CreatedAt,
 	})
 }
 
@@ -234,20 +385,40 @@ func RefreshToken(c *gin.Context) {
 	}
 
 	claims, err := auth.ParseRefreshToken(tokenStr)
-	if err != nil {
+	if err != nil && err.Error() == msg.RefreshTokenReused {
+		recordAudit(c, auth.AuditTokenRefresh, auth.AuditFailure, "refresh_token_reused", claims.UserID, claims.Username)
+		loggerutils.ErrorLog(ctx, http.StatusUnauthorized, err)
+		clearAuthCookies(c)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": msg.RefreshTokenReused})
+		return
+	} else if err != nil {
+		recordAudit(c, auth.AuditTokenRefresh, auth.AuditFailure, "invalid_refresh_token", 0, "")
 		loggerutils.ErrorLog(ctx, http.StatusUnauthorized, err)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": msg.InvalidRefreshToken})
 		return
 	}
 
-	if !auth.IsRefreshTokenActive(claims.Username) {
-		loggerutils.ErrorLog(ctx, http.StatusUnauthorized, err)
+	if !auth.IsRefreshTokenActive(claims.SessionID) {
+		recordAudit(c, auth.AuditTokenRefresh, auth.AuditFailure, "session_inactive", claims.UserID, claims.Username)
+		loggerutils.ErrorLog(ctx, http.StatusUnauthorized, errors.New(msg.UnauthorizedRefreshToken))
 		c.JSON(http.StatusUnauthorized, gin.H{"error": msg.UnauthorizedRefreshToken})
 		return
 	}
 
-	newAccessToken, err := auth.GenerateAccessToken(claims.Username, claims.UserID)
-	if err != nil {
+	// Every refresh rotates the refresh token too; the one just presented
+	// stops working.
+	newAccessToken, newRefreshToken, err := auth.RotateRefreshToken(claims, tokenStr)
+	if err != nil && (err.Error() == msg.RefreshTokenReused || err.Error() == msg.AccountDisabled) {
+		detail := "refresh_token_reused"
+		if err.Error() == msg.AccountDisabled {
+			detail = "account_disabled"
+		}
+		recordAudit(c, auth.AuditTokenRefresh, auth.AuditFailure, detail, claims.UserID, claims.Username)
+		loggerutils.ErrorLog(ctx, http.StatusUnauthorized, err)
+		clearAuthCookies(c)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
 		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
 		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
 			Status: 500,
@@ -256,6 +427,9 @@ func RefreshToken(c *gin.Context) {
 		return
 	}
 
+	setAuthCookies(c, newAccessToken, newRefreshToken)
+	recordAudit(c, auth.AuditTokenRefresh, auth.AuditSuccess, "", claims.UserID, claims.Username)
+
 	c.JSON(http.StatusOK, gin.H{
 		"access_token": newAccessToken,
 	})
@@ -277,24 +451,21 @@ func RefreshToken(c *gin.Context) {
 func Logout(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	cookieToken, err := c.Cookie("access_token")
-	if err != nil  || cookieToken == ""{
+	// AuthMiddleware has already verified the token and set its session.
+	sessionID := c.GetString("session_id")
+	if sessionID == "" {
 		loggerutils.ErrorLog(ctx, http.StatusUnauthorized, errors.New(msg.NoTokenProvided))
 		c.JSON(http.StatusUnauthorized, gin.H{"message": msg.NoTokenProvided})
+		return
 	}
 
-	claims, err := auth.ParseToken(cookieToken)
-	if err != nil {
-		loggerutils.ErrorLog(ctx, http.StatusUnauthorized, errors.New(msg.InvalidToken))
-		c.JSON(http.StatusUnauthorized, gin.H{"message": msg.InvalidToken})
+	if err := auth.RevokeSession(sessionID); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
 	}
 
-	auth.RemoveToken(claims.Username)
-	auth.RemoveRefreshToken(claims.Username)
-
 	//Remove tokens from browser cookies
-	c.SetCookie("access_token", "", -1, "/", "", true, true) 
-	c.SetCookie("refresh_token", "", -1, "/", "", true, true) 
+	clearAuthCookies(c)
+	recordAudit(c, auth.AuditLogout, auth.AuditSuccess, "", c.GetInt("user_id"), c.GetString("username"))
 
 	loggerutils.InfoLog(ctx, http.StatusOK, msg.SuccessLogout)
 	c.JSON(http.StatusOK, h.ErrorResponse{
@@ -319,7 +490,7 @@ func AuthStatus(c *gin.Context) {
 		return
 	}
 
-	if !auth.IsTokenActive(claims.Username) {
+	if !auth.IsTokenActive(claims.SessionID, tokenStr) {
 		loggerutils.InfoLog(ctx, http.StatusUnauthorized, msg.InvalidToken)
 		c.JSON(http.StatusUnauthorized, gin.H{"Message": msg.InvalidToken, "Status":401})
 		return
@@ -338,10 +509,30 @@ func AuthStatus(c *gin.Context) {
 			}})
 }
 
-func Hash(password string) []byte {
-	hash, err := bcr.GenerateFromPassword([]byte(password), bcr.DefaultCost)
-	if err != nil {
-		log.Error(err.Error())
-	}
-	return hash
+func setAuthCookies(c *gin.Context, accessToken, refreshToken string) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     "access_token",
+		Value:    accessToken,
+		Path:     "/",
+		Domain:   "",
+		MaxAge:   3600,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	})
+
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     "refresh_token",
+		Value:    refreshToken,
+		Path:     "/",
+		Domain:   "",
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteNoneMode,
+	})
+}
+
+func clearAuthCookies(c *gin.Context) {
+	c.SetCookie("access_token", "", -1, "/", "", true, true)
+	c.SetCookie("refresh_token", "", -1, "/", "", true, true)
 }
@@ -0,0 +1,206 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	auth "todo-web-api/authentication"
+	h "todo-web-api/helpers"
+	"todo-web-api/loggerutils"
+	msg "todo-web-api/messages"
+	models "todo-web-api/models"
+	s "todo-web-api/storage"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+// List Users endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Admin List Users
+//	@Schemes
+//	@Description	List user accounts, optionally filtered by username
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			username	query		string					false	"Username contains"
+//	@Success		200			{array}		h.AdminUserResult		"Successful"
+//	@Failure		403			{object}	h.ForbiddenResponse		"Forbidden"
+//	@Failure		500			{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/admin/Users [get]
+func AdminGetUsers(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	users, err := s.UserManager.GetUsers(c.Query("username"))
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.SomethingWentWrong})
+		return
+	}
+
+	results := make([]h.AdminUserResult, 0, len(users))
+	for _, user := range users {
+		results = append(results, adminUserResult(&user))
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// Disable User endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Admin Disable User
+//	@Schemes
+//	@Description	Disable a user account and sign it out everywhere
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int						true	"User ID"
+//	@Success		200	{object}	h.AdminUserResult		"Successful"
+//	@Failure		400	{object}	h.BadRequestResponse	"Bad Request"
+//	@Failure		403	{object}	h.ForbiddenResponse		"Forbidden"
+//	@Failure		404	{object}	h.NotFoundResponse		"Not Found"
+//	@Failure		500	{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/admin/DisableUser/{id} [put]
+func AdminDisableUser(c *gin.Context) {
+	setUserDisabled(c, true)
+}
+
+// Enable User endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Admin Enable User
+//	@Schemes
+//	@Description	Re-enable a disabled user account
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int						true	"User ID"
+//	@Success		200	{object}	h.AdminUserResult		"Successful"
+//	@Failure		400	{object}	h.BadRequestResponse	"Bad Request"
+//	@Failure		403	{object}	h.ForbiddenResponse		"Forbidden"
+//	@Failure		404	{object}	h.NotFoundResponse		"Not Found"
+//	@Failure		500	{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/admin/EnableUser/{id} [put]
+func AdminEnableUser(c *gin.Context) {
+	setUserDisabled(c, false)
+}
+
+// Force Logout endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Admin Force Logout
+//	@Schemes
+//	@Description	Revoke every session of a user
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int						true	"User ID"
+//	@Success		200	{object}	h.SuccessResponse		"Successful"
+//	@Failure		400	{object}	h.BadRequestResponse	"Bad Request"
+//	@Failure		403	{object}	h.ForbiddenResponse		"Forbidden"
+//	@Failure		404	{object}	h.NotFoundResponse		"Not Found"
+//	@Failure		500	{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/admin/ForceLogout/{id} [post]
+func AdminForceLogout(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, ok := findUserParam(c)
+	if !ok {
+		return
+	}
+
+	if err := auth.RevokeUserSessions(user.Id); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.SomethingWentWrong})
+		return
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, msg.SuccessForcedLogout)
+	c.JSON(http.StatusOK, h.SuccessResponse{
+		Status:  200,
+		Message: msg.SuccessForcedLogout})
+}
+
+func setUserDisabled(c *gin.Context, disabled bool) {
+	ctx := c.Request.Context()
+
+	user, ok := findUserParam(c)
+	if !ok {
+		return
+	}
+
+	if disabled && user.Id == c.GetInt("user_id") {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, errors.New(msg.CannotDisableSelf))
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: msg.CannotDisableSelf})
+		return
+	}
+
+	user.IsDisabled = disabled
+	if _, err := s.UserManager.UpdateUser(user); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.SomethingWentWrong})
+		return
+	}
+
+	message := msg.SuccessUserEnabled
+	if disabled {
+		message = msg.SuccessUserDisabled
+		// Disabling signs the user out right away instead of waiting for
+		// their access tokens to expire.
+		if err := auth.RevokeUserSessions(user.Id); err != nil {
+			loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+		}
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, message)
+	c.JSON(http.StatusOK, adminUserResult(user))
+}
+
+// findUserParam loads the user named by the id path parameter, writing the
+// error response itself when it can't.
+func findUserParam(c *gin.Context) (*models.User, bool) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: msg.InvalidResourceId})
+		return nil, false
+	}
+
+	user, err := s.UserManager.GetUser(id)
+	if err != nil && err.Error() == msg.UserNotFound {
+		loggerutils.ErrorLog(ctx, http.StatusNotFound, err)
+		c.JSON(http.StatusNotFound, h.NotFoundResponse{
+			Status:  404,
+			Message: msg.UserNotFound})
+		return nil, false
+	} else if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.SomethingWentWrong})
+		return nil, false
+	}
+	return user, true
+}
+
+func adminUserResult(user *models.User) h.AdminUserResult {
+	return h.AdminUserResult{
+		Id:         user.Id,
+		Username:   user.Username,
+		Role:       user.Role,
+		IsDisabled: user.IsDisabled,
+		CreatedAt:  user.CreatedAt,
+	}
+}
@@ -0,0 +1,14 @@
+package controllers
+
+import (
+	"net/http"
+	auth "todo-web-api/authentication"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+// JWKS publishes the public keys that verify tokens issued by this service.
+func JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, auth.PublicJWKS())
+}
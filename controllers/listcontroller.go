@@ -3,9 +3,11 @@ package controllers
 import (
 	"errors"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
+	authz "todo-web-api/authorization"
 	h "todo-web-api/helpers"
 	"todo-web-api/loggerutils"
 	"todo-web-api/messages"
@@ -13,7 +15,6 @@ import (
 	s "todo-web-api/storage"
 
 	gin "github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
 )
 
 // Create List endpoint for Todo
@@ -21,13 +22,15 @@ import (
 //
 //	@BasePath		/api/v1
 //	@Summary		Create List
-//	@Description	Sign-In with user credentials, for generated access token
+//	@Description	Create a named list; a user can have any number of them
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
-//	@Param			id	path		int						true	"id"
+//	@Param			id		path		int						true	"id"
+//	@Param			list	body		h.SaveList				true	"Title and description"
 //	@Success		200	{object}	h.SaveResponse			"Successful"
 //	@Failure		400	{object}	h.BadRequestResponse	"Bad Request"
+//	@Failure		403	{object}	h.ForbiddenResponse	"Forbidden"
 //	@Failure		500	{object}	h.ErrorResponse			"Internal Server Error"
 //	@Router			/CreateList/{id} [post]
 func CreateListForUser(c *gin.Context) {
@@ -44,44 +47,47 @@ func CreateListForUser(c *gin.Context) {
 		return
 	}
 
-	result, err := s.UserManager.GetUser(id)
-	if err != nil && err.Error() == "user not found, list cannot be created" {
+	var req h.SaveList
+	if err := c.ShouldBindJSON(&req); err != nil {
 		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
 
 		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
 			Status:  400,
 			Message: err.Error()})
 		return
-
-	} else if err != nil {
-		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+	}
+	if strings.TrimSpace(req.Title) == "" {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, errors.New(messages.ListTitleRequired))
 
 		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
-			Status:  500,
-			Message: err.Error()})
+			Status:  400,
+			Message: messages.ListTitleRequired})
 		return
 	}
 
-	existingList, err := s.ListManager.GetListForUser(id)
-	if existingList != nil {
-		errMsg := "there can be only 1 list per user"
-		log.FromContext(ctx).WithFields(logrus.Fields{
-			"status": http.StatusBadRequest,
-		}).Error(errors.New(errMsg), err)
+	result, err := s.UserManager.GetUser(id)
+	if err != nil && err.Error() == "user not found, list cannot be created" {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+
 		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
 			Status:  400,
-			Message: errMsg})
+			Message: err.Error()})
 		return
-	} else if err != nil && !strings.Contains(err.Error(), "not found") {
+
+	} else if err != nil {
 		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
 
 		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
-			Status:  400,
+			Status:  500,
 			Message: err.Error()})
 		return
 	}
 
-	list := &models.List{UserId: result.Id, CreatedAt: time.Now()}
+	list := &models.List{
+		Title:       strings.TrimSpace(req.Title),
+		Description: req.Description,
+		UserId:      result.Id,
+		CreatedAt:   time.Now()}
 	_, err = s.ListManager.CreateList(list)
 	if err != nil && err.Error() == "user not found, list cannot created" {
 		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
@@ -110,13 +116,14 @@ func CreateListForUser(c *gin.Context) {
 //
 //	@BasePath		/api/v1
 //	@Summary		Delete List
-//	@Description	Sign-In with user credentials, for generated access token
+//	@Description	Move a list and its tasks to the trash, from where /RestoreList brings them back until they are purged
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
 //	@Param			id	path		int						true	"id"
 //	@Success		200	{object}	h.DeleteResult			"Successful"
 //	@Failure		400	{object}	h.BadRequestResponse	"Bad Request"	//	Failed	due	to	bad	request	(e.g., validation error)
+//	@Failure		403	{object}	h.ForbiddenResponse	"Forbidden"
 //	@Failure		500	{object}	h.ErrorResponse			"Internal Server Error"
 //	@Router			/DeleteList/{id} [delete]
 func DeleteList(c *gin.Context) {
@@ -134,7 +141,7 @@ func DeleteList(c *gin.Context) {
 	}
 
 	result, err := s.ListManager.DeleteList(id)
-	if err != nil && err.Error() == "list record not found" {
+	if err != nil && err.Error() == messages.ListNotFoundInDb {
 		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
 
 		c.JSON(http.StatusBadRequest, h.ErrorResponse{
@@ -158,7 +165,7 @@ func DeleteList(c *gin.Context) {
 //	@BasePath	/api/v1
 //	@Summary	Get List
 //	@Schemes
-//	@Description	Sign-In with user credentials, for generated access token
+//	@Description	The user's oldest list with its tasks, from before users could have several; use /Lists for all of them
 //	@Accept			json
 //	@Produce		json
 //	@Param			userid	path		int						true	"User ID"
@@ -166,6 +173,7 @@ func DeleteList(c *gin.Context) {
 //	@Success		200		{object}	h.SuccessResponse		"Successful"
 //
 //	@Failure		400		{object}	h.BadRequestResponse	"Bad Request"
+//	@Failure		403		{object}	h.ForbiddenResponse	"Forbidden"
 //	@Failure		500		{object}	h.ErrorResponse			"Internal Server Error"
 //	@Router			/GetList/{userid} [get]
 func GetListByUserId(c *gin.Context) {
@@ -222,3 +230,233 @@ func GetListByUserId(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, &list)
 }
+
+// Get Lists godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Get Lists
+//	@Schemes
+//	@Description	The current user's lists and the lists shared with them, with their tasks and the user's role on each, in position order. Archived lists are left out unless archived=true, which lists only those.
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			archived	query		bool			false	"List the archived lists instead"
+//	@Success		200			{array}		models.List		"Successful"
+//	@Failure		500			{object}	h.ErrorResponse	"Internal Server Error"
+//	@Router			/Lists [get]
+func GetLists(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userId := c.GetInt("user_id")
+	archived := c.Query("archived") == "true"
+	lists, err := s.ListManager.GetListsForUser(userId, archived)
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.ListQueryInternalError})
+		return
+	}
+	memberships, err := s.ListMemberManager.GetListMembershipsForUser(userId, true)
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.ListMemberQueryInternalError})
+		return
+	}
+
+	roles := map[int]string{}
+	for _, membership := range memberships {
+		roles[membership.ListId] = membership.Role
+	}
+	for i := range lists {
+		if lists[i].UserId == userId {
+			lists[i].Role = authz.ListRoleOwner
+		} else {
+			lists[i].Role = roles[lists[i].Id]
+		}
+	}
+	if lists == nil {
+		lists = []models.List{}
+	}
+	c.JSON(http.StatusOK, lists)
+}
+
+// Update List endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Update List
+//	@Schemes
+//	@Description	Rename, recolor or move a list; fields left out of the body keep their value
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int						true	"id"
+//	@Param			Request	body		h.UpdateList			true	"Fields to change"
+//	@Success		200		{object}	h.SaveResponse			"Successful"
+//	@Failure		400		{object}	h.BadRequestResponse	"Bad Request"
+//	@Failure		403		{object}	h.ForbiddenResponse		"Forbidden"
+//	@Failure		404		{object}	h.ErrorResponse			"Not Found"
+//	@Failure		500		{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/UpdateList/{id} [put]
+func UpdateList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req h.UpdateList
+	if err := c.ShouldBindJSON(&req); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: err.Error()})
+		return
+	}
+	if req.Title != nil && strings.TrimSpace(*req.Title) == "" {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, errors.New(messages.ListTitleRequired))
+
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: messages.ListTitleRequired})
+		return
+	}
+	if req.Color != nil && *req.Color != "" && !listColor.MatchString(*req.Color) {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, errors.New(messages.InvalidListColor))
+
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: messages.InvalidListColor})
+		return
+	}
+
+	list, ok := findList(c)
+	if !ok {
+		return
+	}
+
+	if req.Title != nil {
+		list.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.Description != nil {
+		list.Description = *req.Description
+	}
+	if req.Color != nil {
+		list.Color = strings.ToLower(*req.Color)
+	}
+	if req.Icon != nil {
+		list.Icon = *req.Icon
+	}
+	if req.Position != nil {
+		list.Position = *req.Position
+	}
+
+	saveList(c, list, messages.SuccessListUpdate)
+}
+
+// Archive List endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Archive List
+//	@Schemes
+//	@Description	Archive a list, hiding it from /Lists without touching its tasks, or restore it with isArchived false
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int						true	"id"
+//	@Param			Request	body		h.SetArchived			true	"Archived or not"
+//	@Success		200		{object}	h.SaveResponse			"Successful"
+//	@Failure		400		{object}	h.BadRequestResponse	"Bad Request"
+//	@Failure		403		{object}	h.ForbiddenResponse		"Forbidden"
+//	@Failure		404		{object}	h.ErrorResponse			"Not Found"
+//	@Failure		500		{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/ListArchived/{id} [put]
+func SetListArchived(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req h.SetArchived
+	if err := c.ShouldBindJSON(&req); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: err.Error()})
+		return
+	}
+
+	list, ok := findList(c)
+	if !ok {
+		return
+	}
+
+	message := messages.SuccessListUnarchived
+	if *req.IsArchived {
+		message = messages.SuccessListArchived
+		// Archiving twice keeps the original date.
+		if list.ArchivedAt == nil {
+			now := time.Now()
+			list.ArchivedAt = &now
+		}
+	} else {
+		list.ArchivedAt = nil
+	}
+
+	saveList(c, list, message)
+}
+
+// listColor accepts #rgb and #rrggbb.
+var listColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// findList loads the list named by the id path parameter, responding
+// itself when it can't.
+func findList(c *gin.Context) (*models.List, bool) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: messages.InvalidResourceId})
+		return nil, false
+	}
+
+	list, err := s.ListManager.GetList(id)
+	if err != nil && authz.IsNotFound(err) {
+		loggerutils.ErrorLog(ctx, http.StatusNotFound, err)
+
+		c.JSON(http.StatusNotFound, h.ErrorResponse{
+			Status:  404,
+			Message: messages.ListNotFoundInDb})
+		return nil, false
+	} else if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.ListQueryInternalError})
+		return nil, false
+	}
+	return list, true
+}
+
+func saveList(c *gin.Context, list *models.List, message string) {
+	ctx := c.Request.Context()
+
+	if _, err := s.ListManager.UpdateList(list); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.SomethingWentWrong})
+		return
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, message)
+	c.JSON(http.StatusOK, h.SaveResponse{
+		Status:  200,
+		Message: message,
+		Id:      list.Id})
+}
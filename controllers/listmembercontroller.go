@@ -0,0 +1,517 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	authz "todo-web-api/authorization"
+	h "todo-web-api/helpers"
+	"todo-web-api/loggerutils"
+	"todo-web-api/messages"
+	models "todo-web-api/models"
+	s "todo-web-api/storage"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+// Invite List Member endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Invite List Member
+//	@Schemes
+//	@Description	Share a list with another user as an editor or viewer. They get access once they accept the invitation.
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int						true	"List id"
+//	@Param			Request	body		h.InviteListMember		true	"Who to invite, and as what"
+//	@Success		200		{object}	h.SaveResponse			"Successful"
+//	@Failure		400		{object}	h.BadRequestResponse	"Bad Request"
+//	@Failure		403		{object}	h.ForbiddenResponse		"Forbidden"
+//	@Failure		404		{object}	h.NotFoundResponse		"Not Found"
+//	@Failure		409		{object}	h.ErrorResponse			"Already a member"
+//	@Failure		500		{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/ListMembers/{id} [post]
+func InviteListMember(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req h.InviteListMember
+	if err := c.ShouldBindJSON(&req); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: err.Error()})
+		return
+	}
+	if !authz.IsInvitableListRole(req.Role) {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, errors.New(messages.InvalidListRole))
+
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: messages.InvalidListRole})
+		return
+	}
+
+	list, ok := findList(c)
+	if !ok {
+		return
+	}
+
+	user, err := s.UserManager.FindExistingAccount(strings.TrimSpace(req.Username), "")
+	if err != nil && err.Error() == messages.AccountNotFound {
+		loggerutils.ErrorLog(ctx, http.StatusNotFound, err)
+
+		c.JSON(http.StatusNotFound, h.NotFoundResponse{
+			Status:  404,
+			Message: messages.AccountNotFound})
+		return
+	} else if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.SomethingWentWrong})
+		return
+	}
+	if user.Id == list.UserId {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, errors.New(messages.CannotInviteListOwner))
+
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: messages.CannotInviteListOwner})
+		return
+	}
+
+	_, err = s.ListMemberManager.GetListMember(list.Id, user.Id)
+	if err == nil {
+		loggerutils.ErrorLog(ctx, http.StatusConflict, errors.New(messages.AlreadyListMember))
+
+		c.JSON(http.StatusConflict, h.ErrorResponse{
+			Status:  409,
+			Message: messages.AlreadyListMember})
+		return
+	} else if err.Error() != messages.ListMemberNotFoundInDb {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.SomethingWentWrong})
+		return
+	}
+
+	member := &models.ListMember{
+		ListId:    list.Id,
+		UserId:    user.Id,
+		Username:  user.Username,
+		Role:      req.Role,
+		InvitedBy: c.GetInt("user_id")}
+	if _, err := s.ListMemberManager.CreateListMember(member); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.SomethingWentWrong})
+		return
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, messages.SuccessInvitationSent)
+	c.JSON(http.StatusOK, h.SaveResponse{
+		Status:  200,
+		Message: messages.SuccessInvitationSent,
+		Id:      member.Id})
+}
+
+// Get List Members endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Get List Members
+//	@Schemes
+//	@Description	Everyone with access to a list, owner first, including invitations that are still pending
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"List id"
+//	@Success		200	{array}		h.ListMemberResult	"Successful"
+//	@Failure		403	{object}	h.ForbiddenResponse	"Forbidden"
+//	@Failure		404	{object}	h.ErrorResponse		"Not Found"
+//	@Failure		500	{object}	h.ErrorResponse		"Internal Server Error"
+//	@Router			/ListMembers/{id} [get]
+func GetListMembers(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	list, ok := findList(c)
+	if !ok {
+		return
+	}
+
+	owner, err := s.UserManager.GetUser(list.UserId)
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.SomethingWentWrong})
+		return
+	}
+	members, err := s.ListMemberManager.GetListMembers(list.Id)
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.ListMemberQueryInternalError})
+		return
+	}
+
+	results := []h.ListMemberResult{{
+		UserId:    owner.Id,
+		Username:  owner.Username,
+		Role:      authz.ListRoleOwner,
+		CreatedAt: list.CreatedAt}}
+	for _, member := range members {
+		results = append(results, h.ListMemberResult{
+			UserId:    member.UserId,
+			Username:  member.Username,
+			Role:      member.Role,
+			Pending:   member.AcceptedAt == nil,
+			CreatedAt: member.CreatedAt})
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// Update List Member endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Update List Member
+//	@Schemes
+//	@Description	Change a member's role on a list between editor and viewer
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int						true	"List id"
+//	@Param			userid	path		int						true	"Member's user id"
+//	@Param			Request	body		h.SetListMemberRole		true	"New role"
+//	@Success		200		{object}	h.SaveResponse			"Successful"
+//	@Failure		400		{object}	h.BadRequestResponse	"Bad Request"
+//	@Failure		403		{object}	h.ForbiddenResponse		"Forbidden"
+//	@Failure		404		{object}	h.ErrorResponse			"Not Found"
+//	@Failure		500		{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/ListMembers/{id}/{userid} [put]
+func UpdateListMember(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req h.SetListMemberRole
+	if err := c.ShouldBindJSON(&req); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: err.Error()})
+		return
+	}
+	if !authz.IsInvitableListRole(req.Role) {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, errors.New(messages.InvalidListRole))
+
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: messages.InvalidListRole})
+		return
+	}
+
+	list, ok := findList(c)
+	if !ok {
+		return
+	}
+	member, ok := findListMember(c, list)
+	if !ok {
+		return
+	}
+
+	member.Role = req.Role
+	if _, err := s.ListMemberManager.UpdateListMember(member); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.SomethingWentWrong})
+		return
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, messages.SuccessListMemberUpdate)
+	c.JSON(http.StatusOK, h.SaveResponse{
+		Status:  200,
+		Message: messages.SuccessListMemberUpdate,
+		Id:      member.Id})
+}
+
+// Remove List Member endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Remove List Member
+//	@Schemes
+//	@Description	Take a member off a list, or withdraw their invitation. Members can remove themselves to leave a list.
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int						true	"List id"
+//	@Param			userid	path		int						true	"Member's user id"
+//	@Success		200		{object}	h.DeleteResult			"Successful"
+//	@Failure		400		{object}	h.BadRequestResponse	"Bad Request"
+//	@Failure		403		{object}	h.ForbiddenResponse		"Forbidden"
+//	@Failure		404		{object}	h.ErrorResponse			"Not Found"
+//	@Failure		500		{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/ListMembers/{id}/{userid} [delete]
+func RemoveListMember(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	list, ok := findList(c)
+	if !ok {
+		return
+	}
+	member, ok := findListMember(c, list)
+	if !ok {
+		return
+	}
+	if c.GetString("list_role") != authz.ListRoleOwner && member.UserId != c.GetInt("user_id") {
+		loggerutils.ErrorLog(ctx, http.StatusForbidden, errors.New(messages.Forbidden))
+
+		c.JSON(http.StatusForbidden, h.ForbiddenResponse{
+			Status:  403,
+			Message: messages.Forbidden})
+		return
+	}
+
+	if _, err := s.ListMemberManager.DeleteListMember(member.Id); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.SomethingWentWrong})
+		return
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, messages.SuccessListMemberRemoved)
+	c.JSON(http.StatusOK, h.DeleteResult{
+		Status:  200,
+		Message: messages.SuccessListMemberRemoved,
+		Success: true})
+}
+
+// Get Invitations endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Get Invitations
+//	@Schemes
+//	@Description	Lists the current user has been invited to and not yet accepted or declined, newest first
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{array}		h.InvitationResult	"Successful"
+//	@Failure		500	{object}	h.ErrorResponse		"Internal Server Error"
+//	@Router			/Invitations [get]
+func GetInvitations(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	invitations, err := s.ListMemberManager.GetListMembershipsForUser(c.GetInt("user_id"), false)
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.ListMemberQueryInternalError})
+		return
+	}
+
+	results := []h.InvitationResult{}
+	for _, invitation := range invitations {
+		list, err := s.ListManager.GetList(invitation.ListId)
+		if err != nil && authz.IsNotFound(err) {
+			// The list is in the trash; the invitation stands if it comes back.
+			continue
+		} else if err != nil {
+			loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+			c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+				Status:  500,
+				Message: messages.ListQueryInternalError})
+			return
+		}
+		inviter, err := s.UserManager.GetUser(invitation.InvitedBy)
+		if err != nil {
+			loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+			c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+				Status:  500,
+				Message: messages.SomethingWentWrong})
+			return
+		}
+		results = append(results, h.InvitationResult{
+			Id:        invitation.Id,
+			ListId:    list.Id,
+			ListTitle: list.Title,
+			Role:      invitation.Role,
+			InvitedBy: inviter.Username,
+			CreatedAt: invitation.CreatedAt})
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// Accept Invitation endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Accept Invitation
+//	@Schemes
+//	@Description	Join a list the current user was invited to, with the role of the invitation
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"Invitation id"
+//	@Success		200	{object}	h.SaveResponse		"Successful"
+//	@Failure		404	{object}	h.NotFoundResponse	"Not Found"
+//	@Failure		500	{object}	h.ErrorResponse		"Internal Server Error"
+//	@Router			/AcceptInvitation/{id} [post]
+func AcceptInvitation(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	invitation, ok := findInvitation(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	invitation.AcceptedAt = &now
+	if _, err := s.ListMemberManager.UpdateListMember(invitation); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.SomethingWentWrong})
+		return
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, messages.SuccessInvitationAccepted)
+	c.JSON(http.StatusOK, h.SaveResponse{
+		Status:  200,
+		Message: messages.SuccessInvitationAccepted,
+		Id:      invitation.ListId})
+}
+
+// Decline Invitation endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Decline Invitation
+//	@Schemes
+//	@Description	Turn down an invitation to a list; the list's owner can invite again
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int					true	"Invitation id"
+//	@Success		200	{object}	h.DeleteResult		"Successful"
+//	@Failure		404	{object}	h.NotFoundResponse	"Not Found"
+//	@Failure		500	{object}	h.ErrorResponse		"Internal Server Error"
+//	@Router			/DeclineInvitation/{id} [post]
+func DeclineInvitation(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	invitation, ok := findInvitation(c)
+	if !ok {
+		return
+	}
+
+	if _, err := s.ListMemberManager.DeleteListMember(invitation.Id); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.SomethingWentWrong})
+		return
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, messages.SuccessInvitationDeclined)
+	c.JSON(http.StatusOK, h.DeleteResult{
+		Status:  200,
+		Message: messages.SuccessInvitationDeclined,
+		Success: true})
+}
+
+// findListMember loads the membership on list of the user named by the
+// userid path parameter, responding itself when it can't. The owner has
+// no membership to change.
+func findListMember(c *gin.Context, list *models.List) (*models.ListMember, bool) {
+	ctx := c.Request.Context()
+
+	userId, err := strconv.Atoi(c.Param("userid"))
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: messages.InvalidResourceId})
+		return nil, false
+	}
+	if userId == list.UserId {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, errors.New(messages.CannotChangeListOwner))
+
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: messages.CannotChangeListOwner})
+		return nil, false
+	}
+
+	member, err := s.ListMemberManager.GetListMember(list.Id, userId)
+	if err != nil && err.Error() == messages.ListMemberNotFoundInDb {
+		loggerutils.ErrorLog(ctx, http.StatusNotFound, err)
+
+		c.JSON(http.StatusNotFound, h.ErrorResponse{
+			Status:  404,
+			Message: messages.ListMemberNotFound})
+		return nil, false
+	} else if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.ListMemberQueryInternalError})
+		return nil, false
+	}
+	return member, true
+}
+
+// findInvitation loads the current user's pending invitation named by the
+// id path parameter. Someone else's invitation is reported as missing, so
+// ids can't be probed.
+func findInvitation(c *gin.Context) (*models.ListMember, bool) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: messages.InvalidResourceId})
+		return nil, false
+	}
+
+	invitation, err := s.ListMemberManager.GetListMemberById(id)
+	if err != nil && err.Error() != messages.ListMemberNotFoundInDb {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.ListMemberQueryInternalError})
+		return nil, false
+	}
+	if err != nil || invitation.UserId != c.GetInt("user_id") || invitation.AcceptedAt != nil {
+		loggerutils.ErrorLog(ctx, http.StatusNotFound, errors.New(messages.InvitationNotFound))
+
+		c.JSON(http.StatusNotFound, h.NotFoundResponse{
+			Status:  404,
+			Message: messages.InvitationNotFound})
+		return nil, false
+	}
+	return invitation, true
+}
@@ -0,0 +1,276 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	auth "todo-web-api/authentication"
+	h "todo-web-api/helpers"
+	"todo-web-api/loggerutils"
+	msg "todo-web-api/messages"
+	models "todo-web-api/models"
+	s "todo-web-api/storage"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+// Login Mfa endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Login Mfa
+//	@Schemes
+//	@Description	Second login step: exchange the mfaToken from /Login and a TOTP or recovery code for a session
+//	@Accept			json
+//	@Produce		json
+//	@Param			Request	body		h.MfaLogin				true	"Mfa Login Request"
+//	@Success		200		{object}	h.AuthStatusResponse	"Successful"
+//	@Failure		400		{object}	h.BadRequestResponse	"Bad Request"
+//	@Failure		401		{object}	h.UnauthorizedResponse	"Invalid Token Or Code"
+//	@Failure		403		{object}	h.ForbiddenResponse		"Account Disabled"
+//	@Failure		423		{object}	h.ErrorResponse			"Account Locked"
+//	@Failure		429		{object}	h.ErrorResponse			"Too Many Attempts"
+//	@Failure		500		{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/LoginMfa [post]
+func LoginMfa(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req h.MfaLogin
+	if err := c.ShouldBindJSON(&req); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: err.Error()})
+		return
+	}
+
+	claims, err := auth.ParseMfaToken(req.MfaToken)
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusUnauthorized, err)
+		c.JSON(http.StatusUnauthorized, h.UnauthorizedResponse{
+			Status:  401,
+			Message: msg.InvalidMfaToken})
+		return
+	}
+
+	// Wrong codes count towards the same lockout as wrong passwords.
+	if loginThrottled(c, claims.Username) {
+		return
+	}
+
+	user, err := s.UserManager.GetUser(claims.UserID)
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusUnauthorized, err)
+		c.JSON(http.StatusUnauthorized, h.UnauthorizedResponse{
+			Status:  401,
+			Message: msg.InvalidMfaToken})
+		return
+	}
+	if user.IsDisabled {
+		loggerutils.ErrorLog(ctx, http.StatusForbidden, errors.New(msg.AccountDisabled))
+		c.JSON(http.StatusForbidden, h.ForbiddenResponse{
+			Status:  403,
+			Message: msg.AccountDisabled})
+		return
+	}
+	if !user.TOTPEnabled {
+		loggerutils.ErrorLog(ctx, http.StatusUnauthorized, errors.New(msg.MfaNotEnrolled))
+		c.JSON(http.StatusUnauthorized, h.UnauthorizedResponse{
+			Status:  401,
+			Message: msg.InvalidMfaToken})
+		return
+	}
+
+	if err := auth.VerifySecondFactor(user, req.Code, req.RecoveryCode); err != nil {
+		if err.Error() == msg.InvalidMfaCode {
+			recordAudit(c, auth.AuditLogin, auth.AuditFailure, "invalid_mfa_code", user.Id, user.Username)
+		}
+		respondMfaError(c, user, err, http.StatusUnauthorized)
+		return
+	}
+
+	startLoginSession(c, user)
+}
+
+// Enroll Mfa endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Enroll Mfa
+//	@Schemes
+//	@Description	Start TOTP enrollment; returns the secret and an otpauth:// URI for the authenticator app
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	h.MfaEnrollResponse		"Successful"
+//	@Failure		400	{object}	h.BadRequestResponse	"Already Enabled"
+//	@Failure		500	{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/EnrollMfa [post]
+func EnrollMfa(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, ok := findCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	secret, uri, err := auth.EnrollTOTP(user)
+	if err != nil && err.Error() == msg.MfaAlreadyEnabled {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: msg.MfaAlreadyEnabled})
+		return
+	} else if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.SomethingWentWrong})
+		return
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, msg.SuccessMfaEnrollStarted)
+	c.JSON(http.StatusOK, h.MfaEnrollResponse{
+		Status:     200,
+		Message:    msg.SuccessMfaEnrollStarted,
+		Secret:     secret,
+		OtpauthUri: uri})
+}
+
+// Confirm Mfa endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Confirm Mfa
+//	@Schemes
+//	@Description	Finish TOTP enrollment with a code from the authenticator app; returns single-use recovery codes, shown only this once
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			Request	body		h.MfaCode					true	"Confirmation Code"
+//	@Success		200		{object}	h.RecoveryCodesResponse		"Successful"
+//	@Failure		400		{object}	h.BadRequestResponse		"Bad Request"
+//	@Failure		500		{object}	h.ErrorResponse				"Internal Server Error"
+//	@Router			/ConfirmMfa [post]
+func ConfirmMfa(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req h.MfaCode
+	if err := c.ShouldBindJSON(&req); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: err.Error()})
+		return
+	}
+
+	user, ok := findCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	codes, err := auth.ConfirmTOTP(user, req.Code)
+	if err != nil {
+		respondMfaError(c, nil, err, http.StatusBadRequest)
+		return
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, msg.SuccessMfaEnabled)
+	c.JSON(http.StatusOK, h.RecoveryCodesResponse{
+		Status:        200,
+		Message:       msg.SuccessMfaEnabled,
+		RecoveryCodes: codes})
+}
+
+// Disable Mfa endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Disable Mfa
+//	@Schemes
+//	@Description	Turn two-factor off; requires a current TOTP code or a recovery code
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			Request	body		h.MfaDisable			true	"Code or Recovery Code"
+//	@Success		200		{object}	h.SuccessResponse		"Successful"
+//	@Failure		400		{object}	h.BadRequestResponse	"Bad Request"
+//	@Failure		423		{object}	h.ErrorResponse			"Account Locked"
+//	@Failure		429		{object}	h.ErrorResponse			"Too Many Attempts"
+//	@Failure		500		{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/DisableMfa [post]
+func DisableMfa(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req h.MfaDisable
+	if err := c.ShouldBindJSON(&req); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: err.Error()})
+		return
+	}
+
+	user, ok := findCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	// A stolen session shouldn't be enough to guess its way past the
+	// second factor either.
+	if loginThrottled(c, user.Username) {
+		return
+	}
+
+	if err := auth.DisableTOTP(user, req.Code, req.RecoveryCode); err != nil {
+		respondMfaError(c, user, err, http.StatusBadRequest)
+		return
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, msg.SuccessMfaDisabled)
+	c.JSON(http.StatusOK, h.SuccessResponse{
+		Status:  200,
+		Message: msg.SuccessMfaDisabled})
+}
+
+// respondMfaError maps errors from the auth mfa functions to responses. A
+// wrong code is answered with invalidCodeStatus and, when user is given,
+// counted as a failed login.
+func respondMfaError(c *gin.Context, user *models.User, err error, invalidCodeStatus int) {
+	ctx := c.Request.Context()
+
+	switch err.Error() {
+	case msg.InvalidMfaCode:
+		if user != nil {
+			auth.RecordLoginFailure(user.Username, c.ClientIP())
+		}
+		loggerutils.ErrorLog(ctx, invalidCodeStatus, err)
+		c.JSON(invalidCodeStatus, h.ErrorResponse{
+			Status:  invalidCodeStatus,
+			Message: msg.InvalidMfaCode})
+	case msg.MfaCodeRequired, msg.MfaAlreadyEnabled, msg.MfaNotEnrolled:
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: err.Error()})
+	default:
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.SomethingWentWrong})
+	}
+}
+
+// findCurrentUser loads the authenticated user, writing the error response
+// itself when it can't.
+func findCurrentUser(c *gin.Context) (*models.User, bool) {
+	ctx := c.Request.Context()
+
+	user, err := s.UserManager.GetUser(c.GetInt("user_id"))
+	if err != nil && err.Error() == msg.UserNotFound {
+		loggerutils.ErrorLog(ctx, http.StatusNotFound, err)
+		c.JSON(http.StatusNotFound, h.NotFoundResponse{
+			Status:  404,
+			Message: msg.UserNotFound})
+		return nil, false
+	} else if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.SomethingWentWrong})
+		return nil, false
+	}
+	return user, true
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	auth "todo-web-api/authentication"
+	h "todo-web-api/helpers"
+	"todo-web-api/loggerutils"
+	msg "todo-web-api/messages"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+// Introspect tells another backend service whether a token issued here is
+// still good, and whose it is (RFC 7662). The token comes as the form field
+// token; token_type_hint is accepted but not needed.
+func Introspect(c *gin.Context) {
+	ctx := c.Request.Context()
+	c.Header("Cache-Control", "no-store")
+
+	token, ok := oauthToken(c)
+	if !ok {
+		return
+	}
+
+	introspection, err := auth.IntrospectToken(token)
+	if err != nil {
+		respondOAuthServerError(c, err)
+		return
+	}
+	if introspection == nil {
+		loggerutils.InfoLog(ctx, http.StatusOK, "inactive token introspected")
+		c.JSON(http.StatusOK, h.IntrospectionResponse{Active: false})
+		return
+	}
+
+	resp := h.IntrospectionResponse{
+		Active:    true,
+		Scope:     strings.Join(introspection.Scopes, " "),
+		Username:  introspection.Username,
+		TokenType: introspection.TokenType,
+		Exp:       introspection.ExpiresAt.Unix(),
+		Sub:       introspection.Subject(),
+		Iss:       introspection.Issuer,
+		Jti:       introspection.TokenId,
+		Sid:       introspection.SessionId,
+	}
+	if introspection.IssuedAt != nil {
+		resp.Iat = introspection.IssuedAt.Unix()
+	}
+	loggerutils.InfoLog(ctx, http.StatusOK, "active token introspected")
+	c.JSON(http.StatusOK, resp)
+}
+
+// Revoke invalidates a token on behalf of another backend service
+// (RFC 7009). Unknown and already revoked tokens get the same empty 200.
+func Revoke(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	token, ok := oauthToken(c)
+	if !ok {
+		return
+	}
+
+	if err := auth.RevokeToken(token); err != nil {
+		respondOAuthServerError(c, err)
+		return
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, msg.SuccessTokenRevoked)
+	c.Status(http.StatusOK)
+}
+
+func oauthToken(c *gin.Context) (string, bool) {
+	token := c.PostForm("token")
+	if token == "" {
+		loggerutils.ErrorLog(c.Request.Context(), http.StatusBadRequest, errors.New(msg.TokenRequired))
+		c.JSON(http.StatusBadRequest, h.OAuthErrorResponse{
+			Error:            "invalid_request",
+			ErrorDescription: msg.TokenRequired})
+		return "", false
+	}
+	return token, true
+}
+
+func respondOAuthServerError(c *gin.Context, err error) {
+	loggerutils.ErrorLog(c.Request.Context(), http.StatusInternalServerError, err)
+	c.JSON(http.StatusInternalServerError, h.OAuthErrorResponse{
+		Error:            "server_error",
+		ErrorDescription: msg.SomethingWentWrong})
+}
@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	auth "todo-web-api/authentication"
+	h "todo-web-api/helpers"
+	"todo-web-api/loggerutils"
+	msg "todo-web-api/messages"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+const oidcStateCookie = "oidc_state"
+
+// Oidc Login endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Oidc Login
+//	@Schemes
+//	@Description	Start signing in with an OpenID Connect provider: redirects the browser to the provider (authorization code flow with PKCE)
+//	@Param			provider	path	string	true	"Provider name from the config"
+//	@Success		302
+//	@Failure		404	{object}	h.NotFoundResponse	"Unknown Provider"
+//	@Failure		502	{object}	h.ErrorResponse		"Provider Unavailable"
+//	@Router			/OidcLogin/{provider} [get]
+func OidcLogin(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	authURL, stateToken, err := auth.BeginOIDCLogin(ctx, c.Param("provider"))
+	if err != nil && err.Error() == msg.UnknownOIDCProvider {
+		loggerutils.ErrorLog(ctx, http.StatusNotFound, err)
+		c.JSON(http.StatusNotFound, h.NotFoundResponse{
+			Status:  404,
+			Message: msg.UnknownOIDCProvider})
+		return
+	} else if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusBadGateway, err)
+		c.JSON(http.StatusBadGateway, h.ErrorResponse{
+			Status:  502,
+			Message: msg.OIDCLoginFailed})
+		return
+	}
+
+	// Lax, unlike the auth cookies: the provider sends the browser back
+	// with a top-level GET, and the cookie isn't needed anywhere else.
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    stateToken,
+		Path:     "/",
+		MaxAge:   int(auth.OIDCStateExpiry.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Oidc Callback endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Oidc Callback
+//	@Schemes
+//	@Description	Where the provider sends the browser back. Opens a session like /Login and redirects to the configured post-login page; accounts with two-factor on get an mfaToken in the redirect's fragment instead, for /LoginMfa.
+//	@Produce		json
+//	@Param			provider	path		string	true	"Provider name from the config"
+//	@Param			code		query		string	true	"Authorization code"
+//	@Param			state		query		string	true	"State"
+//	@Success		200			{object}	h.AuthStatusResponse	"Successful, when no post-login page is configured"
+//	@Success		302
+//	@Failure		400			{object}	h.BadRequestResponse	"Invalid State"
+//	@Failure		401			{object}	h.UnauthorizedResponse	"Sign-In Failed"
+//	@Failure		403			{object}	h.ForbiddenResponse		"No Linked Account Or Disabled"
+//	@Failure		404			{object}	h.NotFoundResponse		"Unknown Provider"
+//	@Failure		500			{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/OidcCallback/{provider} [get]
+func OidcCallback(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	stateToken, _ := c.Cookie(oidcStateCookie)
+	// The state is single-use whatever happens next.
+	http.SetCookie(c.Writer, &http.Cookie{Name: oidcStateCookie, Value: "", Path: "/", MaxAge: -1,
+		HttpOnly: true, Secure: true, SameSite: http.SameSiteLaxMode})
+
+	if providerError := c.Query("error"); providerError != "" {
+		loggerutils.ErrorLog(ctx, http.StatusUnauthorized, errors.New(providerError))
+		c.JSON(http.StatusUnauthorized, h.UnauthorizedResponse{
+			Status:  401,
+			Message: msg.OIDCLoginFailed})
+		return
+	}
+
+	user, err := auth.CompleteOIDCLogin(ctx, c.Param("provider"), stateToken, c.Query("state"), c.Query("code"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		message := msg.SomethingWentWrong
+		switch err.Error() {
+		case msg.UnknownOIDCProvider:
+			status, message = http.StatusNotFound, err.Error()
+		case msg.InvalidOIDCState:
+			status, message = http.StatusBadRequest, err.Error()
+		case msg.OIDCLoginFailed:
+			status, message = http.StatusUnauthorized, err.Error()
+		case msg.OIDCSignupDisabled:
+			status, message = http.StatusForbidden, err.Error()
+		}
+		loggerutils.ErrorLog(ctx, status, err)
+		c.JSON(status, h.ErrorResponse{
+			Status:  status,
+			Message: message})
+		return
+	}
+
+	if user.IsDisabled {
+		loggerutils.ErrorLog(ctx, http.StatusForbidden, errors.New(msg.AccountDisabled))
+		c.JSON(http.StatusForbidden, h.ForbiddenResponse{
+			Status:  403,
+			Message: msg.AccountDisabled})
+		return
+	}
+
+	postLoginURL := auth.OIDCPostLoginURL()
+
+	// The provider vouches for the first factor only; a second one set up
+	// here is still asked for.
+	if user.TOTPEnabled {
+		mfaToken, err := auth.GenerateMfaToken(user)
+		if err != nil {
+			loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+			c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+				Status:  500,
+				Message: msg.SomethingWentWrong})
+			return
+		}
+		if postLoginURL == "" {
+			c.JSON(http.StatusOK, h.MfaChallengeResponse{
+				Status:      200,
+				Message:     msg.MfaRequired,
+				MfaRequired: true,
+				MfaToken:    mfaToken})
+			return
+		}
+		// In the fragment, so it never reaches a server log.
+		c.Redirect(http.StatusFound, postLoginURL+"#mfaToken="+url.QueryEscape(mfaToken))
+		return
+	}
+
+	if postLoginURL == "" {
+		startLoginSession(c, user)
+		return
+	}
+	if !openSession(c, user) {
+		return
+	}
+	loggerutils.InfoLog(ctx, http.StatusFound, msg.SuccessLogin)
+	c.Redirect(http.StatusFound, postLoginURL)
+}
@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	auth "todo-web-api/authentication"
+	h "todo-web-api/helpers"
+	"todo-web-api/loggerutils"
+	msg "todo-web-api/messages"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+// Change Password endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Change Password
+//	@Schemes
+//	@Description	Change the password, given the current one. Every other session is signed out; this one stays.
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			Request	body		h.ChangePassword		true	"Change Password Request"
+//	@Success		200		{object}	h.SuccessResponse		"Successful"
+//	@Failure		400		{object}	h.PasswordPolicyResponse	"Bad Request Or Password Policy"
+//	@Failure		423		{object}	h.ErrorResponse			"Account Locked"
+//	@Failure		429		{object}	h.ErrorResponse			"Too Many Attempts"
+//	@Failure		500		{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/ChangePassword [put]
+func ChangePassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req h.ChangePassword
+	if err := c.ShouldBindJSON(&req); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: err.Error()})
+		return
+	}
+
+	user, ok := findCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	// Guessing the current password here is throttled like a login.
+	if loginThrottled(c, user.Username) {
+		return
+	}
+
+	err := auth.ChangePassword(user, req.CurrentPassword, req.NewPassword, c.GetString("session_id"))
+	if err != nil && err.Error() == msg.InvalidCurrentPassword {
+		auth.RecordLoginFailure(user.Username, c.ClientIP())
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: msg.InvalidCurrentPassword})
+		return
+	} else if err != nil && err.Error() == msg.PasswordPolicyViolated {
+		respondPasswordPolicyError(c, err)
+		return
+	} else if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.SomethingWentWrong})
+		return
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, msg.SuccessPasswordChange)
+	c.JSON(http.StatusOK, h.SuccessResponse{
+		Status:  200,
+		Message: msg.SuccessPasswordChange})
+}
+
+// Forgot Password endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Forgot Password
+//	@Schemes
+//	@Description	Mail a single-use password reset link. The response is the same whether or not the account exists.
+//	@Accept			json
+//	@Produce		json
+//	@Param			Request	body		h.ForgotPassword		true	"Forgot Password Request"
+//	@Success		200		{object}	h.SuccessResponse		"Successful"
+//	@Failure		400		{object}	h.BadRequestResponse	"Bad Request"
+//	@Failure		500		{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/ForgotPassword [post]
+func ForgotPassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req h.ForgotPassword
+	if err := c.ShouldBindJSON(&req); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: err.Error()})
+		return
+	}
+
+	if err := auth.RequestPasswordReset(req.Username); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.SomethingWentWrong})
+		return
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, msg.SuccessPasswordResetRequested)
+	c.JSON(http.StatusOK, h.SuccessResponse{
+		Status:  200,
+		Message: msg.SuccessPasswordResetRequested})
+}
+
+// Reset Password endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Reset Password
+//	@Schemes
+//	@Description	Set a new password with the token from a reset link. Tokens work once, and signing in afresh is required on every device.
+//	@Accept			json
+//	@Produce		json
+//	@Param			Request	body		h.ResetPassword			true	"Reset Password Request"
+//	@Success		200		{object}	h.SuccessResponse		"Successful"
+//	@Failure		400		{object}	h.PasswordPolicyResponse	"Invalid Or Expired Token Or Password Policy"
+//	@Failure		500		{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/ResetPassword [post]
+func ResetPassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req h.ResetPassword
+	if err := c.ShouldBindJSON(&req); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: err.Error()})
+		return
+	}
+
+	err := auth.ResetPassword(req.Token, req.NewPassword)
+	if err != nil && err.Error() == msg.InvalidResetToken {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: msg.InvalidResetToken})
+		return
+	} else if err != nil && err.Error() == msg.PasswordPolicyViolated {
+		respondPasswordPolicyError(c, err)
+		return
+	} else if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.SomethingWentWrong})
+		return
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, msg.SuccessPasswordReset)
+	c.JSON(http.StatusOK, h.SuccessResponse{
+		Status:  200,
+		Message: msg.SuccessPasswordReset})
+}
+
+// respondPasswordPolicyError answers a refused password with a 400 listing
+// every rule it broke.
+func respondPasswordPolicyError(c *gin.Context, err error) {
+	ctx := c.Request.Context()
+
+	var policyErr *auth.PasswordPolicyError
+	violations := []h.PasswordViolation{}
+	if errors.As(err, &policyErr) {
+		for _, violation := range policyErr.Violations {
+			violations = append(violations, h.PasswordViolation{Rule: violation.Rule, Message: violation.Message})
+		}
+	}
+
+	loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+	c.JSON(http.StatusBadRequest, h.PasswordPolicyResponse{
+		Status:     400,
+		Message:    msg.PasswordPolicyViolated,
+		Violations: violations})
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	auth "todo-web-api/authentication"
+	"todo-web-api/contextkeys"
+	h "todo-web-api/helpers"
+	"todo-web-api/loggerutils"
+	msg "todo-web-api/messages"
+	models "todo-web-api/models"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+// Security Events endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Get Security Events
+//	@Schemes
+//	@Description	The current user's recent sign-ins, failed sign-in attempts, token refreshes and sign-outs, newest first
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			limit	query		int						false	"How many events, up to 100 (default 50)"
+//	@Success		200		{array}		h.SecurityEventResult	"Successful"
+//	@Failure		400		{object}	h.BadRequestResponse	"Bad Request"
+//	@Failure		500		{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/SecurityEvents [get]
+func GetSecurityEvents(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, fmt.Errorf("invalid limit %q", c.Query("limit")))
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: msg.InvalidLimit})
+		return
+	}
+
+	events, err := auth.RecentAuditEvents(c.GetInt("user_id"), limit)
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.SomethingWentWrong})
+		return
+	}
+
+	results := make([]h.SecurityEventResult, 0, len(events))
+	for _, event := range events {
+		results = append(results, h.SecurityEventResult{
+			Id:        event.Id,
+			EventType: event.EventType,
+			Outcome:   event.Outcome,
+			Detail:    event.Detail,
+			IPAddress: event.IPAddress,
+			UserAgent: event.UserAgent,
+			RequestId: event.RequestId,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// recordAudit appends an event about this request to the audit log. userId
+// is 0 when the request can't be tied to an account.
+func recordAudit(c *gin.Context, eventType, outcome, detail string, userId int, username string) {
+	requestId := ""
+	if id := c.Request.Context().Value(contextkeys.ContextKeyRequestID); id != nil {
+		requestId = fmt.Sprint(id)
+	}
+
+	auth.RecordAuditEvent(&models.AuditEvent{
+		UserId:    userId,
+		Username:  username,
+		EventType: eventType,
+		Outcome:   outcome,
+		Detail:    detail,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		RequestId: requestId,
+	})
+}
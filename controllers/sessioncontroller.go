@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	auth "todo-web-api/authentication"
+	h "todo-web-api/helpers"
+	"todo-web-api/loggerutils"
+	msg "todo-web-api/messages"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+// List Sessions endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Get Sessions
+//	@Schemes
+//	@Description	List the signed-in devices of the current user
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{array}		h.SessionResult			"Successful"
+//	@Failure		401	{object}	h.UnauthorizedResponse	"Unauthorized"
+//	@Failure		500	{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/Sessions [get]
+func GetSessions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	sessions, err := auth.GetSessions(c.GetInt("user_id"))
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.SomethingWentWrong})
+		return
+	}
+
+	currentSession := c.GetString("session_id")
+	results := make([]h.SessionResult, 0, len(sessions))
+	for _, session := range sessions {
+		results = append(results, h.SessionResult{
+			Id:         session.SessionId,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.SessionId == currentSession,
+		})
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// Revoke Session endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Revoke Session
+//	@Schemes
+//	@Description	Sign out one of the current user's devices
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string					true	"Session ID"
+//	@Success		200	{object}	h.DeleteResult			"Successful"
+//	@Failure		404	{object}	h.NotFoundResponse		"Not Found"
+//	@Failure		500	{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/Sessions/{id} [delete]
+func RevokeSession(c *gin.Context) {
+	ctx := c.Request.Context()
+	sessionID := c.Param("id")
+
+	session, err := auth.GetUserSession(c.GetInt("user_id"), sessionID)
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.SomethingWentWrong})
+		return
+	}
+	if session == nil {
+		loggerutils.ErrorLog(ctx, http.StatusNotFound, errors.New(msg.SessionNotFound))
+		c.JSON(http.StatusNotFound, h.NotFoundResponse{
+			Status:  404,
+			Message: msg.SessionNotFound})
+		return
+	}
+
+	if err := auth.RevokeSession(session.SessionId); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.SomethingWentWrong})
+		return
+	}
+
+	// Revoking the session making the request is the same as logging out.
+	if session.SessionId == c.GetString("session_id") {
+		clearAuthCookies(c)
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, msg.SuccessSessionRevoke)
+	c.JSON(http.StatusOK, h.DeleteResult{
+		Status:  200,
+		Message: msg.SuccessSessionRevoke,
+		Success: true})
+}
+
+// Csrf Token endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Get Csrf Token
+//	@Schemes
+//	@Description	Token for the X-CSRF-Token header, required on POST, PUT and DELETE requests authenticated by the access_token cookie. It lasts 12 hours and only for the current session.
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	h.CsrfTokenResponse		"Successful"
+//	@Failure		400	{object}	h.BadRequestResponse	"No Session"
+//	@Failure		401	{object}	h.UnauthorizedResponse	"Unauthorized"
+//	@Failure		500	{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/CsrfToken [get]
+func GetCsrfToken(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// Personal access tokens have no session, and no need for one.
+	sessionId := c.GetString("session_id")
+	if sessionId == "" {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, errors.New(msg.CSRFNeedsSession))
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: msg.CSRFNeedsSession})
+		return
+	}
+
+	token, err := auth.GenerateCSRFToken(sessionId)
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.SomethingWentWrong})
+		return
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, msg.SuccessCsrfToken)
+	c.JSON(http.StatusOK, h.CsrfTokenResponse{
+		Status:    200,
+		Message:   msg.SuccessCsrfToken,
+		CsrfToken: token})
+}
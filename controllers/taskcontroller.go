@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -27,6 +28,7 @@ import (
 //	@Param			Request	body		h.SaveTask				true	"Add Task"
 //	@Success		200		{object}	h.SaveResponse			"Successful"
 //	@Failure		400		{object}	h.BadRequestResponse	"Bad Request"	//	Failed	due	to	bad	request	(e.g., validation error)
+//	@Failure		403		{object}	h.ForbiddenResponse	"Forbidden"
 //	@Failure		500		{object}	h.ErrorResponse			"Internal Server Error"
 //	@Router			/CreateTask/{listid} [post]
 func AddTaskToList(c *gin.Context) {
@@ -52,6 +54,7 @@ func AddTaskToList(c *gin.Context) {
 			Status: 500,
 
 			Message: err.Error()})
+		return
 	}
 
 	result, err := s.ListManager.GetList(id)
@@ -62,6 +65,7 @@ func AddTaskToList(c *gin.Context) {
 			Status: 400,
 
 			Message: err.Error()})
+		return
 	} else if err != nil {
 		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
 
@@ -69,9 +73,13 @@ func AddTaskToList(c *gin.Context) {
 			Status: 500,
 
 			Message: err.Error()})
+		return
 	}
 
 	task := &models.Task{Title: req.Title, Description: req.Description, ListId: id, CreatedAt: time.Now()}
+	if !scheduleTask(c, task, req) {
+		return
+	}
 
 	s.TaskManager.CreateTask(task, id)
 	c.JSON(http.StatusOK, h.SaveResponse{
@@ -85,12 +93,13 @@ func AddTaskToList(c *gin.Context) {
 //	@BasePath	/api/v1
 //	@Summary	Delete Task
 //	@Schemes
-//	@Description	Sign-In with user credentials, for generated access token
+//	@Description	Move a task to the trash, from where /RestoreTask brings it back until it is purged
 //	@Accept			json
 //	@Produce		json
 //	@Param			id	path		int						true	"id"
 //	@Success		200	{object}	h.DeleteResult			"Successful"
 //	@Failure		400	{object}	h.BadRequestResponse	"Bad Request"
+//	@Failure		403	{object}	h.ForbiddenResponse	"Forbidden"
 //	@Failure		500	{object}	h.ErrorResponse			"Internal Server Error"
 //	@Security		BearerAuth
 //	@Router			/DeleteTask/{id} [delete]
@@ -105,6 +114,7 @@ func DeleteTask(c *gin.Context) {
 			Status: 500,
 
 			Message: err.Error()})
+		return
 	}
 
 	result, err := s.TaskManager.DeleteTask(id)
@@ -114,11 +124,13 @@ func DeleteTask(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
 			Status:  400,
 			Message: messages.NotFound})
+		return
 	} else if err != nil {
 		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
 		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
 			Status:  500,
 			Message: messages.SomethingWentWrong})
+		return
 	}
 
 	c.JSON(http.StatusOK, h.DeleteResult{
@@ -141,6 +153,7 @@ func DeleteTask(c *gin.Context) {
 //	@Param			Request	body		h.SaveTask				true	"Update Task"
 //	@Success		200		{object}	h.SuccessResponse		"Successful"
 //	@Failure		400		{object}	h.BadRequestResponse	"Bad Request"
+//	@Failure		403		{object}	h.ForbiddenResponse	"Forbidden"
 //	@Failure		500		{object}	h.ErrorResponse			"Internal Server Error"
 //	@Router			/UpdateTask/{id} [put]
 func UpdateTask(c *gin.Context) {
@@ -191,6 +204,9 @@ func UpdateTask(c *gin.Context) {
 	if req.Description != "" {
 		task.Description = req.Description
 	}
+	if !scheduleTask(c, task, req) {
+		return
+	}
 
 	result, err := s.TaskManager.UpdateTask(task)
 	if err != nil {
@@ -222,6 +238,7 @@ func UpdateTask(c *gin.Context) {
 //	@Param			Request	body		h.SetStatus				true	"Change Status"
 //	@Success		200		{object}	h.SaveResponse			"Successful"
 //	@Failure		400		{object}	h.BadRequestResponse	"Bad Request"
+//	@Failure		403		{object}	h.ForbiddenResponse	"Forbidden"
 //	@Failure		500		{object}	h.ErrorResponse			"Internal Server Error"
 //
 //	@Router			/TaskCompleted/{id} [put]
@@ -285,3 +302,109 @@ func ChangeStatus(c *gin.Context) {
 		Id:      result,
 	})
 }
+
+// Get Due Tasks endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Get Due Tasks
+//	@Schemes
+//	@Description	Tasks with a due date across the current user's own and shared lists, soonest first. due=overdue gives open tasks already past due, due=today those due today in tz (default UTC), and from and to those due in [from, to). Archived lists are left out.
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			due		query		string					false	"overdue or today"
+//	@Param			tz		query		string					false	"IANA time zone for due=today, e.g. Europe/Paris"
+//	@Param			from	query		string					false	"RFC 3339 start of the range"
+//	@Param			to		query		string					false	"RFC 3339 end of the range, exclusive"
+//	@Success		200		{array}		models.Task				"Successful"
+//	@Failure		400		{object}	h.BadRequestResponse	"Bad Request"
+//	@Failure		500		{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/DueTasks [get]
+func GetDueTasks(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var from, to time.Time
+	openOnly := false
+	switch c.Query("due") {
+	case "overdue":
+		to = time.Now()
+		openOnly = true
+	case "today":
+		loc, err := time.LoadLocation(c.DefaultQuery("tz", "UTC"))
+		if err != nil {
+			loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+
+			c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+				Status:  400,
+				Message: messages.InvalidTimeZone})
+			return
+		}
+		now := time.Now().In(loc)
+		from = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+		to = from.AddDate(0, 0, 1)
+	case "":
+		var fromErr, toErr error
+		from, fromErr = time.Parse(time.RFC3339, c.Query("from"))
+		to, toErr = time.Parse(time.RFC3339, c.Query("to"))
+		if fromErr != nil || toErr != nil || !from.Before(to) {
+			loggerutils.ErrorLog(ctx, http.StatusBadRequest, errors.New(messages.InvalidDueFilter))
+
+			c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+				Status:  400,
+				Message: messages.InvalidDueFilter})
+			return
+		}
+	default:
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, errors.New(messages.InvalidDueFilter))
+
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: messages.InvalidDueFilter})
+		return
+	}
+
+	tasks, err := s.TaskManager.GetDueTasksForUser(c.GetInt("user_id"), from, to, openOnly)
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.TaskQueryInternalError})
+		return
+	}
+	if tasks == nil {
+		tasks = []models.Task{}
+	}
+	c.JSON(http.StatusOK, tasks)
+}
+
+// scheduleTask sets the dates sent in the request on the task, in UTC,
+// and keeps the ones left out. It responds itself when the task would
+// then start after it is due.
+func scheduleTask(c *gin.Context, task *models.Task, req h.SaveTask) bool {
+	startAt, dueAt := task.StartAt, task.DueAt
+	if req.StartAt.Set {
+		startAt = inUTC(req.StartAt.Time)
+	}
+	if req.DueAt.Set {
+		dueAt = inUTC(req.DueAt.Time)
+	}
+	if startAt != nil && dueAt != nil && startAt.After(*dueAt) {
+		loggerutils.ErrorLog(c.Request.Context(), http.StatusBadRequest, errors.New(messages.TaskStartAfterDue))
+
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: messages.TaskStartAfterDue})
+		return false
+	}
+	task.StartAt, task.DueAt = startAt, dueAt
+	return true
+}
+
+func inUTC(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
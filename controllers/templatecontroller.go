@@ -0,0 +1,332 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	h "todo-web-api/helpers"
+	"todo-web-api/loggerutils"
+	"todo-web-api/messages"
+	models "todo-web-api/models"
+	s "todo-web-api/storage"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+// Save List As Template endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Save List As Template
+//	@Schemes
+//	@Description	Save a copy of a list's details and tasks as one of the current user's templates. Completion isn't kept.
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int						true	"List ID"
+//	@Param			Request	body		h.CopyList				true	"Template title; empty keeps the list's"
+//	@Success		200		{object}	h.SaveResponse			"Successful"
+//	@Failure		400		{object}	h.BadRequestResponse	"Bad Request"
+//	@Failure		403		{object}	h.ForbiddenResponse		"Forbidden"
+//	@Failure		404		{object}	h.ErrorResponse			"Not Found"
+//	@Failure		500		{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/SaveListAsTemplate/{id} [post]
+func SaveListAsTemplate(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req h.CopyList
+	if err := c.ShouldBindJSON(&req); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: err.Error()})
+		return
+	}
+
+	list, ok := findList(c)
+	if !ok {
+		return
+	}
+	tasks, ok := findListTasks(c, list)
+	if !ok {
+		return
+	}
+
+	template := &models.ListTemplate{
+		UserId:      c.GetInt("user_id"),
+		Title:       copyTitle(req.Title, list.Title),
+		Description: list.Description,
+		Color:       list.Color,
+		Icon:        list.Icon,
+	}
+	for _, task := range tasks {
+		template.Tasks = append(template.Tasks, models.TemplateTask{Title: task.Title, Description: task.Description})
+	}
+
+	if _, err := s.ListTemplateManager.CreateListTemplate(template); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.ListTemplateQueryInternalError})
+		return
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, messages.SuccessTemplateSaved)
+	c.JSON(http.StatusOK, h.SaveResponse{
+		Status:  200,
+		Message: messages.SuccessTemplateSaved,
+		Id:      template.Id})
+}
+
+// Get List Templates endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Get List Templates
+//	@Schemes
+//	@Description	The current user's list templates with their tasks, by title
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{array}		models.ListTemplate	"Successful"
+//	@Failure		500	{object}	h.ErrorResponse		"Internal Server Error"
+//	@Router			/ListTemplates [get]
+func GetListTemplates(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	templates, err := s.ListTemplateManager.GetListTemplatesForUser(c.GetInt("user_id"))
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.ListTemplateQueryInternalError})
+		return
+	}
+	if templates == nil {
+		templates = []models.ListTemplate{}
+	}
+	c.JSON(http.StatusOK, templates)
+}
+
+// Delete List Template endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Delete List Template
+//	@Schemes
+//	@Description	Delete one of the current user's templates. Lists created from it are kept.
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int						true	"Template ID"
+//	@Success		200	{object}	h.DeleteResult			"Successful"
+//	@Failure		400	{object}	h.BadRequestResponse	"Bad Request"
+//	@Failure		403	{object}	h.ForbiddenResponse		"Forbidden"
+//	@Failure		404	{object}	h.ErrorResponse			"Not Found"
+//	@Failure		500	{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/ListTemplates/{id} [delete]
+func DeleteListTemplate(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: messages.InvalidResourceId})
+		return
+	}
+
+	_, err = s.ListTemplateManager.DeleteListTemplate(id)
+	if err != nil && err.Error() == messages.ListTemplateNotFoundInDb {
+		loggerutils.ErrorLog(ctx, http.StatusNotFound, err)
+
+		c.JSON(http.StatusNotFound, h.ErrorResponse{
+			Status:  404,
+			Message: messages.ListTemplateNotFoundInDb})
+		return
+	} else if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.ListTemplateQueryInternalError})
+		return
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, messages.SuccessTemplateDeleted)
+	c.JSON(http.StatusOK, h.DeleteResult{
+		Status:  200,
+		Message: messages.SuccessTemplateDeleted,
+		Success: true})
+}
+
+// Create List From Template endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Create List From Template
+//	@Schemes
+//	@Description	Create a list for the current user from one of their templates, with the template's tasks all open
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int						true	"Template ID"
+//	@Param			Request	body		h.CopyList				true	"List title; empty keeps the template's"
+//	@Success		200		{object}	h.SaveResponse			"Successful"
+//	@Failure		400		{object}	h.BadRequestResponse	"Bad Request"
+//	@Failure		403		{object}	h.ForbiddenResponse		"Forbidden"
+//	@Failure		404		{object}	h.ErrorResponse			"Not Found"
+//	@Failure		500		{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/CreateListFromTemplate/{id} [post]
+func CreateListFromTemplate(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req h.CopyList
+	if err := c.ShouldBindJSON(&req); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: err.Error()})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: messages.InvalidResourceId})
+		return
+	}
+
+	template, err := s.ListTemplateManager.GetListTemplate(id)
+	if err != nil && err.Error() == messages.ListTemplateNotFoundInDb {
+		loggerutils.ErrorLog(ctx, http.StatusNotFound, err)
+
+		c.JSON(http.StatusNotFound, h.ErrorResponse{
+			Status:  404,
+			Message: messages.ListTemplateNotFoundInDb})
+		return
+	} else if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.ListTemplateQueryInternalError})
+		return
+	}
+
+	list := &models.List{
+		UserId:      c.GetInt("user_id"),
+		Title:       copyTitle(req.Title, template.Title),
+		Description: template.Description,
+		Color:       template.Color,
+		Icon:        template.Icon,
+	}
+	for _, task := range template.Tasks {
+		list.Tasks = append(list.Tasks, models.Task{Title: task.Title, Description: task.Description})
+	}
+	createListCopy(c, list, messages.SuccessListFromTemplate)
+}
+
+// Clone List endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Clone List
+//	@Schemes
+//	@Description	Copy a list and its tasks, with their dates, into a new list owned by the current user. Members aren't copied. With resetCompleted every task in the copy starts open.
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int						true	"List ID"
+//	@Param			Request	body		h.CloneList				true	"Clone options"
+//	@Success		200		{object}	h.SaveResponse			"Successful"
+//	@Failure		400		{object}	h.BadRequestResponse	"Bad Request"
+//	@Failure		403		{object}	h.ForbiddenResponse		"Forbidden"
+//	@Failure		404		{object}	h.ErrorResponse			"Not Found"
+//	@Failure		500		{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/CloneList/{id} [post]
+func CloneList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req h.CloneList
+	if err := c.ShouldBindJSON(&req); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: err.Error()})
+		return
+	}
+
+	source, ok := findList(c)
+	if !ok {
+		return
+	}
+	tasks, ok := findListTasks(c, source)
+	if !ok {
+		return
+	}
+
+	list := &models.List{
+		UserId:      c.GetInt("user_id"),
+		Title:       copyTitle(req.Title, source.Title),
+		Description: source.Description,
+		Color:       source.Color,
+		Icon:        source.Icon,
+	}
+	for _, task := range tasks {
+		list.Tasks = append(list.Tasks, models.Task{
+			Title:       task.Title,
+			Description: task.Description,
+			IsCompleted: task.IsCompleted && !req.ResetCompleted,
+			StartAt:     task.StartAt,
+			DueAt:       task.DueAt,
+		})
+	}
+	createListCopy(c, list, messages.SuccessListCloned)
+}
+
+// findListTasks loads the list's tasks, responding itself when it can't.
+func findListTasks(c *gin.Context, list *models.List) ([]models.Task, bool) {
+	tasks, err := s.TaskManager.GetTasksForList(list.Id)
+	if err != nil {
+		loggerutils.ErrorLog(c.Request.Context(), http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.TaskQueryInternalError})
+		return nil, false
+	}
+	return tasks, true
+}
+
+// createListCopy saves a new list together with its tasks.
+func createListCopy(c *gin.Context, list *models.List, message string) {
+	ctx := c.Request.Context()
+
+	if _, err := s.ListManager.CreateList(list); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.SomethingWentWrong})
+		return
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, message)
+	c.JSON(http.StatusOK, h.SaveResponse{
+		Status:  200,
+		Message: message,
+		Id:      list.Id})
+}
+
+func copyTitle(title string, original string) string {
+	if title = strings.TrimSpace(title); title != "" {
+		return title
+	}
+	return original
+}
@@ -0,0 +1,177 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	h "todo-web-api/helpers"
+	"todo-web-api/loggerutils"
+	"todo-web-api/messages"
+	s "todo-web-api/storage"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+// Get Trash endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Get Trash
+//	@Schemes
+//	@Description	Deleted lists the current user owns and tasks deleted from lists they can edit, most recent first. Items are purged once the retention period is over.
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	h.TrashResult		"Successful"
+//	@Failure		500	{object}	h.ErrorResponse		"Internal Server Error"
+//	@Router			/Trash [get]
+func GetTrash(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	userId := c.GetInt("user_id")
+	lists, err := s.ListManager.GetTrashedListsForUser(userId)
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.ListQueryInternalError})
+		return
+	}
+	tasks, err := s.TaskManager.GetTrashedTasksForUser(userId)
+	if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.TaskQueryInternalError})
+		return
+	}
+
+	result := h.TrashResult{Lists: []h.TrashedListResult{}, Tasks: []h.TrashedTaskResult{}}
+	for _, list := range lists {
+		result.Lists = append(result.Lists, h.TrashedListResult{
+			Id:        list.Id,
+			Title:     list.Title,
+			DeletedAt: list.DeletedAt.Time,
+		})
+	}
+	for _, task := range tasks {
+		result.Tasks = append(result.Tasks, h.TrashedTaskResult{
+			Id:        task.Id,
+			Title:     task.Title,
+			ListId:    task.ListId,
+			DeletedAt: task.DeletedAt.Time,
+		})
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// Restore List endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Restore List
+//	@Schemes
+//	@Description	Take a list out of the trash together with the tasks deleted with it. Only its owner can.
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int						true	"id"
+//	@Success		200	{object}	h.SaveResponse			"Successful"
+//	@Failure		400	{object}	h.BadRequestResponse	"Bad Request"
+//	@Failure		403	{object}	h.ForbiddenResponse		"Forbidden"
+//	@Failure		404	{object}	h.ErrorResponse			"Not Found"
+//	@Failure		500	{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/RestoreList/{id} [post]
+func RestoreList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := trashItemId(c)
+	if !ok {
+		return
+	}
+
+	_, err := s.ListManager.RestoreList(id)
+	if err != nil && err.Error() == messages.ListNotFoundInDb {
+		loggerutils.ErrorLog(ctx, http.StatusNotFound, err)
+
+		c.JSON(http.StatusNotFound, h.ErrorResponse{
+			Status:  404,
+			Message: messages.TrashItemNotFound})
+		return
+	} else if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.ListQueryInternalError})
+		return
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, messages.SuccessListRestored)
+	c.JSON(http.StatusOK, h.SaveResponse{
+		Status:  200,
+		Message: messages.SuccessListRestored,
+		Id:      id})
+}
+
+// Restore Task endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Restore Task
+//	@Schemes
+//	@Description	Take a task out of the trash. Editors of its list can; a task deleted with its list comes back when the list is restored, and is not found until then.
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int						true	"id"
+//	@Success		200	{object}	h.SaveResponse			"Successful"
+//	@Failure		400	{object}	h.BadRequestResponse	"Bad Request"
+//	@Failure		403	{object}	h.ForbiddenResponse		"Forbidden"
+//	@Failure		404	{object}	h.ErrorResponse			"Not Found"
+//	@Failure		500	{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/RestoreTask/{id} [post]
+func RestoreTask(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := trashItemId(c)
+	if !ok {
+		return
+	}
+
+	_, err := s.TaskManager.RestoreTask(id)
+	if err != nil && err.Error() == messages.TaskNotFoundInDb {
+		loggerutils.ErrorLog(ctx, http.StatusNotFound, err)
+
+		c.JSON(http.StatusNotFound, h.ErrorResponse{
+			Status:  404,
+			Message: messages.TrashItemNotFound})
+		return
+	} else if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: messages.TaskQueryInternalError})
+		return
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, messages.SuccessTaskRestored)
+	c.JSON(http.StatusOK, h.SaveResponse{
+		Status:  200,
+		Message: messages.SuccessTaskRestored,
+		Id:      id})
+}
+
+// trashItemId reads the id path parameter, responding itself when it
+// isn't a number.
+func trashItemId(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		loggerutils.ErrorLog(c.Request.Context(), http.StatusBadRequest, err)
+
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: messages.InvalidResourceId})
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	auth "todo-web-api/authentication"
+	h "todo-web-api/helpers"
+	"todo-web-api/loggerutils"
+	msg "todo-web-api/messages"
+
+	gin "github.com/gin-gonic/gin"
+)
+
+// Verify Email endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Verify Email
+//	@Schemes
+//	@Description	Confirm an email address with the token from a verification link. Signed-in clients should call /RefreshToken afterwards to lift the unverified restrictions.
+//	@Accept			json
+//	@Produce		json
+//	@Param			Request	body		h.VerifyEmail			true	"Verify Email Request"
+//	@Success		200		{object}	h.SuccessResponse		"Successful"
+//	@Failure		400		{object}	h.BadRequestResponse	"Invalid Or Expired Token"
+//	@Failure		500		{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/VerifyEmail [post]
+func VerifyEmail(c *gin.Context) {
+	ctx := c.Request.Context()
+	var req h.VerifyEmail
+	if err := c.ShouldBindJSON(&req); err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: err.Error()})
+		return
+	}
+
+	err := auth.VerifyEmail(req.Token)
+	if err != nil && (err.Error() == msg.InvalidVerificationToken || err.Error() == msg.EmailInUse) {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: err.Error()})
+		return
+	} else if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.SomethingWentWrong})
+		return
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, msg.SuccessEmailVerified)
+	c.JSON(http.StatusOK, h.SuccessResponse{
+		Status:  200,
+		Message: msg.SuccessEmailVerified})
+}
+
+// Resend Verification endpoint for Todo godoc
+//
+//	@BasePath	/api/v1
+//	@Summary	Resend Verification
+//	@Schemes
+//	@Description	Mail a new verification link to the account's email address; earlier links stop working
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	h.SuccessResponse		"Successful"
+//	@Failure		400	{object}	h.BadRequestResponse	"No Email Or Already Verified"
+//	@Failure		429	{object}	h.ErrorResponse			"Sent Too Recently"
+//	@Failure		500	{object}	h.ErrorResponse			"Internal Server Error"
+//	@Router			/ResendVerification [post]
+func ResendVerification(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, ok := findCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	retryAfter, err := auth.ResendEmailVerification(user)
+	if err != nil && err.Error() == msg.TooManyVerificationEmails {
+		loggerutils.ErrorLog(ctx, http.StatusTooManyRequests, err)
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, h.ErrorResponse{
+			Status:  429,
+			Message: msg.TooManyVerificationEmails})
+		return
+	} else if err != nil && (err.Error() == msg.NoEmailAddress || err.Error() == msg.EmailAlreadyVerified) {
+		loggerutils.ErrorLog(ctx, http.StatusBadRequest, err)
+		c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+			Status:  400,
+			Message: err.Error()})
+		return
+	} else if err != nil {
+		loggerutils.ErrorLog(ctx, http.StatusInternalServerError, err)
+		c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+			Status:  500,
+			Message: msg.SomethingWentWrong})
+		return
+	}
+
+	loggerutils.InfoLog(ctx, http.StatusOK, msg.SuccessVerificationSent)
+	c.JSON(http.StatusOK, h.SuccessResponse{
+		Status:  200,
+		Message: msg.SuccessVerificationSent})
+}
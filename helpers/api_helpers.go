@@ -1,12 +1,22 @@
 package helpers
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type User struct {
 	Username string `binding:"required"`
 	Password string `binding:"required"`
 }
 
+type Register struct {
+	Username string `binding:"required"`
+	Password string `binding:"required"`
+	// Optional; when given the account is restricted until it's verified.
+	Email string `binding:"omitempty,email,max=191" example:"u1@example.com"`
+}
+
 type SuccessResponse struct {
 	Status  int    `json:"status" example:"200"`
 	Message string `json:"message" example:"Success"`
@@ -38,6 +48,11 @@ type UnauthorizedResponse struct {
 	Message string `json:"message" example:"Unauthorized"`
 }
 
+type ForbiddenResponse struct {
+	Status  int    `json:"status" example:"403"`
+	Message string `json:"message" example:"Forbidden"`
+}
+
 type SaveResponse struct {
 	Username string `json:"username"`
 	Status  int    `json:"status" example:"200"`
@@ -52,8 +67,12 @@ type DeleteResult struct {
 }
 
 type UserResult struct {
-	Username  string    `json:"username"`
-	CreatedAt time.Time `json:"createdAt"`
+	Username      string `json:"username"`
+	Email         string `json:"email,omitempty" example:"u1@example.com"`
+	EmailVerified bool   `json:"emailVerified" example:"false"`
+	// Set while a requested deletion can still be cancelled.
+	DeletionScheduledAt *time.Time `json:"deletionScheduledAt,omitempty"`
+	CreatedAt           time.Time  `json:"createdAt"`
 }
 
 type UserContext struct {
@@ -61,9 +80,274 @@ type UserContext struct {
 	Id      int    `json:"id" example:"1"`
 }
 
+type AdminUserResult struct {
+	Id         int       `json:"id" example:"1"`
+	Username   string    `json:"username"`
+	Role       string    `json:"role" example:"user"`
+	IsDisabled bool      `json:"isDisabled" example:"false"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type MfaChallengeResponse struct {
+	Status      int    `json:"status" example:"200"`
+	Message     string `json:"message" example:"two-factor code required"`
+	MfaRequired bool   `json:"mfaRequired" example:"true"`
+	MfaToken    string `json:"mfaToken"`
+}
+
+type MfaLogin struct {
+	MfaToken     string `json:"mfaToken" binding:"required"`
+	Code         string `json:"code" example:"123456"`
+	RecoveryCode string `json:"recoveryCode" example:"abcde-fghij"`
+}
+
+type MfaCode struct {
+	Code string `json:"code" binding:"required" example:"123456"`
+}
+
+type MfaDisable struct {
+	Code         string `json:"code" example:"123456"`
+	RecoveryCode string `json:"recoveryCode" example:"abcde-fghij"`
+}
+
+type MfaEnrollResponse struct {
+	Status     int    `json:"status" example:"200"`
+	Message    string `json:"message" example:"Scan the QR code and confirm with a code"`
+	Secret     string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	OtpauthUri string `json:"otpauthUri" example:"otpauth://totp/Todo%20Manager:u1?secret=..."`
+}
+
+type RecoveryCodesResponse struct {
+	Status        int      `json:"status" example:"200"`
+	Message       string   `json:"message" example:"Two-factor authentication enabled"`
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type CreateAccessToken struct {
+	Name          string   `json:"name" binding:"required,max=100" example:"backup script"`
+	Scopes        []string `json:"scopes" binding:"required" example:"lists:read,tasks:write"`
+	ExpiresInDays int      `json:"expiresInDays" example:"30"`
+}
+
+type AccessTokenResult struct {
+	Id         int        `json:"id" example:"1"`
+	Name       string     `json:"name" example:"backup script"`
+	Prefix     string     `json:"prefix" example:"todo_pat_Xk3q"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+}
+
+type CreatedAccessTokenResponse struct {
+	Status      int               `json:"status" example:"200"`
+	Message     string            `json:"message" example:"Personal access token created"`
+	Token       string            `json:"token" example:"todo_pat_..."`
+	AccessToken AccessTokenResult `json:"accessToken"`
+}
+
+type CsrfTokenResponse struct {
+	Status    int    `json:"status" example:"200"`
+	Message   string `json:"message" example:"Send this token in the X-CSRF-Token header"`
+	CsrfToken string `json:"csrfToken"`
+}
+
+type SessionResult struct {
+	Id         string    `json:"id" example:"3f2b8c1e-6a4d-4f7e-9c2a-1b5d8e7f6a90"`
+	UserAgent  string    `json:"userAgent"`
+	IPAddress  string    `json:"ipAddress"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	Current    bool      `json:"current" example:"true"`
+}
+
+type PasswordViolation struct {
+	Rule    string `json:"rule" example:"min_length"`
+	Message string `json:"message" example:"must be at least 8 characters"`
+}
+
+type PasswordPolicyResponse struct {
+	Status     int                 `json:"status" example:"400"`
+	Message    string              `json:"message" example:"password does not meet the password policy"`
+	Violations []PasswordViolation `json:"violations"`
+}
+
+type SecurityEventResult struct {
+	Id        int       `json:"id" example:"1"`
+	EventType string    `json:"eventType" example:"login"`
+	Outcome   string    `json:"outcome" example:"failure"`
+	Detail    string    `json:"detail,omitempty" example:"invalid_password"`
+	IPAddress string    `json:"ipAddress"`
+	UserAgent string    `json:"userAgent"`
+	RequestId string    `json:"requestId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// IntrospectionResponse is the RFC 7662 answer. Inactive tokens get only
+// {"active": false}, whatever the reason.
+type IntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty" example:"account:read lists:read"`
+	Username  string `json:"username,omitempty" example:"u1"`
+	TokenType string `json:"token_type,omitempty" example:"Bearer"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Sub       string `json:"sub,omitempty" example:"1"`
+	Iss       string `json:"iss,omitempty" example:"Todo-Service"`
+	Jti       string `json:"jti,omitempty"`
+	// Session the token belongs to; not set for personal access tokens.
+	Sid string `json:"sid,omitempty"`
+}
+
+// OAuthErrorResponse is the error format of RFC 6749 section 5.2, which the
+// /oauth endpoints use instead of the API's own.
+type OAuthErrorResponse struct {
+	Error            string `json:"error" example:"invalid_client"`
+	ErrorDescription string `json:"error_description,omitempty" example:"client authentication failed"`
+}
+
+type ChangePassword struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required"`
+}
+
+type ForgotPassword struct {
+	Username string `json:"username" binding:"required"`
+}
+
+type ResetPassword struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required"`
+}
+
+type DeleteAccount struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type AccountDeletionResponse struct {
+	Status  int    `json:"status" example:"200"`
+	Message string `json:"message" example:"Account scheduled for deletion. Cancel before then to keep it"`
+	// Unset when the account is already gone.
+	DeletionScheduledAt *time.Time `json:"deletionScheduledAt,omitempty"`
+}
+
+type VerifyEmail struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type SaveList struct {
+	Title       string `json:"title" binding:"required,max=100" example:"Groceries"`
+	Description string `json:"description" binding:"max=500" example:"Things to pick up on Saturday"`
+}
+
+// UpdateList changes only the fields that are sent. An empty color or icon
+// clears it.
+type UpdateList struct {
+	Title       *string `json:"title" binding:"omitempty,max=100" example:"Groceries"`
+	Description *string `json:"description" binding:"omitempty,max=500" example:"Things to pick up on Saturday"`
+	Color       *string `json:"color" example:"#1e90ff"`
+	Icon        *string `json:"icon" binding:"omitempty,max=50" example:"cart"`
+	Position    *int    `json:"position" example:"2"`
+}
+
+type SetArchived struct {
+	IsArchived *bool `json:"isArchived" binding:"required" example:"true"`
+}
+
+// CopyList names the template or list made from another one; an empty
+// title keeps the original's.
+type CopyList struct {
+	Title string `json:"title" binding:"max=100" example:"Packing"`
+}
+
+// CloneList can also reopen every task in the copy, for checklists that
+// are gone through again.
+type CloneList struct {
+	Title          string `json:"title" binding:"max=100" example:"Release 2.4 checklist"`
+	ResetCompleted bool   `json:"resetCompleted" example:"true"`
+}
+
+type InviteListMember struct {
+	Username string `json:"username" binding:"required" example:"u2"`
+	Role     string `json:"role" binding:"required" example:"editor"`
+}
+
+type SetListMemberRole struct {
+	Role string `json:"role" binding:"required" example:"viewer"`
+}
+
+// ListMemberResult is a person with access to a list: its owner, or a
+// member who is still pending until they accept the invitation.
+type ListMemberResult struct {
+	UserId    int       `json:"userId" example:"2"`
+	Username  string    `json:"username" example:"u2"`
+	Role      string    `json:"role" example:"editor"`
+	Pending   bool      `json:"pending" example:"false"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type InvitationResult struct {
+	Id        int       `json:"id" example:"1"`
+	ListId    int       `json:"listId" example:"10"`
+	ListTitle string    `json:"listTitle" example:"Groceries"`
+	Role      string    `json:"role" example:"editor"`
+	InvitedBy string    `json:"invitedBy" example:"u1"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TrashResult is what the user can restore: the lists they own that are in
+// the trash, and the tasks deleted on their own from lists they can edit.
+type TrashResult struct {
+	Lists []TrashedListResult `json:"lists"`
+	Tasks []TrashedTaskResult `json:"tasks"`
+}
+
+type TrashedListResult struct {
+	Id        int       `json:"id" example:"10"`
+	Title     string    `json:"title" example:"Groceries"`
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+type TrashedTaskResult struct {
+	Id        int       `json:"id" example:"1"`
+	Title     string    `json:"title" example:"Milk"`
+	ListId    int       `json:"listId" example:"10"`
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
 type SaveTask struct {
 	Title       string `binding:"required"`
 	Description string
+	// RFC 3339 times with a UTC offset, e.g. 2024-05-01T17:00:00+02:00.
+	// On update a date left out is kept and null clears it.
+	StartAt OptionalTime `json:"start_at" swaggertype:"string" format:"date-time"`
+	DueAt   OptionalTime `json:"due_at" swaggertype:"string" format:"date-time"`
+}
+
+// OptionalTime tells a key left out of the body (Set is false) from one
+// sent as null (Set is true and Time nil).
+type OptionalTime struct {
+	Set  bool
+	Time *time.Time
+}
+
+func (o *OptionalTime) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		o.Time = nil
+		return nil
+	}
+	var t time.Time
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	o.Time = &t
+	return nil
+}
+
+func (o OptionalTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.Time)
 }
 
 type SetStatus struct {
@@ -0,0 +1,147 @@
+package mailer
+
+import (
+	"errors"
+	"fmt"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"todo-web-api/loggerutils"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+var log = loggerutils.GetLogger()
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers mail. Which one is used is picked by Configure at
+// startup; controllers and the authentication package only call Send.
+type Sender interface {
+	Send(message Message) error
+}
+
+type Config struct {
+	// log, file or smtp
+	Sender       string
+	From         string
+	Dir          string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	// AllowLocal permits the log and file senders. They leave reset and
+	// verification links readable on the server, so only local development
+	// turns it on.
+	AllowLocal bool
+}
+
+var sender Sender = LogSender{}
+
+// Configure picks the sender; with none configured mail is only logged,
+// which, like the file sender, needs AllowLocal.
+func Configure(config Config) error {
+	switch config.Sender {
+	case "", "log":
+		if !config.AllowLocal {
+			return errors.New("the log mail sender is only allowed in local development, use smtp")
+		}
+		sender = LogSender{}
+	case "file":
+		if !config.AllowLocal {
+			return errors.New("the file mail sender is only allowed in local development, use smtp")
+		}
+		if config.Dir == "" {
+			return errors.New("mail dir is required for the file sender")
+		}
+		sender = FileSender{Dir: config.Dir}
+	case "smtp":
+		if config.SMTPHost == "" || config.From == "" {
+			return errors.New("smtp host and from address are required for the smtp sender")
+		}
+		sender = SMTPSender{Host: config.SMTPHost, Port: config.SMTPPort, Username: config.SMTPUsername,
+			Password: config.SMTPPassword, From: config.From}
+	default:
+		return fmt.Errorf("unknown mail sender %q", config.Sender)
+	}
+	return nil
+}
+
+// SetSender swaps the sender, for tests.
+func SetSender(s Sender) {
+	sender = s
+}
+
+func Send(message Message) error {
+	return sender.Send(message)
+}
+
+// LogSender writes mail to the application log. Fine for local development;
+// anything secret in the body ends up in the logs.
+type LogSender struct{}
+
+func (LogSender) Send(message Message) error {
+	log.WithFields(logrus.Fields{
+		"LoggerName": "Mailer",
+		"To":         message.To,
+		"Subject":    message.Subject,
+	}).Info(message.Body)
+	return nil
+}
+
+// FileSender writes each message to its own file in Dir, so local
+// development and tests can read what would have been sent.
+type FileSender struct {
+	Dir string
+}
+
+func (f FileSender) Send(message Message) error {
+	if err := os.MkdirAll(f.Dir, 0o700); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), uuid.NewString())
+	return os.WriteFile(filepath.Join(f.Dir, name), []byte(format("", message)), 0o600)
+}
+
+type SMTPSender struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (s SMTPSender) Send(message Message) error {
+	port := s.Port
+	if port == "" {
+		port = "587"
+	}
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+	to := headerValue.Replace(message.To)
+	return smtp.SendMail(s.Host+":"+port, auth, s.From, []string{to}, []byte(format(s.From, message)))
+}
+
+// headerValue drops line breaks so a user-supplied value can't add headers.
+var headerValue = strings.NewReplacer("\r", "", "\n", "")
+
+func format(from string, message Message) string {
+	var b strings.Builder
+	if from != "" {
+		b.WriteString("From: " + headerValue.Replace(from) + "\r\n")
+	}
+	b.WriteString("To: " + headerValue.Replace(message.To) + "\r\n")
+	b.WriteString("Subject: " + headerValue.Replace(message.Subject) + "\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(message.Body)
+	return b.String()
+}
@@ -10,17 +10,107 @@ var InvalidRefreshToken string = "invalid refresh token."
 var InvalidPassword string = "Invalid Password Credentials"
 var AccessTokenError string = "Error while generating access token."
 var UnauthorizedRefreshToken = "refresh token unauthorized"
+var RefreshTokenReused = "refresh token reuse detected, all sessions revoked"
+var RefreshTokenError = "Error while generating refresh token."
 var NotFound string = "Not found"
+var Forbidden string = "access to this resource is forbidden"
+var InvalidResourceId string = "invalid resource id"
+var MissingPermission string = "missing permission for this action"
+var AccountDisabled string = "account is disabled"
+var CannotDisableSelf string = "admins cannot disable their own account"
+var SessionNotFound string = "session not found"
+var AccountLocked string = "account temporarily locked after too many failed logins"
+var TooManyLoginAttempts string = "too many failed login attempts, try again later"
+var MfaRequired string = "two-factor code required"
+var InvalidMfaCode string = "invalid two-factor code"
+var InvalidMfaToken string = "invalid or expired mfa token"
+var MfaAlreadyEnabled string = "two-factor authentication is already enabled"
+var MfaNotEnrolled string = "two-factor authentication is not set up"
+var MfaCodeRequired string = "a code or recovery code is required"
+var InvalidAccessToken string = "invalid or expired personal access token"
+var AccessTokenNotFound string = "personal access token not found"
+var InvalidScope string = "invalid scope"
+var InvalidTokenExpiry string = "expiresInDays must be between 1 and 365"
+var InvalidCurrentPassword string = "current password is incorrect"
+var InvalidResetToken string = "reset link is invalid or has expired"
+var InvalidVerificationToken string = "verification link is invalid or has expired"
+var UnknownOIDCProvider string = "unknown sign-in provider"
+var InvalidCSRFToken string = "missing or invalid CSRF token"
+var CSRFNeedsSession string = "CSRF tokens are only issued to signed-in sessions"
+var InvalidOIDCState string = "sign-in request expired or didn't start here, try again"
+var OIDCLoginFailed string = "sign-in with the provider failed"
+var OIDCSignupDisabled string = "no account is linked to this sign-in"
+var EmailInUse string = "email address is already in use"
+var EmailAlreadyVerified string = "email address is already verified"
+var NoEmailAddress string = "account has no email address"
+var TooManyVerificationEmails string = "a verification email was sent recently, try again later"
+var AccountDeletionNotScheduled string = "account is not scheduled for deletion"
+var InvalidLimit string = "limit must be a positive number"
+var PasswordPolicyViolated string = "password does not meet the password policy"
+var InvalidOAuthClient string = "client authentication failed"
+var TokenRequired string = "token is required"
+var ListTitleRequired string = "list title is required"
+var InvalidListColor string = "color must be a hex color such as #1e90ff"
+var AlreadyListMember string = "user is already a member of this list or has been invited"
+var CannotInviteListOwner string = "the list owner is already a member"
+var CannotChangeListOwner string = "the list owner's access can't be changed"
+var InvitationNotFound string = "invitation not found"
+var InvalidListRole string = "role must be editor or viewer"
+var ListMemberNotFound string = "user is not a member of this list"
+var TrashItemNotFound string = "not found in the trash"
+var TaskStartAfterDue string = "start_at can't be after due_at"
+var InvalidDueFilter string = "give due=overdue, due=today, or from and to as RFC 3339 times with from before to"
+var InvalidTimeZone string = "tz must be an IANA time zone such as Europe/Paris"
 
 var SuccessLogout = "User logged out successfully"
 var SuccessLogin = "User logged in successfully"
+var SuccessCsrfToken = "Send this token in the X-CSRF-Token header"
 var SuccessUserCreate = "User created successfully"
 var SuccessListCreate = "List created successfully"
 var SuccessTaskCreate = "Task created successfully"
+var SuccessSessionRevoke = "Session revoked successfully"
+var SuccessUserDisabled = "User disabled successfully"
+var SuccessUserEnabled = "User enabled successfully"
+var SuccessForcedLogout = "User signed out of all sessions"
+var SuccessMfaEnrollStarted = "Scan the QR code and confirm with a code"
+var SuccessMfaEnabled = "Two-factor authentication enabled"
+var SuccessMfaDisabled = "Two-factor authentication disabled"
+var SuccessAccessTokenCreate = "Personal access token created. Copy it now, it won't be shown again"
+var SuccessAccessTokenRevoke = "Personal access token revoked"
+var SuccessPasswordChange = "Password changed. Other sessions were signed out"
+var SuccessPasswordResetRequested = "If the account exists, a reset link has been sent"
+var SuccessPasswordReset = "Password reset. Sign in with the new password"
+var SuccessEmailVerified = "Email address verified"
+var SuccessVerificationSent = "Verification email sent"
+var SuccessAccountDeleted = "Account and all of its data deleted"
+var SuccessAccountDeletionScheduled = "Account scheduled for deletion. Cancel before then to keep it"
+var SuccessAccountDeletionCancelled = "Account deletion cancelled"
+var SuccessTokenRevoked = "Token revoked"
+var SuccessListUpdate = "List updated successfully."
+var SuccessListArchived = "List archived."
+var SuccessListUnarchived = "List restored from the archive."
+var SuccessInvitationSent = "Invitation sent."
+var SuccessInvitationAccepted = "Invitation accepted."
+var SuccessInvitationDeclined = "Invitation declined."
+var SuccessListMemberUpdate = "Member role updated."
+var SuccessListMemberRemoved = "Member removed from the list."
+var SuccessListRestored = "List restored from the trash."
+var SuccessTaskRestored = "Task restored from the trash."
+var SuccessTemplateSaved = "List saved as a template."
+var SuccessTemplateDeleted = "Template deleted."
+var SuccessListFromTemplate = "List created from the template."
+var SuccessListCloned = "List cloned."
 
 var UserNotFoundInDb = "User record not found in db"
 var TaskNotFoundInDb = "Task record not found in db"
 var ListNotFoundInDb = "List record not found in db"
+var SessionNotFoundInDb = "Session record not found in db"
+var LoginAttemptNotFoundInDb = "Login attempt record not found in db"
+var AccessTokenNotFoundInDb = "Access token record not found in db"
+var OneTimeTokenNotFoundInDb = "One-time token record not found in db"
+var ExternalIdentityNotFoundInDb = "External identity record not found in db"
+var ListMemberNotFoundInDb = "List member record not found in db"
+var ListTemplateNotFoundInDb = "List template record not found in db"
 
 var FailedTaskDelete = "Task delete failed"
 var FailedListDelete = "List delete failed"
@@ -29,3 +119,13 @@ var FailedUserDelete = "User delete failed"
 var TaskQueryInternalError string = "something went wrong while fetching task"
 var ListQueryInternalError string = "something went wrong while fetching list"
 var UserQueryInternalError string = "something went wrong while fetching user"
+var SessionQueryInternalError string = "something went wrong while fetching session"
+var SessionSaveError string = "Error while saving session."
+var LoginAttemptQueryInternalError string = "something went wrong while fetching login attempts"
+var RecoveryCodeQueryInternalError string = "something went wrong while fetching recovery codes"
+var AccessTokenQueryInternalError string = "something went wrong while fetching access tokens"
+var OneTimeTokenQueryInternalError string = "something went wrong while fetching one-time tokens"
+var ExternalIdentityQueryInternalError string = "something went wrong while fetching external identities"
+var AuditEventQueryInternalError string = "something went wrong while fetching audit events"
+var ListMemberQueryInternalError string = "something went wrong while fetching list members"
+var ListTemplateQueryInternalError string = "something went wrong while fetching list templates"
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strings"
 	auth "todo-web-api/authentication"
+	authz "todo-web-api/authorization"
 	l "todo-web-api/loggerutils"
 
 	"github.com/gin-gonic/gin"
@@ -16,6 +17,8 @@ func AuthMiddleware() gin.HandlerFunc {
 
 		var tokenStr string
 		var errMessage string
+		// RequireCSRF only checks requests a browser authenticates on its own.
+		authMethod := "bearer"
 		authHeader := c.GetHeader("Authorization")
 
 		if authHeader != ""{
@@ -45,6 +48,28 @@ func AuthMiddleware() gin.HandlerFunc {
 				return
 			}
 			tokenStr = cookieToken
+			authMethod = "cookie"
+		}
+		c.Set("auth_method", authMethod)
+
+		// Personal access tokens are opaque, not JWTs, and carry no session.
+		if auth.IsAccessToken(tokenStr) {
+			user, permissions, err := auth.AuthenticateAccessToken(tokenStr)
+			if err != nil {
+				l.ErrorLog(c.Request.Context(), http.StatusUnauthorized, err)
+				c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				c.Abort()
+				return
+			}
+
+			c.Set("user_id", user.Id)
+			c.Set("username", user.Username)
+			c.Set("roles", auth.Roles(user))
+			c.Set("permissions", permissions)
+
+			l.Log.WithFields(logrus.Fields{"LoggerName": "Auth Middleware"}).Info("personal access token authenticated")
+			c.Next()
+			return
 		}
 
 		claims, err := auth.ParseToken(tokenStr)
@@ -55,7 +80,7 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		if _, ok := auth.ActiveTokens[claims.Username]; !ok {
+		if !auth.IsTokenActive(claims.SessionID, tokenStr) {
 			l.ErrorLog(c.Request.Context(), http.StatusUnauthorized, errors.New("token unauthorized"))
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "token unauthorized"})
 			c.Abort()
@@ -63,6 +88,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		auth.Payload(claims, c)
+		c.Set("permissions", authz.PermissionsFor(claims.Roles...))
 
 		l.Log.WithFields(logrus.Fields{"LoggerName": "Auth Middleware"}).Info("token authenticated")
 		c.Next()
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"todo-web-api/authorization"
+	h "todo-web-api/helpers"
+	l "todo-web-api/loggerutils"
+	"todo-web-api/messages"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// RequireListRole rejects the request with 403 unless the authenticated
+// user has at least role on the list that the resource named by the param
+// path parameter belongs to, and with 404 when the resource or its list
+// doesn't exist. Handlers find the user's role under "list_role". It must
+// run after AuthMiddleware.
+func RequireListRole(resolve authorization.ListResolver, param string, role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		id, err := strconv.Atoi(c.Param(param))
+		if err != nil {
+			l.ErrorLog(ctx, http.StatusBadRequest, err)
+			c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+				Status:  400,
+				Message: messages.InvalidResourceId})
+			c.Abort()
+			return
+		}
+
+		listId, err := resolve(id)
+		var granted string
+		if err == nil {
+			granted, err = authorization.ListRole(listId, c.GetInt("user_id"))
+		}
+		if err != nil && authorization.IsNotFound(err) {
+			l.ErrorLog(ctx, http.StatusNotFound, err)
+			c.JSON(http.StatusNotFound, h.ErrorResponse{
+				Status:  404,
+				Message: err.Error()})
+			c.Abort()
+			return
+		} else if err != nil {
+			l.ErrorLog(ctx, http.StatusInternalServerError, err)
+			c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+				Status:  500,
+				Message: messages.SomethingWentWrong})
+			c.Abort()
+			return
+		}
+
+		if !authorization.HasListRole(granted, role) {
+			l.ErrorLog(ctx, http.StatusForbidden, errors.New(messages.Forbidden))
+			c.JSON(http.StatusForbidden, h.ForbiddenResponse{
+				Status:  403,
+				Message: messages.Forbidden})
+			c.Abort()
+			return
+		}
+
+		l.Log.WithFields(logrus.Fields{"LoggerName": "List Role Middleware"}).Info("list role verified")
+		c.Set("list_role", granted)
+		c.Next()
+	}
+}
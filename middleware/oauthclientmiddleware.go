@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	auth "todo-web-api/authentication"
+	h "todo-web-api/helpers"
+	l "todo-web-api/loggerutils"
+	"todo-web-api/messages"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireOAuthClient authenticates the calling service against
+// auth.oauth_clients. Credentials come as HTTP Basic (client_secret_basic)
+// or as client_id and client_secret form fields (client_secret_post).
+func RequireOAuthClient() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clientId, clientSecret, ok := c.Request.BasicAuth()
+		if ok {
+			// RFC 6749 section 2.3.1: both are form-encoded before they go
+			// into the header.
+			id, idErr := url.QueryUnescape(clientId)
+			secret, secretErr := url.QueryUnescape(clientSecret)
+			if idErr != nil || secretErr != nil {
+				id, secret = "", ""
+			}
+			clientId, clientSecret = id, secret
+		} else {
+			clientId, clientSecret = c.PostForm("client_id"), c.PostForm("client_secret")
+		}
+
+		if !auth.AuthenticateOAuthClient(clientId, clientSecret) {
+			l.ErrorLog(c.Request.Context(), http.StatusUnauthorized, errors.New(messages.InvalidOAuthClient))
+			c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+			c.JSON(http.StatusUnauthorized, h.OAuthErrorResponse{
+				Error:            "invalid_client",
+				ErrorDescription: messages.InvalidOAuthClient})
+			c.Abort()
+			return
+		}
+		c.Set("client_id", clientId)
+		c.Next()
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"todo-web-api/authorization"
+	h "todo-web-api/helpers"
+	l "todo-web-api/loggerutils"
+	"todo-web-api/messages"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// RequireOwner rejects the request with 403 unless the resource named by the
+// param path parameter belongs to the authenticated user, and with 404 when
+// there is no such resource. It must run after AuthMiddleware.
+func RequireOwner(resolve authorization.OwnerResolver, param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		id, err := strconv.Atoi(c.Param(param))
+		if err != nil {
+			l.ErrorLog(ctx, http.StatusBadRequest, err)
+			c.JSON(http.StatusBadRequest, h.BadRequestResponse{
+				Status:  400,
+				Message: messages.InvalidResourceId})
+			c.Abort()
+			return
+		}
+
+		owner, err := resolve(id)
+		if err != nil && authorization.IsNotFound(err) {
+			l.ErrorLog(ctx, http.StatusNotFound, err)
+			c.JSON(http.StatusNotFound, h.ErrorResponse{
+				Status:  404,
+				Message: err.Error()})
+			c.Abort()
+			return
+		} else if err != nil {
+			l.ErrorLog(ctx, http.StatusInternalServerError, err)
+			c.JSON(http.StatusInternalServerError, h.ErrorResponse{
+				Status:  500,
+				Message: messages.SomethingWentWrong})
+			c.Abort()
+			return
+		}
+
+		if owner != c.GetInt("user_id") {
+			l.ErrorLog(ctx, http.StatusForbidden, errors.New(messages.Forbidden))
+			c.JSON(http.StatusForbidden, h.ForbiddenResponse{
+				Status:  403,
+				Message: messages.Forbidden})
+			c.Abort()
+			return
+		}
+
+		l.Log.WithFields(logrus.Fields{"LoggerName": "Ownership Middleware"}).Info("resource owner verified")
+		c.Next()
+	}
+}
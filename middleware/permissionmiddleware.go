@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"todo-web-api/authorization"
+	h "todo-web-api/helpers"
+	l "todo-web-api/loggerutils"
+	"todo-web-api/messages"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission rejects the request with 403 unless the authenticated
+// user's roles grant permission. It must run after AuthMiddleware.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !authorization.HasPermission(c.GetStringSlice("permissions"), permission) {
+			l.ErrorLog(c.Request.Context(), http.StatusForbidden, errors.New(messages.MissingPermission))
+			c.JSON(http.StatusForbidden, h.ForbiddenResponse{
+				Status:  403,
+				Message: messages.MissingPermission})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
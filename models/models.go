@@ -2,6 +2,8 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type Task struct {
@@ -11,18 +13,190 @@ type Task struct {
 	IsCompleted bool      `gorm:"default:false" json:"isCompleted"`
 	ListId      int       `gorm:"foreignkey:ListId" json:"list_id"`
 	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	// StartAt is when the task is scheduled to start and DueAt when it is
+	// due; either can be left unset. Both are stored in UTC.
+	StartAt *time.Time `json:"start_at"`
+	DueAt   *time.Time `gorm:"index" json:"due_at"`
+	// DeletedAt puts the task in the trash, where it stays until restored
+	// or purged. GORM leaves trashed rows out of every other query.
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 type List struct {
-	Id        int       `gorm:"primaryKey" json:"id"`
-	Tasks     []Task    `json:"tasks"`
-	UserId    int       `gorm:"foreignkey:UserId" json:"user_id"`
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	Id          int    `gorm:"primaryKey" json:"id"`
+	Title       string `gorm:"size:100" json:"title"`
+	Description string `gorm:"size:500" json:"description"`
+	// Color is a hex color such as #1e90ff; Icon is whatever name or emoji
+	// the client shows. Lists are sorted by Position, which clients set.
+	Color    string `gorm:"size:9" json:"color"`
+	Icon     string `gorm:"size:50" json:"icon"`
+	Position int    `gorm:"default:0" json:"position"`
+	// ArchivedAt hides the list from the default listings; its tasks stay.
+	ArchivedAt *time.Time `gorm:"index" json:"archived_at"`
+	// Purging a list deletes its tasks and memberships, in the database as
+	// well as in the stores.
+	Tasks     []Task       `gorm:"constraint:OnDelete:CASCADE" json:"tasks"`
+	Members   []ListMember `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	UserId    int          `gorm:"foreignkey:UserId" json:"user_id"`
+	CreatedAt time.Time    `gorm:"autoCreateTime" json:"created_at"`
+	// DeletedAt puts the list in the trash; its live tasks go with it, at
+	// the same time, so restoring the list brings back just those.
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	// Role is the requesting user's role on the list, filled in by /Lists.
+	Role string `gorm:"-" json:"role,omitempty"`
 }
 
 type User struct {
+	Id         int    `gorm:"primaryKey" json:"id"`
+	Username   string `gorm:"size:100;not null" json:"username"`
+	Password   string `gorm:"size:255;not null" json:"password"`
+	Role       string `gorm:"size:20;not null;default:user" json:"role"`
+	IsDisabled bool   `gorm:"default:false" json:"is_disabled"`
+	// Email is optional. One that hasn't been verified yet restricts the
+	// account, and isn't used for mail other than the verification link.
+	Email         string `gorm:"size:191;index" json:"email"`
+	EmailVerified bool   `gorm:"default:false" json:"email_verified"`
+	// VerifiedEmail is Email once it's verified, and null before. Its unique
+	// index keeps two accounts from holding the same verified address;
+	// unverified ones don't claim it.
+	VerifiedEmail *string `gorm:"size:191;uniqueIndex" json:"-"`
+	// TOTPSecret is set on enrollment but only enforced once TOTPEnabled;
+	// TOTPLastCounter is the last time step accepted, so a code can't be
+	// replayed.
+	TOTPSecret      string `gorm:"size:64" json:"-"`
+	TOTPEnabled     bool   `gorm:"default:false" json:"totp_enabled"`
+	TOTPLastCounter int64  `gorm:"default:0" json:"-"`
+	// DeletionScheduledAt is when a pending self-service deletion goes
+	// through; nil unless one was requested with a grace period.
+	DeletionScheduledAt *time.Time `gorm:"index" json:"deletion_scheduled_at"`
+	CreatedAt           time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// Session is one signed-in device. Each login gets its own SessionId (the
+// sid claim) and access/refresh pair. Tokens are stored as SHA-256
+// digests, never raw.
+type Session struct {
+	Id           int       `gorm:"primaryKey" json:"-"`
+	SessionId    string    `gorm:"size:36;not null;uniqueIndex" json:"id"`
+	UserId       int       `gorm:"not null;index" json:"user_id"`
+	Username     string    `gorm:"size:100;not null" json:"username"`
+	AccessToken  string    `gorm:"size:64" json:"-"`
+	RefreshToken string    `gorm:"size:64" json:"-"`
+	UserAgent    string    `gorm:"size:255" json:"user_agent"`
+	IPAddress    string    `gorm:"size:45" json:"ip_address"`
+	LastUsedAt   time.Time `json:"last_used_at"`
+	ExpiresAt    time.Time `gorm:"index" json:"expires_at"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// LoginAttempt counts recent failed logins for one subject, either a
+// username ("user:<name>") or a client address ("ip:<addr>").
+type LoginAttempt struct {
+	Id           int       `gorm:"primaryKey" json:"-"`
+	Subject      string    `gorm:"size:191;not null;uniqueIndex" json:"subject"`
+	Failures     int       `gorm:"not null;default:0" json:"failures"`
+	LastFailedAt time.Time `json:"last_failed_at"`
+	LockedUntil  time.Time `gorm:"index" json:"locked_until"`
+}
+
+// RecoveryCode is a single-use fallback for a lost authenticator, stored as
+// a SHA-256 digest.
+type RecoveryCode struct {
+	Id        int        `gorm:"primaryKey" json:"-"`
+	UserId    int        `gorm:"not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"size:64;not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// PersonalAccessToken lets scripts call the API with a bearer token instead
+// of the cookie login. Only the SHA-256 digest of the token is stored;
+// Prefix keeps enough of it to tell tokens apart in a list.
+type PersonalAccessToken struct {
+	Id         int        `gorm:"primaryKey" json:"id"`
+	UserId     int        `gorm:"not null;index" json:"user_id"`
+	Name       string     `gorm:"size:100;not null" json:"name"`
+	TokenHash  string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Prefix     string     `gorm:"size:20;not null" json:"prefix"`
+	Scopes     string     `gorm:"size:255;not null" json:"scopes"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time  `gorm:"index" json:"expires_at"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// OneTimeToken is a single-use, expiring token mailed to a user, such as a
+// password reset link. Only its SHA-256 digest is stored.
+type OneTimeToken struct {
+	Id        int        `gorm:"primaryKey" json:"-"`
+	UserId    int        `gorm:"not null;index" json:"user_id"`
+	Purpose   string     `gorm:"size:20;not null" json:"purpose"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"index" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// ExternalIdentity links an account at an OpenID Connect provider, known by
+// the provider's name from the config and its subject, to a local user.
+type ExternalIdentity struct {
+	Id          int       `gorm:"primaryKey" json:"-"`
+	UserId      int       `gorm:"not null;index" json:"user_id"`
+	Provider    string    `gorm:"size:50;not null;uniqueIndex:idx_provider_subject" json:"provider"`
+	Subject     string    `gorm:"size:191;not null;uniqueIndex:idx_provider_subject" json:"-"`
+	Email       string    `gorm:"size:191" json:"email"`
+	LastLoginAt time.Time `json:"last_login_at"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// AuditEvent records one security-relevant action, such as a login or a
+// failed attempt at one. Events are only ever appended. UserId is 0 when
+// the attempt couldn't be tied to an account.
+type AuditEvent struct {
 	Id        int       `gorm:"primaryKey" json:"id"`
-	Username  string    `gorm:"size:100;not null" json:"username"`
-	Password  string    `gorm:"size:255;not null" json:"password"`
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UserId    int       `gorm:"not null;index" json:"user_id"`
+	Username  string    `gorm:"size:100" json:"username"`
+	EventType string    `gorm:"size:40;not null" json:"event_type"`
+	Outcome   string    `gorm:"size:20;not null" json:"outcome"`
+	Detail    string    `gorm:"size:100" json:"detail"`
+	IPAddress string    `gorm:"size:45" json:"ip_address"`
+	UserAgent string    `gorm:"size:255" json:"user_agent"`
+	RequestId string    `gorm:"size:36" json:"request_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+// ListMember shares a list with a user other than its owner, who stays the
+// list's UserId. Role is editor or viewer. The member has no access until
+// they accept the invitation, which sets AcceptedAt.
+type ListMember struct {
+	Id         int        `gorm:"primaryKey" json:"id"`
+	ListId     int        `gorm:"not null;uniqueIndex:idx_list_member" json:"list_id"`
+	UserId     int        `gorm:"not null;uniqueIndex:idx_list_member;index" json:"user_id"`
+	Username   string     `gorm:"size:100" json:"username"`
+	Role       string     `gorm:"size:10;not null" json:"role"`
+	InvitedBy  int        `json:"invited_by"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// ListTemplate is a reusable copy of a list's details and tasks, such as a
+// packing checklist, that new lists can be created from. Templates belong
+// to one user and aren't shared.
+type ListTemplate struct {
+	Id          int            `gorm:"primaryKey" json:"id"`
+	UserId      int            `gorm:"not null;index" json:"user_id"`
+	Title       string         `gorm:"size:100" json:"title"`
+	Description string         `gorm:"size:500" json:"description"`
+	Color       string         `gorm:"size:9" json:"color"`
+	Icon        string         `gorm:"size:50" json:"icon"`
+	Tasks       []TemplateTask `gorm:"foreignKey:TemplateId;constraint:OnDelete:CASCADE" json:"tasks"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TemplateTask is a task in a template. It has no completion state; tasks
+// created from it start out open.
+type TemplateTask struct {
+	Id          int    `gorm:"primaryKey" json:"-"`
+	TemplateId  int    `gorm:"not null;index" json:"-"`
+	Title       string `gorm:"size:255;not null" json:"title"`
+	Description string `json:"description"`
 }
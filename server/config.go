@@ -3,6 +3,7 @@ package server
 import (
 	"os"
 	"strings"
+	"time"
 	l "todo-web-api/loggerutils"
 
 	"github.com/sirupsen/logrus"
@@ -15,6 +16,9 @@ type Config struct {
 	Swagger    Swagger    `yaml:"swagger"`
 	APIConfig  APIConfig  `yaml:"api"`
 	CORSConfig CORSConfig `yaml:"cors"`
+	Auth       Auth       `yaml:"auth"`
+	Mail       Mail       `yaml:"mail"`
+	Trash      Trash      `yaml:"trash"`
 }
 
 type App struct {
@@ -54,6 +58,139 @@ type CORSConfig struct {
 	AllowCredentials bool     `yaml:"allow_credentials"`
 }
 
+type Auth struct {
+	// How often expired sessions are purged from the database.
+	SessionCleanupMinutes int `yaml:"session_cleanup_minutes"`
+	// Kid of the key new tokens are signed with. To rotate, add the new key,
+	// point this at it and keep the old key listed until its tokens expire.
+	ActiveKeyId string       `yaml:"active_key_id"`
+	SigningKeys []SigningKey `yaml:"signing_keys"`
+	// Accounts promoted to the admin role on startup.
+	AdminUsernames  []string        `yaml:"admin_usernames"`
+	Lockout         Lockout         `yaml:"lockout"`
+	PasswordPolicy  PasswordPolicy  `yaml:"password_policy"`
+	PasswordHashing PasswordHashing `yaml:"password_hashing"`
+	// Name authenticator apps show next to TOTP codes.
+	MfaIssuer string `yaml:"mfa_issuer"`
+	// Page of the web client that takes a reset token as ?token= and posts
+	// it to /ResetPassword; and how long reset links stay valid.
+	PasswordResetURL     string `yaml:"password_reset_url"`
+	PasswordResetMinutes int    `yaml:"password_reset_minutes"`
+	// Same for email verification links, plus the wait between resends.
+	EmailVerificationURL      string `yaml:"email_verification_url"`
+	EmailVerificationHours    int    `yaml:"email_verification_hours"`
+	VerificationResendSeconds int    `yaml:"verification_resend_seconds"`
+	// OpenID Connect providers users can sign in with at
+	// /OidcLogin/<name>, and the page the browser lands on afterwards.
+	OIDCProviders    []OIDCProvider `yaml:"oidc_providers"`
+	OIDCPostLoginURL string         `yaml:"oidc_post_login_url"`
+	// Hours a DELETE /Account can still be cancelled before the account
+	// and its data are removed; 0 deletes immediately.
+	AccountDeletionGraceHours int `yaml:"account_deletion_grace_hours"`
+	// Backend services allowed to call /oauth/introspect and /oauth/revoke.
+	OAuthClients []OAuthClient `yaml:"oauth_clients"`
+}
+
+// OAuthClient is another backend service validating this API's tokens.
+type OAuthClient struct {
+	ClientID string `yaml:"client_id"`
+	// The secret can come from OAUTH_<CLIENT_ID>_CLIENT_SECRET instead; at
+	// least 16 characters.
+	ClientSecret string `yaml:"client_secret"`
+}
+
+type OIDCProvider struct {
+	Name   string `yaml:"name"`
+	Issuer string `yaml:"issuer"`
+	// The secret can come from OIDC_<NAME>_CLIENT_SECRET instead.
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+	// Must be this service's /api/v1/OidcCallback/<name>, as registered
+	// with the provider.
+	RedirectURL string   `yaml:"redirect_url"`
+	Scopes      []string `yaml:"scopes"`
+	// Create accounts for provider users who don't have one yet.
+	AllowSignup bool `yaml:"allow_signup"`
+}
+
+// Mail picks how outgoing mail is delivered: "log" (the default) writes it
+// to the application log, "file" drops .eml files in Dir, "smtp" sends it.
+// Only local-development may use log or file.
+type Mail struct {
+	Sender       string `yaml:"sender"`
+	From         string `yaml:"from"`
+	Dir          string `yaml:"dir"`
+	SMTPHost     string `yaml:"smtp_host"`
+	SMTPPort     string `yaml:"smtp_port"`
+	SMTPUsername string `yaml:"smtp_username"`
+	SMTPPassword string `yaml:"smtp_password"`
+}
+
+// Trash is where deleted lists and tasks wait, restorable, for
+// RetentionDays (30 if unset) before they are purged for good.
+type Trash struct {
+	RetentionDays int `yaml:"retention_days"`
+}
+
+// Lockout throttles failed logins; zero values fall back to the defaults in
+// authentication.DefaultLockoutPolicy.
+type Lockout struct {
+	// Failures before a username is locked, and for how long.
+	MaxFailures    int `yaml:"max_failures"`
+	LockoutMinutes int `yaml:"lockout_minutes"`
+	// Backoff between failed attempts starts here and doubles.
+	BaseDelaySeconds int `yaml:"base_delay_seconds"`
+	MaxDelayMinutes  int `yaml:"max_delay_minutes"`
+	// Failures from one address before it is throttled too.
+	IPMaxFailures int `yaml:"ip_max_failures"`
+	// Failures older than this no longer count.
+	FailureWindowMinutes int `yaml:"failure_window_minutes"`
+}
+
+// PasswordPolicy is checked whenever a password is set; zero lengths fall
+// back to authentication.DefaultPasswordPolicy (8 to 72).
+type PasswordPolicy struct {
+	MinLength int `yaml:"min_length"`
+	// In bytes; capped at what the hasher uses, 72 for bcrypt.
+	MaxLength     int  `yaml:"max_length"`
+	RequireUpper  bool `yaml:"require_uppercase"`
+	RequireLower  bool `yaml:"require_lowercase"`
+	RequireDigit  bool `yaml:"require_digit"`
+	RequireSymbol bool `yaml:"require_symbol"`
+	// Passwords containing the username are refused unless this is set.
+	AllowUsername bool `yaml:"allow_username"`
+	// Directory of SHA-1 range files (<5 hex prefix>[.txt] holding
+	// SUFFIX:COUNT lines, as the Have I Been Pwned downloader writes them).
+	// Empty turns the breached-password check off.
+	BreachedPasswordsDir string `yaml:"breached_passwords_dir"`
+}
+
+// PasswordHashing picks how new password hashes are made: "argon2id" (the
+// default) or "bcrypt". Zero parameters keep the defaults. Stored hashes
+// made differently, or with weaker parameters, are redone at the next
+// login.
+type PasswordHashing struct {
+	Algorithm string `yaml:"algorithm"`
+	// argon2id memory in KiB, passes and lanes.
+	Argon2MemoryKiB   uint32 `yaml:"argon2_memory_kib"`
+	Argon2Iterations  uint32 `yaml:"argon2_iterations"`
+	Argon2Parallelism uint8  `yaml:"argon2_parallelism"`
+	BcryptCost        int    `yaml:"bcrypt_cost"`
+}
+
+type SigningKey struct {
+	Kid string `yaml:"kid"`
+	// RS256 or EdDSA
+	Algorithm      string `yaml:"algorithm"`
+	PrivateKeyFile string `yaml:"private_key_file"`
+	// Instead of private_key_file: the environment variable holding the
+	// private key PEM, e.g. one set with `fly secrets set`.
+	PrivateKeyEnv string `yaml:"private_key_env"`
+	PublicKeyFile string `yaml:"public_key_file"`
+	// Optional; the key stops verifying (and leaves the JWKS) after this.
+	VerifyUntil time.Time `yaml:"verify_until"`
+}
+
 func readConfigFile(filename string) (*Config, error) {
 	file, err := os.Open(filename)
 	if err != nil {
@@ -114,4 +251,31 @@ func applyEnvOverrides(config *Config) {
 		}
 		config.CORSConfig.AllowedOrigins = trimmed
 	}
+	// Kept out of the config file, like the database password.
+	if password := os.Getenv("SMTP_PASSWORD"); password != "" {
+		config.Mail.SMTPPassword = password
+	}
+	// The provider is picked per deployment, so its settings can come from
+	// secrets too.
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		config.Mail.SMTPHost = host
+	}
+	if username := os.Getenv("SMTP_USERNAME"); username != "" {
+		config.Mail.SMTPUsername = username
+	}
+	if from := os.Getenv("MAIL_FROM"); from != "" {
+		config.Mail.From = from
+	}
+	for i, provider := range config.Auth.OIDCProviders {
+		name := strings.ToUpper(strings.ReplaceAll(provider.Name, "-", "_"))
+		if secret := os.Getenv("OIDC_" + name + "_CLIENT_SECRET"); secret != "" {
+			config.Auth.OIDCProviders[i].ClientSecret = secret
+		}
+	}
+	for i, client := range config.Auth.OAuthClients {
+		name := strings.ToUpper(strings.ReplaceAll(client.ClientID, "-", "_"))
+		if secret := os.Getenv("OAUTH_" + name + "_CLIENT_SECRET"); secret != "" {
+			config.Auth.OAuthClients[i].ClientSecret = secret
+		}
+	}
 }
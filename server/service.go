@@ -3,8 +3,12 @@ package server
 import (
 	"os/exec"
 	"runtime"
+	"time"
+	auth "todo-web-api/authentication"
+	authz "todo-web-api/authorization"
 	app "todo-web-api/controllers"
 	"todo-web-api/loggerutils"
+	"todo-web-api/mailer"
 	"todo-web-api/middleware"
 	store "todo-web-api/storage"
 
@@ -15,6 +19,7 @@ import (
 	"github.com/sirupsen/logrus"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type Service struct {
@@ -31,6 +36,22 @@ func NewService(c *Config, l *loggerutils.LogUtil) *Service {
 
 func (s *Service) Start(r *gin.Engine) {
 	s.connectToSQL()
+	s.configureSigningKeys()
+	s.configureLockout()
+	s.configurePasswordHasher()
+	s.configurePasswordPolicy()
+	auth.ConfigureMfa(s.config.Auth.MfaIssuer)
+	auth.ConfigurePasswordReset(s.config.Auth.PasswordResetURL, time.Duration(s.config.Auth.PasswordResetMinutes)*time.Minute)
+	auth.ConfigureEmailVerification(s.config.Auth.EmailVerificationURL,
+		time.Duration(s.config.Auth.EmailVerificationHours)*time.Hour,
+		time.Duration(s.config.Auth.VerificationResendSeconds)*time.Second)
+	s.configureMailer()
+	s.configureOIDC()
+	s.configureOAuthClients()
+	auth.ConfigureAccountDeletion(time.Duration(s.config.Auth.AccountDeletionGraceHours) * time.Hour)
+	s.startSessionCleanup()
+	s.startTrashPurge()
+	s.promoteAdmins()
 	s.corsConfiguration(r)
 	if s.config.Swagger.Enabled {
 		s.swaggerSetup(r)
@@ -57,6 +78,204 @@ func (s *Service) connectToSQL() {
 	Db.Connect(dbConfigs.Username, dbConfigs.Password, dbConfigs.Host, dbConfigs.Port, dbConfigs.Name)
 }
 
+func (s *Service) configureSigningKeys() {
+	authConfig := s.config.Auth
+	if len(authConfig.SigningKeys) == 0 {
+		// Without configured keys tokens are signed with an ephemeral key,
+		// which breaks as soon as there's a restart or a second instance.
+		if s.config.App.Environment != "local-development" {
+			s.logger.WithFields(logrus.Fields{"Error": "No JWT signing keys configured"}).Fatal("auth.signing_keys is required")
+		}
+		return
+	}
+
+	keys := make([]auth.KeyConfig, 0, len(authConfig.SigningKeys))
+	for _, key := range authConfig.SigningKeys {
+		keys = append(keys, auth.KeyConfig{
+			Kid:            key.Kid,
+			Algorithm:      key.Algorithm,
+			PrivateKeyFile: key.PrivateKeyFile,
+			PrivateKeyEnv:  key.PrivateKeyEnv,
+			PublicKeyFile:  key.PublicKeyFile,
+			VerifyUntil:    key.VerifyUntil,
+		})
+	}
+	if err := auth.ConfigureKeys(keys, authConfig.ActiveKeyId); err != nil {
+		s.logger.WithFields(logrus.Fields{"Error": "Unable to load JWT signing keys"}).Fatal(err.Error())
+	}
+}
+
+func (s *Service) configureLockout() {
+	lockout := s.config.Auth.Lockout
+	auth.ConfigureLockout(auth.LockoutPolicy{
+		MaxFailures:     lockout.MaxFailures,
+		LockoutDuration: time.Duration(lockout.LockoutMinutes) * time.Minute,
+		BaseDelay:       time.Duration(lockout.BaseDelaySeconds) * time.Second,
+		MaxDelay:        time.Duration(lockout.MaxDelayMinutes) * time.Minute,
+		IPMaxFailures:   lockout.IPMaxFailures,
+		FailureWindow:   time.Duration(lockout.FailureWindowMinutes) * time.Minute,
+	})
+}
+
+func (s *Service) configurePasswordHasher() {
+	hashing := s.config.Auth.PasswordHashing
+	switch hashing.Algorithm {
+	case "", "argon2id":
+		hasher := auth.DefaultArgon2idHasher()
+		if hashing.Argon2MemoryKiB > 0 {
+			hasher.Memory = hashing.Argon2MemoryKiB
+		}
+		if hashing.Argon2Iterations > 0 {
+			hasher.Iterations = hashing.Argon2Iterations
+		}
+		if hashing.Argon2Parallelism > 0 {
+			hasher.Parallelism = hashing.Argon2Parallelism
+		}
+		auth.ConfigurePasswordHasher(hasher)
+	case "bcrypt":
+		cost := hashing.BcryptCost
+		if cost <= 0 {
+			cost = bcrypt.DefaultCost
+		}
+		auth.ConfigurePasswordHasher(&auth.BcryptHasher{Cost: cost})
+	default:
+		s.logger.WithFields(logrus.Fields{"Error": "Unable to configure password hashing"}).Fatal("unknown auth.password_hashing.algorithm " + hashing.Algorithm)
+	}
+}
+
+func (s *Service) configurePasswordPolicy() {
+	policy := s.config.Auth.PasswordPolicy
+	err := auth.ConfigurePasswordPolicy(auth.PasswordPolicy{
+		MinLength:            policy.MinLength,
+		MaxLength:            policy.MaxLength,
+		RequireUpper:         policy.RequireUpper,
+		RequireLower:         policy.RequireLower,
+		RequireDigit:         policy.RequireDigit,
+		RequireSymbol:        policy.RequireSymbol,
+		AllowUsername:        policy.AllowUsername,
+		BreachedPasswordsDir: policy.BreachedPasswordsDir,
+	})
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{"Error": "Unable to configure password policy"}).Fatal(err.Error())
+	}
+}
+
+func (s *Service) configureMailer() {
+	mail := s.config.Mail
+	err := mailer.Configure(mailer.Config{
+		Sender:       mail.Sender,
+		From:         mail.From,
+		Dir:          mail.Dir,
+		SMTPHost:     mail.SMTPHost,
+		SMTPPort:     mail.SMTPPort,
+		SMTPUsername: mail.SMTPUsername,
+		SMTPPassword: mail.SMTPPassword,
+		AllowLocal:   s.config.App.Environment == "local-development",
+	})
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{"Error": "Unable to configure mail sender"}).Fatal(err.Error())
+	}
+}
+
+func (s *Service) configureOIDC() {
+	providers := make([]auth.OIDCProviderConfig, 0, len(s.config.Auth.OIDCProviders))
+	for _, provider := range s.config.Auth.OIDCProviders {
+		providers = append(providers, auth.OIDCProviderConfig{
+			Name:         provider.Name,
+			Issuer:       provider.Issuer,
+			ClientID:     provider.ClientID,
+			ClientSecret: provider.ClientSecret,
+			RedirectURL:  provider.RedirectURL,
+			Scopes:       provider.Scopes,
+			AllowSignup:  provider.AllowSignup,
+		})
+	}
+	if err := auth.ConfigureOIDC(providers, s.config.Auth.OIDCPostLoginURL); err != nil {
+		s.logger.WithFields(logrus.Fields{"Error": "Unable to configure OIDC providers"}).Fatal(err.Error())
+	}
+}
+
+func (s *Service) configureOAuthClients() {
+	clients := make([]auth.OAuthClient, 0, len(s.config.Auth.OAuthClients))
+	for _, client := range s.config.Auth.OAuthClients {
+		clients = append(clients, auth.OAuthClient{
+			ClientID:     client.ClientID,
+			ClientSecret: client.ClientSecret,
+		})
+	}
+	if err := auth.ConfigureOAuthClients(clients); err != nil {
+		s.logger.WithFields(logrus.Fields{"Error": "Unable to configure OAuth clients"}).Fatal(err.Error())
+	}
+}
+
+// startSessionCleanup purges expired sessions, access tokens and one-time
+// tokens and stale login attempt counters in the background so none of those tables grows
+// without bound. Accounts whose deletion grace period is over go too.
+func (s *Service) startSessionCleanup() {
+	minutes := s.config.Auth.SessionCleanupMinutes
+	if minutes <= 0 {
+		minutes = 15
+	}
+	auth.PurgeExpiredSessions()
+	auth.PurgeStaleLoginAttempts()
+	auth.PurgeExpiredAccessTokens()
+	auth.PurgeExpiredOneTimeTokens()
+	auth.PurgeScheduledAccountDeletions()
+	auth.StartSessionCleanup(time.Duration(minutes) * time.Minute)
+}
+
+// startTrashPurge deletes lists and tasks for good once they have been in
+// the trash for the retention period, at startup and then every hour.
+func (s *Service) startTrashPurge() {
+	days := s.config.Trash.RetentionDays
+	if days <= 0 {
+		days = 30
+	}
+	retention := time.Duration(days) * 24 * time.Hour
+	s.purgeTrash(retention)
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			s.purgeTrash(retention)
+		}
+	}()
+}
+
+func (s *Service) purgeTrash(retention time.Duration) {
+	before := time.Now().Add(-retention)
+	tasks, err := store.TaskManager.PurgeDeletedTasks(before)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{"LoggerName": "TrashPurge"}).Error(err.Error())
+	}
+	lists, err := store.ListManager.PurgeDeletedLists(before)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{"LoggerName": "TrashPurge"}).Error(err.Error())
+	}
+	if tasks > 0 || lists > 0 {
+		s.logger.WithFields(logrus.Fields{"LoggerName": "TrashPurge", "Tasks": tasks, "Lists": lists}).Info("trash purged")
+	}
+}
+
+// promoteAdmins gives the accounts listed in auth.admin_usernames the admin
+// role, which is how the first admin gets created.
+func (s *Service) promoteAdmins() {
+	for _, username := range s.config.Auth.AdminUsernames {
+		user, err := store.UserManager.FindExistingAccount(username, "")
+		if err != nil {
+			s.logger.WithFields(logrus.Fields{"Error": "Unable to promote admin", "Username": username}).Warn(err.Error())
+			continue
+		}
+		if user.Role == authz.RoleAdmin {
+			continue
+		}
+		user.Role = authz.RoleAdmin
+		if _, err := store.UserManager.UpdateUser(user); err != nil {
+			s.logger.WithFields(logrus.Fields{"Error": "Unable to promote admin", "Username": username}).Error(err.Error())
+		}
+	}
+}
+
 func (s *Service) corsConfiguration(r *gin.Engine) {
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     s.config.CORSConfig.AllowedOrigins,
@@ -69,6 +288,17 @@ func (s *Service) corsConfiguration(r *gin.Engine) {
 
 // registerRoutes mounts the API routes under /api/v1, independent of Swagger.
 func (s *Service) registerRoutes(r *gin.Engine) {
+	// Public keys for services verifying our tokens; served at the
+	// conventional location rather than under /api/v1.
+	r.GET("/.well-known/jwks.json", app.JWKS)
+	// Token introspection (RFC 7662) and revocation (RFC 7009) for other
+	// backend services, authenticated with their client credentials.
+	oauth := r.Group("/oauth", middleware.RequestIDMiddleware(), middleware.RequireOAuthClient())
+	{
+		oauth.POST("/introspect", app.Introspect)
+		oauth.POST("/revoke", app.Revoke)
+	}
+
 	v1 := r.Group("/api/v1")
 	{
 		eg := v1.Group("/")
@@ -107,19 +337,66 @@ func (s *Service) RouteSetup(r *gin.RouterGroup) {
 		v1.POST("/Login", middleware.RequestIDMiddleware(), app.Login)
 		v1.POST("/Register", middleware.RequestIDMiddleware(), app.Register)
 		v1.POST("/RefreshToken", middleware.RequestIDMiddleware(), app.RefreshToken)
+		v1.POST("/LoginMfa", middleware.RequestIDMiddleware(), app.LoginMfa)
+		v1.POST("/ForgotPassword", middleware.RequestIDMiddleware(), app.ForgotPassword)
+		v1.POST("/ResetPassword", middleware.RequestIDMiddleware(), app.ResetPassword)
+		v1.POST("/VerifyEmail", middleware.RequestIDMiddleware(), app.VerifyEmail)
+		v1.GET("/OidcLogin/:provider", middleware.RequestIDMiddleware(), app.OidcLogin)
+		v1.GET("/OidcCallback/:provider", middleware.RequestIDMiddleware(), app.OidcCallback)
 	}
 
-	auth := r.Group("/", middleware.RequestIDMiddleware(), middleware.AuthMiddleware())
+	auth := r.Group("/", middleware.RequestIDMiddleware(), middleware.AuthMiddleware(), middleware.RequireCSRF())
 	{
-		auth.GET("/GetUser/:id", app.GetUserById)
-		auth.POST("/CreateList/:id", app.CreateListForUser)
-		auth.GET("/GetList/:userid", app.GetListByUserId)
-		auth.DELETE("/DeleteList/:id", app.DeleteList)
-		auth.POST("/CreateTask/:listid", app.AddTaskToList)
-		auth.DELETE("/DeleteTask/:id", app.DeleteTask)
-		auth.PUT("/UpdateTask/:id", app.UpdateTask)
-		auth.PUT("/TaskCompleted/:id", app.ChangeStatus)
+		auth.GET("/CsrfToken", app.GetCsrfToken)
+		auth.GET("/GetUser/:id", middleware.RequirePermission(authz.PermAccountRead), middleware.RequireOwner(authz.UserOwner, "id"), app.GetUserById)
+		auth.POST("/CreateList/:id", middleware.RequirePermission(authz.PermListsWrite), middleware.RequireOwner(authz.UserOwner, "id"), app.CreateListForUser)
+		auth.GET("/Lists", middleware.RequirePermission(authz.PermListsRead), app.GetLists)
+		auth.GET("/GetList/:userid", middleware.RequirePermission(authz.PermListsRead), middleware.RequireOwner(authz.UserOwner, "userid"), app.GetListByUserId)
+		auth.PUT("/UpdateList/:id", middleware.RequirePermission(authz.PermListsWrite), middleware.RequireListRole(authz.ListById, "id", authz.ListRoleEditor), app.UpdateList)
+		auth.PUT("/ListArchived/:id", middleware.RequirePermission(authz.PermListsWrite), middleware.RequireListRole(authz.ListById, "id", authz.ListRoleOwner), app.SetListArchived)
+		auth.DELETE("/DeleteList/:id", middleware.RequirePermission(authz.PermListsWrite), middleware.RequireListRole(authz.ListById, "id", authz.ListRoleOwner), app.DeleteList)
+		auth.GET("/ListMembers/:id", middleware.RequirePermission(authz.PermListsRead), middleware.RequireListRole(authz.ListById, "id", authz.ListRoleViewer), app.GetListMembers)
+		auth.POST("/ListMembers/:id", middleware.RequirePermission(authz.PermListsWrite), middleware.RequireListRole(authz.ListById, "id", authz.ListRoleOwner), app.InviteListMember)
+		auth.PUT("/ListMembers/:id/:userid", middleware.RequirePermission(authz.PermListsWrite), middleware.RequireListRole(authz.ListById, "id", authz.ListRoleOwner), app.UpdateListMember)
+		auth.DELETE("/ListMembers/:id/:userid", middleware.RequirePermission(authz.PermListsWrite), middleware.RequireListRole(authz.ListById, "id", authz.ListRoleViewer), app.RemoveListMember)
+		auth.POST("/CloneList/:id", middleware.RequirePermission(authz.PermListsWrite), middleware.RequireListRole(authz.ListById, "id", authz.ListRoleViewer), app.CloneList)
+		auth.POST("/SaveListAsTemplate/:id", middleware.RequirePermission(authz.PermListsWrite), middleware.RequireListRole(authz.ListById, "id", authz.ListRoleViewer), app.SaveListAsTemplate)
+		auth.GET("/ListTemplates", middleware.RequirePermission(authz.PermListsRead), app.GetListTemplates)
+		auth.DELETE("/ListTemplates/:id", middleware.RequirePermission(authz.PermListsWrite), middleware.RequireOwner(authz.TemplateOwner, "id"), app.DeleteListTemplate)
+		auth.POST("/CreateListFromTemplate/:id", middleware.RequirePermission(authz.PermListsWrite), middleware.RequireOwner(authz.TemplateOwner, "id"), app.CreateListFromTemplate)
+		auth.GET("/Invitations", middleware.RequirePermission(authz.PermListsRead), app.GetInvitations)
+		auth.POST("/AcceptInvitation/:id", middleware.RequirePermission(authz.PermListsWrite), app.AcceptInvitation)
+		auth.POST("/DeclineInvitation/:id", middleware.RequirePermission(authz.PermListsWrite), app.DeclineInvitation)
+		auth.POST("/CreateTask/:listid", middleware.RequirePermission(authz.PermTasksWrite), middleware.RequireListRole(authz.ListById, "listid", authz.ListRoleEditor), app.AddTaskToList)
+		auth.DELETE("/DeleteTask/:id", middleware.RequirePermission(authz.PermTasksWrite), middleware.RequireListRole(authz.ListOfTask, "id", authz.ListRoleEditor), app.DeleteTask)
+		auth.PUT("/UpdateTask/:id", middleware.RequirePermission(authz.PermTasksWrite), middleware.RequireListRole(authz.ListOfTask, "id", authz.ListRoleEditor), app.UpdateTask)
+		auth.PUT("/TaskCompleted/:id", middleware.RequirePermission(authz.PermTasksWrite), middleware.RequireListRole(authz.ListOfTask, "id", authz.ListRoleEditor), app.ChangeStatus)
+		auth.GET("/DueTasks", middleware.RequirePermission(authz.PermListsRead), app.GetDueTasks)
+		auth.GET("/Trash", middleware.RequirePermission(authz.PermListsRead), app.GetTrash)
+		auth.POST("/RestoreList/:id", middleware.RequirePermission(authz.PermListsWrite), middleware.RequireOwner(authz.TrashedListOwner, "id"), app.RestoreList)
+		auth.POST("/RestoreTask/:id", middleware.RequirePermission(authz.PermTasksWrite), middleware.RequireListRole(authz.ListOfTrashedTask, "id", authz.ListRoleEditor), app.RestoreTask)
 		auth.POST("/Logout", app.Logout)
+		auth.GET("/Sessions", middleware.RequirePermission(authz.PermAccountRead), app.GetSessions)
+		auth.DELETE("/Sessions/:id", middleware.RequirePermission(authz.PermAccountWrite), app.RevokeSession)
+		auth.GET("/SecurityEvents", middleware.RequirePermission(authz.PermAccountRead), app.GetSecurityEvents)
+		auth.GET("/AccessTokens", middleware.RequirePermission(authz.PermAccountRead), app.GetAccessTokens)
+		auth.POST("/AccessTokens", middleware.RequirePermission(authz.PermAccountWrite), app.CreateAccessToken)
+		auth.DELETE("/AccessTokens/:id", middleware.RequirePermission(authz.PermAccountWrite), app.RevokeAccessToken)
+		auth.POST("/EnrollMfa", middleware.RequirePermission(authz.PermAccountWrite), app.EnrollMfa)
+		auth.POST("/ConfirmMfa", middleware.RequirePermission(authz.PermAccountWrite), app.ConfirmMfa)
+		auth.POST("/DisableMfa", middleware.RequirePermission(authz.PermAccountWrite), app.DisableMfa)
+		auth.PUT("/ChangePassword", middleware.RequirePermission(authz.PermAccountWrite), app.ChangePassword)
+		auth.POST("/ResendVerification", middleware.RequirePermission(authz.PermAccountWrite), app.ResendVerification)
+		auth.DELETE("/Account", middleware.RequirePermission(authz.PermAccountWrite), app.DeleteAccount)
+		auth.POST("/CancelAccountDeletion", middleware.RequirePermission(authz.PermAccountWrite), app.CancelAccountDeletion)
+	}
+
+	admin := r.Group("/admin", middleware.RequestIDMiddleware(), middleware.AuthMiddleware(), middleware.RequireCSRF())
+	{
+		admin.GET("/Users", middleware.RequirePermission(authz.PermUsersRead), app.AdminGetUsers)
+		admin.PUT("/DisableUser/:id", middleware.RequirePermission(authz.PermUsersManage), app.AdminDisableUser)
+		admin.PUT("/EnableUser/:id", middleware.RequirePermission(authz.PermUsersManage), app.AdminEnableUser)
+		admin.POST("/ForceLogout/:id", middleware.RequirePermission(authz.PermUsersManage), app.AdminForceLogout)
 	}
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
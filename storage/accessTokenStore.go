@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"errors"
+	"time"
+	"todo-web-api/messages"
+	models "todo-web-api/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type AccessTokenStore struct {
+}
+
+func (T *AccessTokenStore) CreateAccessToken(token *models.PersonalAccessToken) (ID int, err error) {
+	result := Context.Create(token)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "AccessTokenStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return 0, errors.New(messages.AccessTokenQueryInternalError)
+	}
+	return token.Id, nil
+}
+
+func (T *AccessTokenStore) GetAccessTokenByHash(tokenHash string) (*models.PersonalAccessToken, error) {
+	var token models.PersonalAccessToken
+	result := Context.Where("token_hash = ?", tokenHash).First(&token)
+	if result.Error != nil && errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, errors.New(messages.AccessTokenNotFoundInDb)
+	} else if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "AccessTokenStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return nil, errors.New(messages.AccessTokenQueryInternalError)
+	}
+	return &token, nil
+}
+
+func (T *AccessTokenStore) GetAccessTokensForUser(userId int) ([]models.PersonalAccessToken, error) {
+	var tokens []models.PersonalAccessToken
+	result := Context.Where("user_id = ?", userId).Order("created_at desc").Find(&tokens)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "AccessTokenStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return nil, errors.New(messages.AccessTokenQueryInternalError)
+	}
+	return tokens, nil
+}
+
+func (T *AccessTokenStore) TouchAccessToken(id int, usedAt time.Time) error {
+	result := Context.Model(&models.PersonalAccessToken{}).Where("id = ?", id).Update("last_used_at", usedAt)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "AccessTokenStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return errors.New(messages.AccessTokenQueryInternalError)
+	}
+	return nil
+}
+
+// Scoped to the owner, so one user can't revoke another's token by id
+func (T *AccessTokenStore) DeleteAccessToken(userId int, id int) (success bool, err error) {
+	result := Context.Where("id = ? AND user_id = ?", id, userId).Delete(&models.PersonalAccessToken{})
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "AccessTokenStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return false, errors.New(messages.AccessTokenQueryInternalError)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (T *AccessTokenStore) DeleteExpiredAccessTokens(now time.Time) (count int64, err error) {
+	result := Context.Where("expires_at < ?", now).Delete(&models.PersonalAccessToken{})
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "AccessTokenStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return 0, errors.New(messages.AccessTokenQueryInternalError)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"errors"
+	"todo-web-api/messages"
+	models "todo-web-api/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+type AuditEventStore struct {
+}
+
+func (A *AuditEventStore) CreateAuditEvent(event *models.AuditEvent) (ID int, err error) {
+	result := Context.Create(event)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "AuditEventStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return 0, errors.New(messages.AuditEventQueryInternalError)
+	}
+	return event.Id, nil
+}
+
+// Newest first
+func (A *AuditEventStore) GetAuditEventsForUser(userId int, limit int) ([]models.AuditEvent, error) {
+	var events []models.AuditEvent
+	result := Context.Where("user_id = ?", userId).Order("created_at desc, id desc").Limit(limit).Find(&events)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "AuditEventStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return nil, errors.New(messages.AuditEventQueryInternalError)
+	}
+	return events, nil
+}
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"time"
 	models "todo-web-api/models"
 	sqlite "todo-web-api/storagelite"
 )
@@ -8,6 +9,15 @@ import (
 var UserManager IUserManager
 var TaskManager ITaskManager
 var ListManager IListManager
+var SessionManager ISessionManager
+var LoginAttemptManager ILoginAttemptManager
+var RecoveryCodeManager IRecoveryCodeManager
+var AccessTokenManager IAccessTokenManager
+var OneTimeTokenManager IOneTimeTokenManager
+var ExternalIdentityManager IExternalIdentityManager
+var AuditEventManager IAuditEventManager
+var ListMemberManager IListMemberManager
+var ListTemplateManager IListTemplateManager
 var StoreManager IDatabase
 
 func ConfigureDb(useSQLite bool) {
@@ -23,6 +33,15 @@ func Sqlite() {
 	UserManager = &sqlite.UserStoreLite{}
 	TaskManager = &sqlite.TaskStoreLite{}
 	ListManager = &sqlite.ListStoreLite{}
+	SessionManager = &sqlite.SessionStoreLite{}
+	LoginAttemptManager = &sqlite.LoginAttemptStoreLite{}
+	RecoveryCodeManager = &sqlite.RecoveryCodeStoreLite{}
+	AccessTokenManager = &sqlite.AccessTokenStoreLite{}
+	OneTimeTokenManager = &sqlite.OneTimeTokenStoreLite{}
+	ExternalIdentityManager = &sqlite.ExternalIdentityStoreLite{}
+	AuditEventManager = &sqlite.AuditEventStoreLite{}
+	ListMemberManager = &sqlite.ListMemberStoreLite{}
+	ListTemplateManager = &sqlite.ListTemplateStoreLite{}
 	StoreManager = &sqlite.StoreManagerLite{}
 }
 
@@ -30,30 +49,140 @@ func SqlServer() {
 	UserManager = &UserStore{}
 	TaskManager = &TaskStore{}
 	ListManager = &ListStore{}
+	SessionManager = &SessionStore{}
+	LoginAttemptManager = &LoginAttemptStore{}
+	RecoveryCodeManager = &RecoveryCodeStore{}
+	AccessTokenManager = &AccessTokenStore{}
+	OneTimeTokenManager = &OneTimeTokenStore{}
+	ExternalIdentityManager = &ExternalIdentityStore{}
+	AuditEventManager = &AuditEventStore{}
+	ListMemberManager = &ListMemberStore{}
+	ListTemplateManager = &ListTemplateStore{}
 	StoreManager = &StoreDbManager{}
 }
 
 type IListManager interface {
 	CreateList(list *models.List) (ID int, err error)
-	DeleteList(id int) (success bool, err error)
+	UpdateList(list *models.List) (ID int, err error)
+	// GetListForUser skips archived lists.
 	GetListForUser(id int) (*models.List, error)
+	// GetListsForUser includes the lists shared with the user.
+	GetListsForUser(userId int, archived bool) ([]models.List, error)
 	GetList(id int) (*models.List, error)
+	// DeleteList moves the list and its tasks to the trash. The trash
+	// methods see only trashed lists, which the others never return.
+	DeleteList(id int) (success bool, err error)
+	GetTrashedList(id int) (*models.List, error)
+	GetTrashedListsForUser(userId int) ([]models.List, error)
+	RestoreList(id int) (success bool, err error)
+	// PurgeDeletedLists deletes lists trashed before the given time for
+	// good, with their tasks and memberships.
+	PurgeDeletedLists(before time.Time) (count int64, err error)
 }
 
 type ITaskManager interface {
 	CreateTask(task *models.Task, listId int) (ID int, err error)
-	DeleteTask(id int) (success bool, err error)
 	GetTask(id int) (*models.Task, error)
 	UpdateTask(task *models.Task) (ID int, err error)
+	GetTasksForList(listId int) ([]models.Task, error)
+	// GetDueTasksForUser returns the tasks due in [from, to) on the
+	// unarchived lists the user owns or is a member of; a zero from has no
+	// lower bound. openOnly leaves out completed tasks.
+	GetDueTasksForUser(userId int, from time.Time, to time.Time, openOnly bool) ([]models.Task, error)
+	// DeleteTask moves the task to the trash.
+	DeleteTask(id int) (success bool, err error)
+	GetTrashedTask(id int) (*models.Task, error)
+	// GetTrashedTasksForUser returns the tasks trashed on their own from
+	// the lists the user owns or edits; those trashed with their list come
+	// back with it.
+	GetTrashedTasksForUser(userId int) ([]models.Task, error)
+	RestoreTask(id int) (success bool, err error)
+	PurgeDeletedTasks(before time.Time) (count int64, err error)
 }
 
 type IUserManager interface {
 	CreateUser(user *models.User) (ID int, err error)
 	DeleteUser(id int) (success bool, err error)
 	GetUser(id int) (*models.User, error)
+	GetUsers(search string) ([]models.User, error)
+	UpdateUser(user *models.User) (ID int, err error)
 	FindExistingAccount(username string, password string) (*models.User, error)
+	GetUserByVerifiedEmail(email string) (*models.User, error)
+	AdvanceTOTPCounter(userId int, counter int64) (success bool, err error)
+	GetUsersDueForDeletion(now time.Time) ([]models.User, error)
+}
+
+type ISessionManager interface {
+	SaveSession(session *models.Session) (ID int, err error)
+	GetSession(sessionId string) (*models.Session, error)
+	GetSessionsForUser(userId int) ([]models.Session, error)
+	RotateSessionTokens(sessionId, currentRefreshToken, accessToken, refreshToken string, expiresAt time.Time) (success bool, err error)
+	DeleteSession(sessionId string) (success bool, err error)
+	DeleteSessionsForUser(userId int) (count int64, err error)
+	DeleteOtherSessions(userId int, keepSessionId string) (count int64, err error)
+	DeleteExpiredSessions(now time.Time) (count int64, err error)
+}
+
+type ILoginAttemptManager interface {
+	GetLoginAttempt(subject string) (*models.LoginAttempt, error)
+	RecordFailedAttempt(subject string, now time.Time, windowStart time.Time) (*models.LoginAttempt, error)
+	LockLoginAttempt(subject string, until time.Time) error
+	DeleteLoginAttempt(subject string) (success bool, err error)
+	DeleteStaleLoginAttempts(before time.Time) (count int64, err error)
+}
+
+type IRecoveryCodeManager interface {
+	ReplaceRecoveryCodes(userId int, codeHashes []string) error
+	UseRecoveryCode(userId int, codeHash string) (success bool, err error)
+	DeleteRecoveryCodes(userId int) (count int64, err error)
+}
+
+type IAccessTokenManager interface {
+	CreateAccessToken(token *models.PersonalAccessToken) (ID int, err error)
+	GetAccessTokenByHash(tokenHash string) (*models.PersonalAccessToken, error)
+	GetAccessTokensForUser(userId int) ([]models.PersonalAccessToken, error)
+	TouchAccessToken(id int, usedAt time.Time) error
+	DeleteAccessToken(userId int, id int) (success bool, err error)
+	DeleteExpiredAccessTokens(now time.Time) (count int64, err error)
+}
+
+type IOneTimeTokenManager interface {
+	SaveOneTimeToken(token *models.OneTimeToken) (ID int, err error)
+	ConsumeOneTimeToken(purpose string, tokenHash string, now time.Time) (*models.OneTimeToken, error)
+	GetLatestOneTimeToken(userId int, purpose string) (*models.OneTimeToken, error)
+	DeleteOneTimeTokens(userId int, purpose string) (count int64, err error)
+	DeleteExpiredOneTimeTokens(now time.Time) (count int64, err error)
+}
+
+type IExternalIdentityManager interface {
+	CreateExternalIdentity(identity *models.ExternalIdentity) (ID int, err error)
+	GetExternalIdentity(provider string, subject string) (*models.ExternalIdentity, error)
+	TouchExternalIdentity(id int, lastLoginAt time.Time) error
 }
 
 type IDatabase interface {
 	Connect(dbUser, dbPassword, dbHost, dbPort, dbName string)
 }
+
+// IAuditEventManager has no update or delete: audit events are append-only.
+type IAuditEventManager interface {
+	CreateAuditEvent(event *models.AuditEvent) (ID int, err error)
+	GetAuditEventsForUser(userId int, limit int) ([]models.AuditEvent, error)
+}
+
+type IListMemberManager interface {
+	CreateListMember(member *models.ListMember) (ID int, err error)
+	GetListMember(listId int, userId int) (*models.ListMember, error)
+	GetListMemberById(id int) (*models.ListMember, error)
+	GetListMembers(listId int) ([]models.ListMember, error)
+	GetListMembershipsForUser(userId int, accepted bool) ([]models.ListMember, error)
+	UpdateListMember(member *models.ListMember) (ID int, err error)
+	DeleteListMember(id int) (success bool, err error)
+}
+
+type IListTemplateManager interface {
+	CreateListTemplate(template *models.ListTemplate) (ID int, err error)
+	GetListTemplate(id int) (*models.ListTemplate, error)
+	GetListTemplatesForUser(userId int) ([]models.ListTemplate, error)
+	DeleteListTemplate(id int) (success bool, err error)
+}
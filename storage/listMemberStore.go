@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"errors"
+	"todo-web-api/messages"
+	models "todo-web-api/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type ListMemberStore struct {
+}
+
+func (L *ListMemberStore) CreateListMember(member *models.ListMember) (ID int, err error) {
+	result := Context.Create(member)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "ListMemberStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return 0, errors.New(messages.ListMemberQueryInternalError)
+	}
+	return member.Id, nil
+}
+
+// The membership of userId on listId, accepted or not.
+func (L *ListMemberStore) GetListMember(listId int, userId int) (*models.ListMember, error) {
+	var member models.ListMember
+	result := Context.Where("list_id = ? AND user_id = ?", listId, userId).First(&member)
+	return L.found(&member, result)
+}
+
+func (L *ListMemberStore) GetListMemberById(id int) (*models.ListMember, error) {
+	var member models.ListMember
+	result := Context.First(&member, id)
+	return L.found(&member, result)
+}
+
+// Everyone invited to the list, in invitation order.
+func (L *ListMemberStore) GetListMembers(listId int) ([]models.ListMember, error) {
+	var members []models.ListMember
+	result := Context.Where("list_id = ?", listId).Order("id").Find(&members)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "ListMemberStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return nil, errors.New(messages.ListMemberQueryInternalError)
+	}
+	return members, nil
+}
+
+// Either the user's accepted memberships or their pending invitations,
+// newest first.
+func (L *ListMemberStore) GetListMembershipsForUser(userId int, accepted bool) ([]models.ListMember, error) {
+	var members []models.ListMember
+	query := Context.Where("user_id = ?", userId)
+	if accepted {
+		query = query.Where("accepted_at IS NOT NULL")
+	} else {
+		query = query.Where("accepted_at IS NULL")
+	}
+	result := query.Order("id desc").Find(&members)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "ListMemberStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return nil, errors.New(messages.ListMemberQueryInternalError)
+	}
+	return members, nil
+}
+
+func (L *ListMemberStore) UpdateListMember(member *models.ListMember) (ID int, err error) {
+	result := Context.Save(member)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "ListMemberStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return 0, errors.New(messages.ListMemberQueryInternalError)
+	}
+	return member.Id, nil
+}
+
+func (L *ListMemberStore) DeleteListMember(id int) (success bool, err error) {
+	result := Context.Delete(&models.ListMember{}, id)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "ListMemberStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return false, errors.New(messages.ListMemberQueryInternalError)
+	}
+	if result.RowsAffected == 0 {
+		return false, errors.New(messages.ListMemberNotFoundInDb)
+	}
+	return true, nil
+}
+
+func (L *ListMemberStore) found(member *models.ListMember, result *gorm.DB) (*models.ListMember, error) {
+	if result.Error != nil && errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, errors.New(messages.ListMemberNotFoundInDb)
+	} else if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "ListMemberStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return nil, errors.New(messages.ListMemberQueryInternalError)
+	}
+	return member, nil
+}
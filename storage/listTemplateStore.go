@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"errors"
+	"todo-web-api/messages"
+	models "todo-web-api/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type ListTemplateStore struct {
+}
+
+// CreateListTemplate saves the template with its tasks.
+func (L *ListTemplateStore) CreateListTemplate(template *models.ListTemplate) (ID int, err error) {
+	result := Context.Create(template)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "ListTemplateStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return 0, errors.New(messages.ListTemplateQueryInternalError)
+	}
+	return template.Id, nil
+}
+
+// The template with its tasks, in the order they were saved.
+func (L *ListTemplateStore) GetListTemplate(id int) (*models.ListTemplate, error) {
+	var template models.ListTemplate
+	result := Context.Preload("Tasks", orderedTemplateTasks).First(&template, id)
+	if result.Error != nil && errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, errors.New(messages.ListTemplateNotFoundInDb)
+	} else if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "ListTemplateStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return nil, errors.New(messages.ListTemplateQueryInternalError)
+	}
+	return &template, nil
+}
+
+// The user's templates with their tasks, by title.
+func (L *ListTemplateStore) GetListTemplatesForUser(userId int) ([]models.ListTemplate, error) {
+	var templates []models.ListTemplate
+	result := Context.Where("user_id = ?", userId).Preload("Tasks", orderedTemplateTasks).Order("title, id").Find(&templates)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "ListTemplateStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return nil, errors.New(messages.ListTemplateQueryInternalError)
+	}
+	return templates, nil
+}
+
+// DeleteListTemplate deletes the template with its tasks. Lists created
+// from it are left alone.
+func (L *ListTemplateStore) DeleteListTemplate(id int) (success bool, err error) {
+	var deleted int64
+	err = Context.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("template_id = ?", id).Delete(&models.TemplateTask{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.ListTemplate{}, id)
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "ListTemplateStore",
+			"DbContext":  "mysql",
+		}).Error(err.Error())
+		return false, errors.New(messages.ListTemplateQueryInternalError)
+	}
+	if deleted == 0 {
+		return false, errors.New(messages.ListTemplateNotFoundInDb)
+	}
+	return true, nil
+}
+
+func orderedTemplateTasks(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"errors"
+	"time"
+	"todo-web-api/messages"
+	models "todo-web-api/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoginAttemptStore struct {
+}
+
+func (A *LoginAttemptStore) GetLoginAttempt(subject string) (*models.LoginAttempt, error) {
+	var attempt models.LoginAttempt
+	result := Context.Where("subject = ?", subject).First(&attempt)
+	if result.Error != nil && errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, errors.New(messages.LoginAttemptNotFoundInDb)
+	} else if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "LoginAttemptStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return nil, errors.New(messages.LoginAttemptQueryInternalError)
+	}
+	return &attempt, nil
+}
+
+// Bump the failure count in a single upsert so concurrent guesses can't
+// overwrite each other's increments. A count whose last failure is older
+// than windowStart starts over at one.
+func (A *LoginAttemptStore) RecordFailedAttempt(subject string, now time.Time, windowStart time.Time) (*models.LoginAttempt, error) {
+	attempt := models.LoginAttempt{Subject: subject, Failures: 1, LastFailedAt: now}
+	result := Context.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "subject"}},
+		// failures is assigned first so it still sees the old last_failed_at.
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "failures"},
+				Value: gorm.Expr("CASE WHEN last_failed_at < ? THEN 1 ELSE failures + 1 END", windowStart)},
+			{Column: clause.Column{Name: "last_failed_at"}, Value: now},
+		},
+	}).Create(&attempt)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "LoginAttemptStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return nil, errors.New(messages.LoginAttemptQueryInternalError)
+	}
+	return A.GetLoginAttempt(subject)
+}
+
+func (A *LoginAttemptStore) LockLoginAttempt(subject string, until time.Time) error {
+	result := Context.Model(&models.LoginAttempt{}).Where("subject = ?", subject).Update("locked_until", until)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "LoginAttemptStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return errors.New(messages.LoginAttemptQueryInternalError)
+	}
+	return nil
+}
+
+func (A *LoginAttemptStore) DeleteLoginAttempt(subject string) (success bool, err error) {
+	result := Context.Where("subject = ?", subject).Delete(&models.LoginAttempt{})
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "LoginAttemptStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return false, errors.New(messages.LoginAttemptQueryInternalError)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Delete counters that are neither locked nor recent enough to matter
+func (A *LoginAttemptStore) DeleteStaleLoginAttempts(before time.Time) (count int64, err error) {
+	result := Context.Where("last_failed_at < ? AND locked_until < ?", before, before).Delete(&models.LoginAttempt{})
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "LoginAttemptStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return 0, errors.New(messages.LoginAttemptQueryInternalError)
+	}
+	return result.RowsAffected, nil
+}
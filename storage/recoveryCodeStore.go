@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"errors"
+	"time"
+	"todo-web-api/messages"
+	models "todo-web-api/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeStore struct {
+}
+
+// Swap the user's codes for a fresh set in one transaction, so a failure
+// never leaves them with none
+func (R *RecoveryCodeStore) ReplaceRecoveryCodes(userId int, codeHashes []string) error {
+	err := Context.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userId).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]models.RecoveryCode, 0, len(codeHashes))
+		for _, hash := range codeHashes {
+			codes = append(codes, models.RecoveryCode{UserId: userId, CodeHash: hash})
+		}
+		return tx.Create(&codes).Error
+	})
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "RecoveryCodeStore",
+			"DbContext":  "mysql",
+		}).Error(err.Error())
+		return errors.New(messages.RecoveryCodeQueryInternalError)
+	}
+	return nil
+}
+
+// Mark a code used only if it is still unused, so it works exactly once
+func (R *RecoveryCodeStore) UseRecoveryCode(userId int, codeHash string) (success bool, err error) {
+	result := Context.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userId, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "RecoveryCodeStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return false, errors.New(messages.RecoveryCodeQueryInternalError)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (R *RecoveryCodeStore) DeleteRecoveryCodes(userId int) (count int64, err error) {
+	result := Context.Where("user_id = ?", userId).Delete(&models.RecoveryCode{})
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "RecoveryCodeStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return 0, errors.New(messages.RecoveryCodeQueryInternalError)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"errors"
+	"time"
+	"todo-web-api/messages"
+	models "todo-web-api/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type SessionStore struct {
+}
+
+func (S *SessionStore) SaveSession(session *models.Session) (ID int, err error) {
+	result := Context.Save(&session)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "SessionStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+	}
+	return session.Id, result.Error
+}
+
+func (S *SessionStore) GetSession(sessionId string) (*models.Session, error) {
+	var session models.Session
+	result := Context.Where("session_id = ?", sessionId).First(&session)
+	if result.Error != nil && errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, errors.New(messages.SessionNotFoundInDb)
+	} else if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "SessionStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return nil, errors.New(messages.SessionQueryInternalError)
+	}
+	return &session, nil
+}
+
+func (S *SessionStore) GetSessionsForUser(userId int) ([]models.Session, error) {
+	var sessions []models.Session
+	result := Context.Where("user_id = ?", userId).Order("created_at desc").Find(&sessions)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "SessionStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return nil, errors.New(messages.SessionQueryInternalError)
+	}
+	return sessions, nil
+}
+
+// Swap in a new token pair only if the session still holds
+// currentRefreshToken, so two requests can't both rotate the same token
+func (S *SessionStore) RotateSessionTokens(sessionId, currentRefreshToken, accessToken, refreshToken string, expiresAt time.Time) (success bool, err error) {
+	result := Context.Model(&models.Session{}).
+		Where("session_id = ? AND refresh_token = ?", sessionId, currentRefreshToken).
+		Updates(map[string]interface{}{
+			"access_token":  accessToken,
+			"refresh_token": refreshToken,
+			"expires_at":    expiresAt,
+			"last_used_at":  time.Now(),
+		})
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "SessionStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return false, errors.New("something went wrong while rotating session tokens")
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (S *SessionStore) DeleteSession(sessionId string) (success bool, err error) {
+	result := Context.Where("session_id = ?", sessionId).Delete(&models.Session{})
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "SessionStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return false, errors.New("something went wrong while deleting session")
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (S *SessionStore) DeleteSessionsForUser(userId int) (count int64, err error) {
+	result := Context.Where("user_id = ?", userId).Delete(&models.Session{})
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "SessionStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return 0, errors.New("something went wrong while deleting sessions")
+	}
+	return result.RowsAffected, nil
+}
+
+// Sign the user out everywhere except keepSessionId
+func (S *SessionStore) DeleteOtherSessions(userId int, keepSessionId string) (count int64, err error) {
+	result := Context.Where("user_id = ? AND session_id <> ?", userId, keepSessionId).Delete(&models.Session{})
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "SessionStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return 0, errors.New(messages.SessionQueryInternalError)
+	}
+	return result.RowsAffected, nil
+}
+
+// Delete sessions whose tokens have all expired
+func (S *SessionStore) DeleteExpiredSessions(now time.Time) (count int64, err error) {
+	result := Context.Where("expires_at < ?", now).Delete(&models.Session{})
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "SessionStore",
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return 0, errors.New("something went wrong while deleting expired sessions")
+	}
+	return result.RowsAffected, nil
+}
@@ -41,7 +41,42 @@ func (Db *StoreDbManager) Connect(dbUser, dbPassword, dbHost, dbPort, dbName str
 }
 
 func (Db *StoreDbManager) MigrateModels(db *gorm.DB) {
-	db.AutoMigrate(&models.Task{})
+	// Lists go before the tables with foreign keys to them.
 	db.AutoMigrate(&models.User{})
+	fillVerifiedEmails(db)
 	db.AutoMigrate(&models.List{})
+	deleteOrphans(db)
+	db.AutoMigrate(&models.Task{})
+	db.AutoMigrate(&models.Session{})
+	db.AutoMigrate(&models.LoginAttempt{})
+	db.AutoMigrate(&models.RecoveryCode{})
+	db.AutoMigrate(&models.PersonalAccessToken{})
+	db.AutoMigrate(&models.OneTimeToken{})
+	db.AutoMigrate(&models.ExternalIdentity{})
+	db.AutoMigrate(&models.AuditEvent{})
+	db.AutoMigrate(&models.ListMember{})
+	db.AutoMigrate(&models.ListTemplate{})
+	db.AutoMigrate(&models.TemplateTask{})
+}
+
+// fillVerifiedEmails copies addresses verified before VerifiedEmail existed.
+// If two accounts verified the same one, the older account keeps it.
+func fillVerifiedEmails(db *gorm.DB) {
+	var users []models.User
+	db.Where("email_verified = ? AND email <> '' AND verified_email IS NULL", true).Order("id").Find(&users)
+	for _, user := range users {
+		db.Model(&models.User{}).Where("id = ?", user.Id).Update("verified_email", user.Email)
+	}
+}
+
+// deleteOrphans removes tasks and list members whose list is gone. Lists
+// deleted before the foreign keys existed left them behind, and they would
+// keep the keys from being added. Lists in the trash still count.
+func deleteOrphans(db *gorm.DB) {
+	lists := db.Unscoped().Model(&models.List{}).Select("id")
+	for _, model := range []interface{}{&models.Task{}, &models.ListMember{}} {
+		if db.Migrator().HasTable(model) {
+			db.Unscoped().Where("list_id NOT IN (?)", lists).Delete(model)
+		}
+	}
 }
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"errors"
+	"time"
 	"todo-web-api/loggerutils"
 	"todo-web-api/messages"
 	models "todo-web-api/models"
@@ -41,6 +42,9 @@ func (U *UserStore) CreateUser(user *models.User) (ID int, err error) {
 	return user.Id, result.Error
 }
 
+// Delete the user together with their lists, tasks, sessions and
+// credentials in one transaction, so nothing is left orphaned and a failure
+// leaves the account as it was
 func (U *UserStore) DeleteUser(id int) (success bool, err error) {
 	var user models.User
 	result := Context.First(&user, id)
@@ -60,14 +64,34 @@ func (U *UserStore) DeleteUser(id int) (success bool, err error) {
 		return false, err
 	}
 
-	result = Context.Delete(&user)
-	if result.Error != nil {
-		err := errors.New("something went wrong while deleting User")
+	err = Context.Transaction(func(tx *gorm.DB) error {
+		// Unscoped, so lists and tasks in the trash go too rather than
+		// being trashed again.
+		lists := tx.Unscoped().Model(&models.List{}).Select("id").Where("user_id = ?", user.Id)
+		for _, model := range []interface{}{&models.Task{}, &models.ListMember{}} {
+			if err := tx.Unscoped().Where("list_id IN (?)", lists).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		templates := tx.Model(&models.ListTemplate{}).Select("id").Where("user_id = ?", user.Id)
+		if err := tx.Where("template_id IN (?)", templates).Delete(&models.TemplateTask{}).Error; err != nil {
+			return err
+		}
+		owned := []interface{}{&models.List{}, &models.ListMember{}, &models.ListTemplate{}, &models.Session{}, &models.RecoveryCode{},
+			&models.PersonalAccessToken{}, &models.OneTimeToken{}, &models.ExternalIdentity{}}
+		for _, model := range owned {
+			if err := tx.Unscoped().Where("user_id = ?", user.Id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&user).Error
+	})
+	if err != nil {
 		log.WithFields(logrus.Fields{
 			"LoggerName": loggerName,
 			"DbContext":  "mysql",
-		}).Error(result.Error.Error())
-		return false, err
+		}).Error(err.Error())
+		return false, errors.New("something went wrong while deleting User")
 	}
 	return true, nil
 }
@@ -113,3 +137,79 @@ func (U *UserStore) FindExistingAccount(username string, password string) (*mode
 	}
 	return &user, nil
 }
+
+// List users, optionally filtered by a username substring
+func (U *UserStore) GetUsers(search string) ([]models.User, error) {
+	var users []models.User
+	query := Context.Order("id")
+	if search != "" {
+		query = query.Where("username LIKE ?", "%"+search+"%")
+	}
+	result := query.Find(&users)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": loggerName,
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return nil, errors.New(messages.UserQueryInternalError)
+	}
+	return users, nil
+}
+
+func (U *UserStore) UpdateUser(user *models.User) (ID int, err error) {
+	result := Context.Save(&user)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": loggerName,
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return 0, errors.New("something went wrong while updating user")
+	}
+	return user.Id, nil
+}
+
+// The account holding a verified address; unverified ones aren't found
+func (U *UserStore) GetUserByVerifiedEmail(email string) (*models.User, error) {
+	var user models.User
+	result := Context.Where("verified_email = ?", email).First(&user)
+	if result.Error != nil && errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, errors.New(messages.UserNotFound)
+	} else if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": loggerName,
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return nil, errors.New(messages.UserQueryInternalError)
+	}
+	return &user, nil
+}
+
+// Accept a TOTP time step only if it is newer than the last one used, so
+// the same code can't be replayed
+func (U *UserStore) AdvanceTOTPCounter(userId int, counter int64) (success bool, err error) {
+	result := Context.Model(&models.User{}).
+		Where("id = ? AND totp_last_counter < ?", userId, counter).
+		Update("totp_last_counter", counter)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": loggerName,
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return false, errors.New(messages.UserQueryInternalError)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Users whose scheduled deletion is due by now
+func (U *UserStore) GetUsersDueForDeletion(now time.Time) ([]models.User, error) {
+	var users []models.User
+	result := Context.Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", now).Find(&users)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": loggerName,
+			"DbContext":  "mysql",
+		}).Error(result.Error.Error())
+		return nil, errors.New(messages.UserQueryInternalError)
+	}
+	return users, nil
+}
@@ -0,0 +1,38 @@
+package storagelite
+
+import (
+	"errors"
+	"todo-web-api/messages"
+	models "todo-web-api/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+type AuditEventStoreLite struct {
+}
+
+func (A *AuditEventStoreLite) CreateAuditEvent(event *models.AuditEvent) (ID int, err error) {
+	result := Context.Create(event)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "AuditEventStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return 0, errors.New(messages.AuditEventQueryInternalError)
+	}
+	return event.Id, nil
+}
+
+// Newest first
+func (A *AuditEventStoreLite) GetAuditEventsForUser(userId int, limit int) ([]models.AuditEvent, error) {
+	var events []models.AuditEvent
+	result := Context.Where("user_id = ?", userId).Order("created_at desc, id desc").Limit(limit).Find(&events)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "AuditEventStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return nil, errors.New(messages.AuditEventQueryInternalError)
+	}
+	return events, nil
+}
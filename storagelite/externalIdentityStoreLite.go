@@ -0,0 +1,53 @@
+package storagelite
+
+import (
+	"errors"
+	"time"
+	"todo-web-api/messages"
+	models "todo-web-api/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type ExternalIdentityStoreLite struct {
+}
+
+func (E *ExternalIdentityStoreLite) CreateExternalIdentity(identity *models.ExternalIdentity) (ID int, err error) {
+	result := Context.Create(identity)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "ExternalIdentityStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return 0, errors.New(messages.ExternalIdentityQueryInternalError)
+	}
+	return identity.Id, nil
+}
+
+func (E *ExternalIdentityStoreLite) GetExternalIdentity(provider string, subject string) (*models.ExternalIdentity, error) {
+	var identity models.ExternalIdentity
+	result := Context.Where("provider = ? AND subject = ?", provider, subject).First(&identity)
+	if result.Error != nil && errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, errors.New(messages.ExternalIdentityNotFoundInDb)
+	} else if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "ExternalIdentityStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return nil, errors.New(messages.ExternalIdentityQueryInternalError)
+	}
+	return &identity, nil
+}
+
+func (E *ExternalIdentityStoreLite) TouchExternalIdentity(id int, lastLoginAt time.Time) error {
+	result := Context.Model(&models.ExternalIdentity{}).Where("id = ?", id).Update("last_login_at", lastLoginAt)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "ExternalIdentityStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return errors.New(messages.ExternalIdentityQueryInternalError)
+	}
+	return nil
+}
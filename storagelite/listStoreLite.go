@@ -2,6 +2,7 @@ package storagelite
 
 import (
 	"errors"
+	"time"
 	"todo-web-api/loggerutils"
 	"todo-web-api/messages"
 	models "todo-web-api/models"
@@ -25,6 +26,18 @@ func (L *ListStoreLite) CreateList(list *models.List) (ID int, err error) {
 	return list.Id, result.Error
 }
 
+func (L *ListStoreLite) UpdateList(list *models.List) (ID int, err error) {
+	result := Context.Save(list)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "ListStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return 0, errors.New(messages.ListQueryInternalError)
+	}
+	return list.Id, nil
+}
+
 func (L *ListStoreLite) DeleteList(id int) (success bool, err error) {
 	var list models.List
 	result := Context.First(&list, id)
@@ -44,20 +57,118 @@ func (L *ListStoreLite) DeleteList(id int) (success bool, err error) {
 		return false, errors.New(errMsg)
 	}
 
-	result = Context.Delete(&list)
+	err = Context.Transaction(func(tx *gorm.DB) error {
+		return trashListWithTasks(tx, &list, time.Now())
+	})
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "ListStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(err)
+		return false, errors.New(messages.ListQueryInternalError)
+	}
+	return true, nil
+}
+
+// trashListWithTasks gives the list and its live tasks the same DeletedAt,
+// which is how RestoreList tells them from tasks trashed earlier on their
+// own. Memberships are left alone so a restored list is shared again.
+func trashListWithTasks(tx *gorm.DB, list *models.List, now time.Time) error {
+	if err := tx.Model(&models.Task{}).Where("list_id = ?", list.Id).Update("deleted_at", now).Error; err != nil {
+		return err
+	}
+	return tx.Model(list).Update("deleted_at", now).Error
+}
+
+// The trashed list with the given id.
+func (L *ListStoreLite) GetTrashedList(id int) (*models.List, error) {
+	var list models.List
+	result := Context.Unscoped().Where("deleted_at IS NOT NULL").First(&list, id)
+	if result.Error != nil && errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "ListStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return nil, errors.New(messages.ListNotFoundInDb)
+	} else if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "ListStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return nil, errors.New(messages.ListQueryInternalError)
+	}
+	return &list, nil
+}
+
+// The lists the user owns that are in the trash, most recently deleted
+// first. Only the owner can delete a list, so only they see it here.
+func (L *ListStoreLite) GetTrashedListsForUser(userId int) ([]models.List, error) {
+	var lists []models.List
+	result := Context.Unscoped().Where("user_id = ? AND deleted_at IS NOT NULL", userId).Order("deleted_at desc, id").Find(&lists)
 	if result.Error != nil {
 		log.WithFields(logrus.Fields{
 			"LoggerName": "ListStoreLite",
 			"DbContext":  "sqlite",
 		}).Error(result.Error)
+		return nil, errors.New(messages.ListQueryInternalError)
+	}
+	return lists, nil
+}
+
+// RestoreList takes the list out of the trash along with the tasks that
+// went in with it.
+func (L *ListStoreLite) RestoreList(id int) (success bool, err error) {
+	list, err := L.GetTrashedList(id)
+	if err != nil {
+		return false, err
+	}
+
+	err = Context.Transaction(func(tx *gorm.DB) error {
+		trashed := tx.Unscoped().Model(&models.Task{}).Where("list_id = ? AND deleted_at = ?", list.Id, list.DeletedAt.Time)
+		if err := trashed.Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(list).Update("deleted_at", nil).Error
+	})
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "ListStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(err)
 		return false, errors.New(messages.ListQueryInternalError)
 	}
 	return true, nil
 }
 
+// PurgeDeletedLists deletes the lists trashed before the given time with
+// their tasks and memberships. The foreign keys would cascade anyway;
+// deleting them here as well keeps databases created before the keys
+// existed free of orphans.
+func (L *ListStoreLite) PurgeDeletedLists(before time.Time) (count int64, err error) {
+	err = Context.Transaction(func(tx *gorm.DB) error {
+		lists := tx.Unscoped().Model(&models.List{}).Select("id").Where("deleted_at < ?", before)
+		for _, model := range []interface{}{&models.Task{}, &models.ListMember{}} {
+			if err := tx.Unscoped().Where("list_id IN (?)", lists).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		result := tx.Unscoped().Where("deleted_at < ?", before).Delete(&models.List{})
+		count = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "ListStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(err)
+		return 0, errors.New(messages.ListQueryInternalError)
+	}
+	return count, nil
+}
+
 func (L *ListStoreLite) GetListForUser(id int) (*models.List, error) {
 	var list models.List
-	result := Context.Where("user_id = ?", id).Preload("Tasks").First(&list)
+	result := Context.Where("user_id = ? AND archived_at IS NULL", id).Preload("Tasks").First(&list)
 	if result.Error != nil && errors.Is(result.Error, gorm.ErrRecordNotFound) {
 
 		errMsg := messages.ListNotFoundInDb
@@ -76,6 +187,28 @@ func (L *ListStoreLite) GetListForUser(id int) (*models.List, error) {
 	return &list, nil
 }
 
+// The user's own lists and those shared with them, in position order, with
+// their tasks; either the archived lists or the others.
+func (L *ListStoreLite) GetListsForUser(userId int, archived bool) ([]models.List, error) {
+	var lists []models.List
+	shared := Context.Model(&models.ListMember{}).Select("list_id").Where("user_id = ? AND accepted_at IS NOT NULL", userId)
+	query := Context.Where("user_id = ? OR id IN (?)", userId, shared)
+	if archived {
+		query = query.Where("archived_at IS NOT NULL")
+	} else {
+		query = query.Where("archived_at IS NULL")
+	}
+	result := query.Preload("Tasks").Order("position, id").Find(&lists)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "ListStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return nil, errors.New(messages.ListQueryInternalError)
+	}
+	return lists, nil
+}
+
 // Get List by Id
 func (L *ListStoreLite) GetList(id int) (*models.List, error) {
 	var list models.List
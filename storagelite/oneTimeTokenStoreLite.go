@@ -0,0 +1,93 @@
+package storagelite
+
+import (
+	"errors"
+	"time"
+	"todo-web-api/messages"
+	models "todo-web-api/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type OneTimeTokenStoreLite struct {
+}
+
+func (O *OneTimeTokenStoreLite) SaveOneTimeToken(token *models.OneTimeToken) (ID int, err error) {
+	result := Context.Create(token)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "OneTimeTokenStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return 0, errors.New(messages.OneTimeTokenQueryInternalError)
+	}
+	return token.Id, nil
+}
+
+// Mark the token used in the same statement that checks it is unused and
+// unexpired, so two requests can't both redeem it
+func (O *OneTimeTokenStoreLite) ConsumeOneTimeToken(purpose string, tokenHash string, now time.Time) (*models.OneTimeToken, error) {
+	result := Context.Model(&models.OneTimeToken{}).
+		Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", tokenHash, purpose, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "OneTimeTokenStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return nil, errors.New(messages.OneTimeTokenQueryInternalError)
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New(messages.OneTimeTokenNotFoundInDb)
+	}
+
+	var token models.OneTimeToken
+	if err := Context.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "OneTimeTokenStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(err)
+		return nil, errors.New(messages.OneTimeTokenQueryInternalError)
+	}
+	return &token, nil
+}
+
+func (O *OneTimeTokenStoreLite) GetLatestOneTimeToken(userId int, purpose string) (*models.OneTimeToken, error) {
+	var token models.OneTimeToken
+	result := Context.Where("user_id = ? AND purpose = ?", userId, purpose).Order("created_at desc").First(&token)
+	if result.Error != nil && errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, errors.New(messages.OneTimeTokenNotFoundInDb)
+	} else if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "OneTimeTokenStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return nil, errors.New(messages.OneTimeTokenQueryInternalError)
+	}
+	return &token, nil
+}
+
+func (O *OneTimeTokenStoreLite) DeleteOneTimeTokens(userId int, purpose string) (count int64, err error) {
+	result := Context.Where("user_id = ? AND purpose = ?", userId, purpose).Delete(&models.OneTimeToken{})
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "OneTimeTokenStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return 0, errors.New(messages.OneTimeTokenQueryInternalError)
+	}
+	return result.RowsAffected, nil
+}
+
+func (O *OneTimeTokenStoreLite) DeleteExpiredOneTimeTokens(now time.Time) (count int64, err error) {
+	result := Context.Where("expires_at < ?", now).Delete(&models.OneTimeToken{})
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "OneTimeTokenStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return 0, errors.New(messages.OneTimeTokenQueryInternalError)
+	}
+	return result.RowsAffected, nil
+}
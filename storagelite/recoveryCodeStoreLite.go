@@ -0,0 +1,64 @@
+package storagelite
+
+import (
+	"errors"
+	"time"
+	"todo-web-api/messages"
+	models "todo-web-api/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeStoreLite struct {
+}
+
+// Swap the user's codes for a fresh set in one transaction, so a failure
+// never leaves them with none
+func (R *RecoveryCodeStoreLite) ReplaceRecoveryCodes(userId int, codeHashes []string) error {
+	err := Context.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userId).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]models.RecoveryCode, 0, len(codeHashes))
+		for _, hash := range codeHashes {
+			codes = append(codes, models.RecoveryCode{UserId: userId, CodeHash: hash})
+		}
+		return tx.Create(&codes).Error
+	})
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "RecoveryCodeStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(err)
+		return errors.New(messages.RecoveryCodeQueryInternalError)
+	}
+	return nil
+}
+
+// Mark a code used only if it is still unused, so it works exactly once
+func (R *RecoveryCodeStoreLite) UseRecoveryCode(userId int, codeHash string) (success bool, err error) {
+	result := Context.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userId, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "RecoveryCodeStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return false, errors.New(messages.RecoveryCodeQueryInternalError)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (R *RecoveryCodeStoreLite) DeleteRecoveryCodes(userId int) (count int64, err error) {
+	result := Context.Where("user_id = ?", userId).Delete(&models.RecoveryCode{})
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "RecoveryCodeStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return 0, errors.New(messages.RecoveryCodeQueryInternalError)
+	}
+	return result.RowsAffected, nil
+}
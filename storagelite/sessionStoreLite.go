@@ -0,0 +1,124 @@
+package storagelite
+
+import (
+	"errors"
+	"time"
+	"todo-web-api/messages"
+	models "todo-web-api/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type SessionStoreLite struct {
+}
+
+func (S *SessionStoreLite) SaveSession(session *models.Session) (ID int, err error) {
+	result := Context.Save(&session)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "SessionStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+	}
+	return session.Id, result.Error
+}
+
+func (S *SessionStoreLite) GetSession(sessionId string) (*models.Session, error) {
+	var session models.Session
+	result := Context.Where("session_id = ?", sessionId).First(&session)
+	if result.Error != nil && errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, errors.New(messages.SessionNotFoundInDb)
+	} else if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "SessionStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return nil, errors.New(messages.SessionQueryInternalError)
+	}
+	return &session, nil
+}
+
+func (S *SessionStoreLite) GetSessionsForUser(userId int) ([]models.Session, error) {
+	var sessions []models.Session
+	result := Context.Where("user_id = ?", userId).Order("created_at desc").Find(&sessions)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "SessionStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return nil, errors.New(messages.SessionQueryInternalError)
+	}
+	return sessions, nil
+}
+
+// Swap in a new token pair only if the session still holds
+// currentRefreshToken, so two requests can't both rotate the same token
+func (S *SessionStoreLite) RotateSessionTokens(sessionId, currentRefreshToken, accessToken, refreshToken string, expiresAt time.Time) (success bool, err error) {
+	result := Context.Model(&models.Session{}).
+		Where("session_id = ? AND refresh_token = ?", sessionId, currentRefreshToken).
+		Updates(map[string]interface{}{
+			"access_token":  accessToken,
+			"refresh_token": refreshToken,
+			"expires_at":    expiresAt,
+			"last_used_at":  time.Now(),
+		})
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "SessionStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return false, errors.New(messages.SessionQueryInternalError)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (S *SessionStoreLite) DeleteSession(sessionId string) (success bool, err error) {
+	result := Context.Where("session_id = ?", sessionId).Delete(&models.Session{})
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "SessionStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return false, errors.New(messages.SessionQueryInternalError)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (S *SessionStoreLite) DeleteSessionsForUser(userId int) (count int64, err error) {
+	result := Context.Where("user_id = ?", userId).Delete(&models.Session{})
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "SessionStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return 0, errors.New(messages.SessionQueryInternalError)
+	}
+	return result.RowsAffected, nil
+}
+
+// Sign the user out everywhere except keepSessionId
+func (S *SessionStoreLite) DeleteOtherSessions(userId int, keepSessionId string) (count int64, err error) {
+	result := Context.Where("user_id = ? AND session_id <> ?", userId, keepSessionId).Delete(&models.Session{})
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "SessionStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return 0, errors.New(messages.SessionQueryInternalError)
+	}
+	return result.RowsAffected, nil
+}
+
+// Delete sessions whose tokens have all expired
+func (S *SessionStoreLite) DeleteExpiredSessions(now time.Time) (count int64, err error) {
+	result := Context.Where("expires_at < ?", now).Delete(&models.Session{})
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "SessionStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return 0, errors.New(messages.SessionQueryInternalError)
+	}
+	return result.RowsAffected, nil
+}
@@ -2,6 +2,7 @@ package storagelite
 
 import (
 	"os"
+	"strings"
 	l "todo-web-api/loggerutils"
 	models "todo-web-api/models"
 
@@ -23,17 +24,74 @@ func (Db *StoreManagerLite) Connect(dbUser, dbPassword, dbHost, dbPort, dbName s
 	if dbPath == "" {
 		dbPath = "todo.db"
 	}
-	Context, err = gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+	// SQLite changes a table by copying it to a new one and dropping the
+	// old, which with foreign keys on would cascade into its children. So
+	// migrations run on a connection without them, and the app gets its
+	// own.
+	migrations, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+	if err != nil {
+		errMsg := "SQLite connection failed."
+		l.Log.WithFields(logrus.Fields{"LoggerName": "StoreManagerLite"}).Fatal(errMsg)
+	}
+	Db.MigrateModels(migrations)
+	if sqlDb, err := migrations.DB(); err == nil {
+		sqlDb.Close()
+	}
+
+	Context, err = gorm.Open(sqlite.Open(withForeignKeys(dbPath)), &gorm.Config{})
 	if err != nil {
 		errMsg := "SQLite connection failed."
 		l.Log.WithFields(logrus.Fields{"LoggerName": "StoreManagerLite"}).Fatal(errMsg)
 	}
 	l.Log.WithFields(logrus.Fields{"LoggerName": "StoreManagerLite"}).Info("SQLite Connection Successful")
-	Db.MigrateModels(Context)
+}
+
+// withForeignKeys turns on foreign key enforcement, which SQLite leaves
+// off unless each connection asks for it.
+func withForeignKeys(dbPath string) string {
+	if strings.Contains(dbPath, "?") {
+		return dbPath + "&_foreign_keys=on"
+	}
+	return dbPath + "?_foreign_keys=on"
 }
 
 func (Db *StoreManagerLite) MigrateModels(db *gorm.DB) {
-	db.AutoMigrate(&models.Task{})
+	// Lists go before the tables with foreign keys to them.
 	db.AutoMigrate(&models.User{})
+	fillVerifiedEmails(db)
 	db.AutoMigrate(&models.List{})
+	deleteOrphans(db)
+	db.AutoMigrate(&models.Task{})
+	db.AutoMigrate(&models.Session{})
+	db.AutoMigrate(&models.LoginAttempt{})
+	db.AutoMigrate(&models.RecoveryCode{})
+	db.AutoMigrate(&models.PersonalAccessToken{})
+	db.AutoMigrate(&models.OneTimeToken{})
+	db.AutoMigrate(&models.ExternalIdentity{})
+	db.AutoMigrate(&models.AuditEvent{})
+	db.AutoMigrate(&models.ListMember{})
+	db.AutoMigrate(&models.ListTemplate{})
+	db.AutoMigrate(&models.TemplateTask{})
+}
+
+// fillVerifiedEmails copies addresses verified before VerifiedEmail existed.
+// If two accounts verified the same one, the older account keeps it.
+func fillVerifiedEmails(db *gorm.DB) {
+	var users []models.User
+	db.Where("email_verified = ? AND email <> '' AND verified_email IS NULL", true).Order("id").Find(&users)
+	for _, user := range users {
+		db.Model(&models.User{}).Where("id = ?", user.Id).Update("verified_email", user.Email)
+	}
+}
+
+// deleteOrphans removes tasks and list members whose list is gone. Lists
+// deleted before the foreign keys existed left them behind, and they would
+// keep the keys from being added. Lists in the trash still count.
+func deleteOrphans(db *gorm.DB) {
+	lists := db.Unscoped().Model(&models.List{}).Select("id")
+	for _, model := range []interface{}{&models.Task{}, &models.ListMember{}} {
+		if db.Migrator().HasTable(model) {
+			db.Unscoped().Where("list_id NOT IN (?)", lists).Delete(model)
+		}
+	}
 }
@@ -2,6 +2,7 @@ package storagelite
 
 import (
 	"errors"
+	"time"
 	"todo-web-api/messages"
 	models "todo-web-api/models"
 
@@ -74,3 +75,108 @@ func (T *TaskStoreLite) UpdateTask(task *models.Task) (ID int, err error) {
 	result := Context.Save(&task)
 	return task.Id, result.Error
 }
+
+// The list's tasks, in the order they were added.
+func (T *TaskStoreLite) GetTasksForList(listId int) ([]models.Task, error) {
+	var tasks []models.Task
+	result := Context.Where("list_id = ?", listId).Order("id").Find(&tasks)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "TaskStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return nil, errors.New(messages.TaskQueryInternalError)
+	}
+	return tasks, nil
+}
+
+// Tasks due before to, and not before from unless it's zero, on the
+// unarchived lists the user owns or has accepted an invitation to; soonest
+// first.
+func (T *TaskStoreLite) GetDueTasksForUser(userId int, from time.Time, to time.Time, openOnly bool) ([]models.Task, error) {
+	var tasks []models.Task
+	shared := Context.Model(&models.ListMember{}).Select("list_id").Where("user_id = ? AND accepted_at IS NOT NULL", userId)
+	lists := Context.Model(&models.List{}).Select("id").Where("(user_id = ? OR id IN (?)) AND archived_at IS NULL", userId, shared)
+	query := Context.Where("list_id IN (?) AND due_at < ?", lists, to.UTC())
+	if !from.IsZero() {
+		query = query.Where("due_at >= ?", from.UTC())
+	}
+	if openOnly {
+		query = query.Where("is_completed = ?", false)
+	}
+	result := query.Order("due_at, id").Find(&tasks)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "TaskStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return nil, errors.New(messages.TaskQueryInternalError)
+	}
+	return tasks, nil
+}
+
+// The trashed task with the given id; tasks trashed with their list count.
+func (T *TaskStoreLite) GetTrashedTask(id int) (*models.Task, error) {
+	var task models.Task
+	result := Context.Unscoped().Where("deleted_at IS NOT NULL").First(&task, id)
+	if result.Error != nil && errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "TaskStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return nil, errors.New(messages.TaskNotFoundInDb)
+	} else if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "TaskStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return nil, errors.New(messages.TaskQueryInternalError)
+	}
+	return &task, nil
+}
+
+// Tasks in the trash whose list isn't, from the lists the user owns or has
+// accepted an editor invitation to; most recently deleted first.
+func (T *TaskStoreLite) GetTrashedTasksForUser(userId int) ([]models.Task, error) {
+	var tasks []models.Task
+	edited := Context.Model(&models.ListMember{}).Select("list_id").Where("user_id = ? AND role = ? AND accepted_at IS NOT NULL", userId, "editor")
+	lists := Context.Model(&models.List{}).Select("id").Where("user_id = ? OR id IN (?)", userId, edited)
+	result := Context.Unscoped().Where("deleted_at IS NOT NULL AND list_id IN (?)", lists).Order("deleted_at desc, id").Find(&tasks)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "TaskStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return nil, errors.New(messages.TaskQueryInternalError)
+	}
+	return tasks, nil
+}
+
+func (T *TaskStoreLite) RestoreTask(id int) (success bool, err error) {
+	result := Context.Unscoped().Model(&models.Task{}).Where("id = ? AND deleted_at IS NOT NULL", id).Update("deleted_at", nil)
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "TaskStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return false, errors.New(messages.TaskQueryInternalError)
+	}
+	if result.RowsAffected == 0 {
+		return false, errors.New(messages.TaskNotFoundInDb)
+	}
+	return true, nil
+}
+
+// PurgeDeletedTasks deletes the tasks trashed before the given time for
+// good.
+func (T *TaskStoreLite) PurgeDeletedTasks(before time.Time) (count int64, err error) {
+	result := Context.Unscoped().Where("deleted_at < ?", before).Delete(&models.Task{})
+	if result.Error != nil {
+		log.WithFields(logrus.Fields{
+			"LoggerName": "TaskStoreLite",
+			"DbContext":  "sqlite",
+		}).Error(result.Error)
+		return 0, errors.New(messages.TaskQueryInternalError)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,170 @@
+package controllertests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	auth "todo-web-api/authentication"
+	authz "todo-web-api/authorization"
+	app "todo-web-api/controllers"
+	h "todo-web-api/helpers"
+	"todo-web-api/messages"
+	"todo-web-api/middleware"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+	m "todo-web-api/tests/mockmanagers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupAccessTokenRouters(user *models.User, tokenManager m.IAccessTokenMockManager) *gin.Engine {
+	r := gin.Default()
+	storage.UserManager = &m.MockUserManager{GetUserFn: func(id int) (*models.User, error) {
+		return user, nil
+	}}
+	storage.AccessTokenManager = tokenManager
+
+	authed := r.Group("/", withUser(user.Id, "sid"))
+	{
+		authed.GET("/AccessTokens", app.GetAccessTokens)
+		authed.POST("/AccessTokens", app.CreateAccessToken)
+		authed.DELETE("/AccessTokens/:id", app.RevokeAccessToken)
+	}
+
+	// Routes behind the real middleware, to check how tokens authenticate.
+	api := r.Group("/api", middleware.AuthMiddleware())
+	{
+		api.GET("/read", middleware.RequirePermission(authz.PermListsRead), func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"user_id": c.GetInt("user_id")})
+		})
+		api.POST("/write", middleware.RequirePermission(authz.PermTasksWrite), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+	}
+	return r
+}
+
+func createTokenRequest(body h.CreateAccessToken) *http.Request {
+	jsonValue, _ := json.Marshal(body)
+	req, _ := http.NewRequest("POST", "/AccessTokens", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func bearerRequest(method, path, token string) *http.Request {
+	req, _ := http.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func TestCreateAccessToken_StoresOnlyDigest(t *testing.T) {
+	user := &models.User{Id: 1, Username: "u1", Role: authz.RoleUser}
+	var stored *models.PersonalAccessToken
+	router := setupAccessTokenRouters(user, &m.MockAccessTokenManager{
+		CreateAccessTokenFn: func(token *models.PersonalAccessToken) (int, error) {
+			stored = token
+			token.Id = 7
+			return 7, nil
+		}})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, createTokenRequest(h.CreateAccessToken{Name: "backup", Scopes: []string{"lists:read"}}))
+
+	var created h.CreatedAccessTokenResponse
+	json.Unmarshal(w.Body.Bytes(), &created)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.HasPrefix(created.Token, auth.AccessTokenPrefix))
+	assert.Equal(t, tokenDigest(created.Token), stored.TokenHash)
+	assert.True(t, strings.HasPrefix(created.Token, stored.Prefix))
+	assert.Equal(t, 7, created.AccessToken.Id)
+	assert.Equal(t, []string{"lists:read"}, created.AccessToken.Scopes)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, 30), stored.ExpiresAt, time.Minute)
+}
+
+func TestCreateAccessToken_RejectsScopes(t *testing.T) {
+	user := &models.User{Id: 1, Username: "u1", Role: authz.RoleUser}
+	router := setupAccessTokenRouters(user, &m.MockAccessTokenManager{})
+
+	for _, scopes := range [][]string{
+		{authz.PermAccountWrite}, // never grantable to a token
+		{authz.PermUsersManage},  // not the user's to give
+		{"lists:everything"},
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, createTokenRequest(h.CreateAccessToken{Name: "backup", Scopes: scopes}))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, scopes)
+		assert.Contains(t, w.Body.String(), messages.InvalidScope)
+	}
+}
+
+func TestAccessToken_AuthenticatesWithScopes(t *testing.T) {
+	user := &models.User{Id: 1, Username: "u1", Role: authz.RoleUser}
+	plain := auth.AccessTokenPrefix + "testtoken"
+	touched := 0
+	router := setupAccessTokenRouters(user, &m.MockAccessTokenManager{
+		GetAccessTokenByHashFn: func(tokenHash string) (*models.PersonalAccessToken, error) {
+			assert.Equal(t, tokenDigest(plain), tokenHash)
+			return &models.PersonalAccessToken{Id: 3, UserId: 1, Scopes: "lists:read",
+				ExpiresAt: time.Now().Add(time.Hour)}, nil
+		},
+		TouchAccessTokenFn: func(id int, usedAt time.Time) error {
+			touched = id
+			return nil
+		}})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, bearerRequest("GET", "/api/read", plain))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"user_id": 1}`, w.Body.String())
+	assert.Equal(t, 3, touched)
+
+	// Outside the token's scopes, even though the user's role allows it.
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, bearerRequest("POST", "/api/write", plain))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestAccessToken_ExpiredOrDisabledRejected(t *testing.T) {
+	plain := auth.AccessTokenPrefix + "testtoken"
+	for name, tc := range map[string]struct {
+		user      *models.User
+		expiresAt time.Time
+	}{
+		"expired":  {&models.User{Id: 1, Role: authz.RoleUser}, time.Now().Add(-time.Minute)},
+		"disabled": {&models.User{Id: 1, Role: authz.RoleUser, IsDisabled: true}, time.Now().Add(time.Hour)},
+	} {
+		expiresAt := tc.expiresAt
+		router := setupAccessTokenRouters(tc.user, &m.MockAccessTokenManager{
+			GetAccessTokenByHashFn: func(tokenHash string) (*models.PersonalAccessToken, error) {
+				return &models.PersonalAccessToken{Id: 3, UserId: 1, Scopes: "lists:read", ExpiresAt: expiresAt}, nil
+			}})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, bearerRequest("GET", "/api/read", plain))
+		assert.Equal(t, http.StatusUnauthorized, w.Code, name)
+	}
+}
+
+func TestRevokeAccessToken_NotOwned(t *testing.T) {
+	user := &models.User{Id: 1, Username: "u1", Role: authz.RoleUser}
+	router := setupAccessTokenRouters(user, &m.MockAccessTokenManager{
+		DeleteAccessTokenFn: func(userId int, id int) (bool, error) {
+			return userId == 1 && id == 3, nil
+		}})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/AccessTokens/4", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/AccessTokens/3", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
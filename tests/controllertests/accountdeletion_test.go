@@ -0,0 +1,134 @@
+package controllertests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	auth "todo-web-api/authentication"
+	app "todo-web-api/controllers"
+	h "todo-web-api/helpers"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+	m "todo-web-api/tests/mockmanagers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupDeletionRouters(user *models.User, deleted *int) *gin.Engine {
+	r := gin.Default()
+	storage.UserManager = &m.MockUserManager{
+		GetUserFn: func(id int) (*models.User, error) {
+			return user, nil
+		},
+		UpdateUserFn: func(u *models.User) (int, error) {
+			return u.Id, nil
+		},
+		DeleteUserFn: func(id int) (bool, error) {
+			*deleted = id
+			return true, nil
+		},
+		GetUsersDueForDeletionFn: func(now time.Time) ([]models.User, error) {
+			if user.DeletionScheduledAt == nil || user.DeletionScheduledAt.After(now) {
+				return nil, nil
+			}
+			return []models.User{*user}, nil
+		}}
+	storage.LoginAttemptManager = &m.MockLoginAttemptManager{}
+	storage.AuditEventManager = &m.MockAuditEventManager{}
+
+	authed := r.Group("/", withUser(user.Id, "sid"))
+	{
+		authed.DELETE("/Account", app.DeleteAccount)
+		authed.POST("/CancelAccountDeletion", app.CancelAccountDeletion)
+	}
+	return r
+}
+
+func TestDeleteAccount_Immediate(t *testing.T) {
+	auth.ConfigureAccountDeletion(0)
+	user := passwordUser()
+	deleted := 0
+	router := setupDeletionRouters(user, &deleted)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("DELETE", "/Account", h.DeleteAccount{Password: "testpass1"}))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), messages.SuccessAccountDeleted)
+	assert.Equal(t, user.Id, deleted)
+	for _, cookie := range w.Result().Cookies() {
+		assert.Empty(t, cookie.Value)
+	}
+}
+
+func TestDeleteAccount_WrongPassword(t *testing.T) {
+	auth.ConfigureAccountDeletion(0)
+	user := passwordUser()
+	deleted := 0
+	router := setupDeletionRouters(user, &deleted)
+	recorded := []string{}
+	storage.LoginAttemptManager = &m.MockLoginAttemptManager{
+		RecordFailedAttemptFn: func(subject string, now time.Time, windowStart time.Time) (*models.LoginAttempt, error) {
+			recorded = append(recorded, subject)
+			return &models.LoginAttempt{Subject: subject, Failures: 1}, nil
+		}}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("DELETE", "/Account", h.DeleteAccount{Password: "wrongpw1"}))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), messages.InvalidCurrentPassword)
+	assert.Contains(t, recorded, "user:u1")
+	assert.Zero(t, deleted)
+}
+
+func TestDeleteAccount_GracePeriodCanBeCancelled(t *testing.T) {
+	auth.ConfigureAccountDeletion(48 * time.Hour)
+	defer auth.ConfigureAccountDeletion(0)
+	user := passwordUser()
+	deleted := 0
+	router := setupDeletionRouters(user, &deleted)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("DELETE", "/Account", h.DeleteAccount{Password: "testpass1"}))
+
+	var resp h.AccountDeletionResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Zero(t, deleted)
+	if assert.NotNil(t, resp.DeletionScheduledAt) {
+		assert.WithinDuration(t, time.Now().Add(48*time.Hour), *resp.DeletionScheduledAt, time.Minute)
+	}
+
+	// Nothing is due yet.
+	auth.PurgeScheduledAccountDeletions()
+	assert.Zero(t, deleted)
+
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/CancelAccountDeletion", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, user.DeletionScheduledAt)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/CancelAccountDeletion", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), messages.AccountDeletionNotScheduled)
+}
+
+func TestPurgeScheduledAccountDeletions_DeletesDueAccounts(t *testing.T) {
+	user := passwordUser()
+	due := time.Now().Add(-time.Minute)
+	user.DeletionScheduledAt = &due
+	deleted := 0
+	setupDeletionRouters(user, &deleted)
+
+	auth.PurgeScheduledAccountDeletions()
+
+	assert.Equal(t, user.Id, deleted)
+}
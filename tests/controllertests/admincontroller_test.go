@@ -0,0 +1,117 @@
+package controllertests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	authz "todo-web-api/authorization"
+	app "todo-web-api/controllers"
+	h "todo-web-api/helpers"
+	"todo-web-api/messages"
+	"todo-web-api/middleware"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+	m "todo-web-api/tests/mockmanagers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func withRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("permissions", authz.PermissionsFor(role))
+		c.Next()
+	}
+}
+
+func setupAdminRouters(userManager m.IUserMockManager, sessionManager m.ISessionMockManager, userId int, role string) *gin.Engine {
+	r := gin.Default()
+	storage.UserManager = userManager
+	storage.SessionManager = sessionManager
+	admin := r.Group("/admin", withUser(userId, "sid"), withRole(role))
+	{
+		admin.GET("/Users", middleware.RequirePermission(authz.PermUsersRead), app.AdminGetUsers)
+		admin.PUT("/DisableUser/:id", middleware.RequirePermission(authz.PermUsersManage), app.AdminDisableUser)
+		admin.PUT("/EnableUser/:id", middleware.RequirePermission(authz.PermUsersManage), app.AdminEnableUser)
+		admin.POST("/ForceLogout/:id", middleware.RequirePermission(authz.PermUsersManage), app.AdminForceLogout)
+	}
+	return r
+}
+
+func TestAdmin_UserRoleForbidden(t *testing.T) {
+	router := setupAdminRouters(&m.MockUserManager{}, &m.MockSessionManager{}, 1, authz.RoleUser)
+
+	for _, req := range []struct{ method, path string }{
+		{"GET", "/admin/Users"},
+		{"PUT", "/admin/DisableUser/2"},
+		{"PUT", "/admin/EnableUser/2"},
+		{"POST", "/admin/ForceLogout/2"},
+	} {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(req.method, req.path, nil)
+		router.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusForbidden, w.Code, req.path)
+	}
+}
+
+func TestAdmin_GetUsers(t *testing.T) {
+	search := ""
+	router := setupAdminRouters(&m.MockUserManager{GetUsersFn: func(s string) ([]models.User, error) {
+		search = s
+		return []models.User{{Id: 2, Username: "u2", Password: "hash", Role: authz.RoleUser}}, nil
+	}}, &m.MockSessionManager{}, 1, authz.RoleAdmin)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/admin/Users?username=u2", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "u2", search)
+	assert.NotContains(t, w.Body.String(), "hash")
+
+	var users []h.AdminUserResult
+	json.Unmarshal(w.Body.Bytes(), &users)
+	assert.Len(t, users, 1)
+	assert.Equal(t, "u2", users[0].Username)
+}
+
+func TestAdmin_DisableUserRevokesSessions(t *testing.T) {
+	var saved *models.User
+	revokedFor := 0
+	router := setupAdminRouters(&m.MockUserManager{
+		GetUserFn: func(id int) (*models.User, error) {
+			return &models.User{Id: id, Username: "u2", Role: authz.RoleUser}, nil
+		},
+		UpdateUserFn: func(user *models.User) (int, error) {
+			saved = user
+			return user.Id, nil
+		}}, &m.MockSessionManager{
+		DeleteSessionsForUserFn: func(userId int) (int64, error) {
+			revokedFor = userId
+			return 2, nil
+		}}, 1, authz.RoleAdmin)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/admin/DisableUser/2", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, saved.IsDisabled)
+	assert.Equal(t, 2, revokedFor)
+}
+
+func TestAdmin_CannotDisableSelf(t *testing.T) {
+	router := setupAdminRouters(&m.MockUserManager{
+		GetUserFn: func(id int) (*models.User, error) {
+			return &models.User{Id: id, Username: "admin", Role: authz.RoleAdmin}, nil
+		}}, &m.MockSessionManager{}, 1, authz.RoleAdmin)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/admin/DisableUser/1", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), messages.CannotDisableSelf)
+}
@@ -0,0 +1,119 @@
+package controllertests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	auth "todo-web-api/authentication"
+	app "todo-web-api/controllers"
+	h "todo-web-api/helpers"
+	"todo-web-api/messages"
+	"todo-web-api/middleware"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+	m "todo-web-api/tests/mockmanagers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupCsrfRouters signs u1 in with session sid and returns its access
+// token, with routes behind the real auth and CSRF middleware.
+func setupCsrfRouters(t *testing.T) (*gin.Engine, string) {
+	accessToken, err := auth.GenerateAccessToken("u1", 1, "sid", nil)
+	assert.Nil(t, err)
+	storage.SessionManager = &m.MockSessionManager{GetSessionFn: func(sessionId string) (*models.Session, error) {
+		if sessionId != "sid" {
+			return nil, nil
+		}
+		return &models.Session{SessionId: "sid", UserId: 1, AccessToken: tokenDigest(accessToken), ExpiresAt: time.Now().Add(time.Hour)}, nil
+	}}
+
+	r := gin.Default()
+	api := r.Group("/", middleware.AuthMiddleware(), middleware.RequireCSRF())
+	{
+		api.GET("/CsrfToken", app.GetCsrfToken)
+		api.GET("/read", func(c *gin.Context) { c.Status(http.StatusOK) })
+		api.DELETE("/write", func(c *gin.Context) { c.Status(http.StatusOK) })
+	}
+	return r, accessToken
+}
+
+func cookieRequest(method, path, accessToken, csrfToken string) *http.Request {
+	req, _ := http.NewRequest(method, path, nil)
+	req.AddCookie(&http.Cookie{Name: "access_token", Value: accessToken})
+	if csrfToken != "" {
+		req.Header.Set(middleware.CSRFHeader, csrfToken)
+	}
+	return req
+}
+
+func fetchCsrfToken(t *testing.T, router *gin.Engine, accessToken string) string {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, cookieRequest("GET", "/CsrfToken", accessToken, ""))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp h.CsrfTokenResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp.CsrfToken
+}
+
+func TestCsrf_CookieWriteWithoutTokenIsRefused(t *testing.T) {
+	router, accessToken := setupCsrfRouters(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, cookieRequest("DELETE", "/write", accessToken, ""))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), messages.InvalidCSRFToken)
+}
+
+func TestCsrf_CookieWriteWithToken(t *testing.T) {
+	router, accessToken := setupCsrfRouters(t)
+	csrfToken := fetchCsrfToken(t, router, accessToken)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, cookieRequest("DELETE", "/write", accessToken, csrfToken))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCsrf_CookieReadNeedsNoToken(t *testing.T) {
+	router, accessToken := setupCsrfRouters(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, cookieRequest("GET", "/read", accessToken, ""))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCsrf_BearerWriteBypasses(t *testing.T) {
+	router, accessToken := setupCsrfRouters(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, bearerRequest("DELETE", "/write", accessToken))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCsrf_TokenFromAnotherSessionIsRefused(t *testing.T) {
+	router, accessToken := setupCsrfRouters(t)
+	otherSession, _ := auth.GenerateCSRFToken("other-sid")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, cookieRequest("DELETE", "/write", accessToken, otherSession))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestCsrf_TokenIsNotAnAccessToken(t *testing.T) {
+	router, accessToken := setupCsrfRouters(t)
+	csrfToken := fetchCsrfToken(t, router, accessToken)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, bearerRequest("GET", "/read", csrfToken))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,93 @@
+package controllertests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	auth "todo-web-api/authentication"
+	"todo-web-api/models"
+	m "todo-web-api/tests/mockmanagers"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// hashedUserManager serves one account with the given stored hash and
+// counts how often it is saved.
+func hashedUserManager(user *models.User, saves *int) *m.MockUserManager {
+	return &m.MockUserManager{
+		FindExistingAccountFn: func(username, password string) (*models.User, error) {
+			return user, nil
+		},
+		UpdateUserFn: func(u *models.User) (int, error) {
+			*saves++
+			return u.Id, nil
+		}}
+}
+
+func TestLogin_RehashesBcryptToArgon2id(t *testing.T) {
+	user := &models.User{Id: 1, Username: "u1", Role: "user"}
+	user.Password, _ = hashPassword("testpass1")
+	saves := 0
+	router := setupRouters(hashedUserManager(user, &saves))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, loginRequest("u1", "testpass1"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, saves)
+	assert.True(t, strings.HasPrefix(user.Password, "$argon2id$v=19$m=65536,t=3,p=2$"))
+	assert.True(t, verifies(user.Password, "testpass1"))
+}
+
+func TestLogin_RehashesWeakerArgon2idParameters(t *testing.T) {
+	auth.ConfigurePasswordHasher(&auth.Argon2idHasher{Memory: 8 * 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+	user := &models.User{Id: 1, Username: "u1", Role: "user"}
+	user.Password, _ = auth.HashPassword("testpass1")
+	auth.ConfigurePasswordHasher(auth.DefaultArgon2idHasher())
+	saves := 0
+	router := setupRouters(hashedUserManager(user, &saves))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, loginRequest("u1", "testpass1"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, saves)
+	assert.True(t, strings.HasPrefix(user.Password, "$argon2id$v=19$m=65536,t=3,p=2$"))
+}
+
+func TestLogin_CurrentHashIsKept(t *testing.T) {
+	user := &models.User{Id: 1, Username: "u1", Role: "user"}
+	user.Password, _ = auth.HashPassword("testpass1")
+	stored := user.Password
+	saves := 0
+	router := setupRouters(hashedUserManager(user, &saves))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, loginRequest("u1", "testpass1"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Zero(t, saves)
+	assert.Equal(t, stored, user.Password)
+}
+
+func TestLogin_WrongPasswordIsNotRehashed(t *testing.T) {
+	user := &models.User{Id: 1, Username: "u1", Role: "user"}
+	user.Password, _ = hashPassword("testpass1")
+	saves := 0
+	router := setupRouters(hashedUserManager(user, &saves))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, loginRequest("u1", "wrongpw1"))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Zero(t, saves)
+}
+
+func TestVerifyPassword_RejectsMalformedHashes(t *testing.T) {
+	for _, hash := range []string{"", "plaintext", "$argon2id$v=19$m=65536,t=3,p=2$c2FsdA", "$argon2id$v=16$m=65536,t=3,p=2$c2FsdA$a2V5"} {
+		match, needsRehash := auth.VerifyPassword(hash, "testpass1")
+		assert.False(t, match, hash)
+		assert.False(t, needsRehash, hash)
+	}
+}
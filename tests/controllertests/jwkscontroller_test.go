@@ -0,0 +1,117 @@
+package controllertests
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	auth "todo-web-api/authentication"
+	app "todo-web-api/controllers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeKeyFile(t *testing.T, name string, key interface{}) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func configureTestKeys(t *testing.T) (oldKey, newKey auth.KeyConfig) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	oldKey = auth.KeyConfig{Kid: "old", Algorithm: "EdDSA", PrivateKeyFile: writeKeyFile(t, "old.pem", edKey)}
+	newKey = auth.KeyConfig{Kid: "new", Algorithm: "RS256", PrivateKeyFile: writeKeyFile(t, "new.pem", rsaKey)}
+	return oldKey, newKey
+}
+
+func TestKeyRotation_OldTokensStillVerify(t *testing.T) {
+	oldKey, newKey := configureTestKeys(t)
+
+	assert.NoError(t, auth.ConfigureKeys([]auth.KeyConfig{oldKey}, "old"))
+	token, _ := auth.GenerateAccessToken("u1", 1, "sid-1", nil)
+
+	assert.NoError(t, auth.ConfigureKeys([]auth.KeyConfig{oldKey, newKey}, "new"))
+	claims, err := auth.ParseToken(token)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "sid-1", claims.SessionID)
+}
+
+func TestKeyRotation_RetiredKeyRejected(t *testing.T) {
+	oldKey, newKey := configureTestKeys(t)
+
+	assert.NoError(t, auth.ConfigureKeys([]auth.KeyConfig{oldKey}, "old"))
+	token, _ := auth.GenerateAccessToken("u1", 1, "sid-1", nil)
+
+	oldKey.VerifyUntil = time.Now().Add(-time.Minute)
+	assert.NoError(t, auth.ConfigureKeys([]auth.KeyConfig{oldKey, newKey}, "new"))
+	_, err := auth.ParseToken(token)
+
+	assert.Error(t, err)
+}
+
+func TestKeyFromEnvironment(t *testing.T) {
+	oldKey, _ := configureTestKeys(t)
+	pemBytes, _ := os.ReadFile(oldKey.PrivateKeyFile)
+	fromEnv := auth.KeyConfig{Kid: "env", Algorithm: "EdDSA", PrivateKeyEnv: "TEST_JWT_SIGNING_KEY"}
+
+	t.Setenv("TEST_JWT_SIGNING_KEY", "")
+	assert.Error(t, auth.ConfigureKeys([]auth.KeyConfig{fromEnv}, "env"))
+
+	t.Setenv("TEST_JWT_SIGNING_KEY", string(pemBytes))
+	assert.NoError(t, auth.ConfigureKeys([]auth.KeyConfig{fromEnv}, "env"))
+	token, _ := auth.GenerateAccessToken("u1", 1, "sid-1", nil)
+
+	// The same key read from its file verifies what the env key signed.
+	assert.NoError(t, auth.ConfigureKeys([]auth.KeyConfig{{Kid: "env", Algorithm: "EdDSA", PrivateKeyFile: oldKey.PrivateKeyFile}}, "env"))
+	_, err := auth.ParseToken(token)
+	assert.NoError(t, err)
+
+	fromEnv.PrivateKeyFile = oldKey.PrivateKeyFile
+	assert.Error(t, auth.ConfigureKeys([]auth.KeyConfig{fromEnv}, "env"))
+}
+
+func TestJWKS(t *testing.T) {
+	oldKey, newKey := configureTestKeys(t)
+	assert.NoError(t, auth.ConfigureKeys([]auth.KeyConfig{oldKey, newKey}, "new"))
+
+	r := gin.Default()
+	r.GET("/.well-known/jwks.json", app.JWKS)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/.well-known/jwks.json", nil)
+	r.ServeHTTP(w, req)
+
+	var jwks auth.JWKS
+	json.Unmarshal(w.Body.Bytes(), &jwks)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Len(t, jwks.Keys, 2)
+	for _, key := range jwks.Keys {
+		if key.Kid == "new" {
+			assert.Equal(t, "RSA", key.Kty)
+			assert.Equal(t, "RS256", key.Alg)
+			assert.NotEmpty(t, key.N)
+		} else {
+			assert.Equal(t, "OKP", key.Kty)
+			assert.Equal(t, "Ed25519", key.Crv)
+			assert.NotEmpty(t, key.X)
+		}
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"strings"
 	"testing"
 	app "todo-web-api/controllers"
+	h "todo-web-api/helpers"
+	"todo-web-api/messages"
 	"todo-web-api/models"
 	"todo-web-api/storage"
 	m "todo-web-api/tests/mockmanagers"
@@ -45,6 +47,8 @@ func setupListRouters(listManager m.IListMockManager, userManager m.IUserMockMan
 		r.POST("/CreateList/:id", app.CreateListForUser)
 		r.GET("/GetList/:userid", app.GetListByUserId)
 		r.DELETE("/DeleteList/:id", app.DeleteList)
+		r.PUT("/UpdateList/:id", app.UpdateList)
+		r.PUT("/ListArchived/:id", app.SetListArchived)
 	}
 	return r
 }
@@ -83,7 +87,7 @@ func TestCreateListForUser(t *testing.T) {
 		}}, userManager)
 	w := httptest.NewRecorder()
 
-	list := models.List{}
+	list := h.SaveList{Title: "Groceries"}
 	json, _ := json.Marshal(list)
 	req, _ := http.NewRequest("POST", fmt.Sprintf("/CreateList/%d", 1), strings.NewReader(string(json)))
 	router.ServeHTTP(w, req)
@@ -99,7 +103,7 @@ func TestCreateListForUser_NotFound(t *testing.T) {
 		}})
 	w := httptest.NewRecorder()
 
-	user := models.List{}
+	user := h.SaveList{Title: "Groceries"}
 	json, _ := json.Marshal(user)
 	req, _ := http.NewRequest("POST", fmt.Sprintf("/CreateList/%d", 1), strings.NewReader(string(json)))
 	router.ServeHTTP(w, req)
@@ -107,20 +111,91 @@ func TestCreateListForUser_NotFound(t *testing.T) {
 	assert.Equal(t, 400, w.Code)
 }
 
-func TestCreateList_AlreadyExists(t *testing.T) {
+func TestCreateList_SecondListAllowed(t *testing.T) {
 	InitManagersDefault()
+	var created *models.List
 	router := setupListRouters(&m.MockListManager{
 		GetListForUserFn: func(id int) (*models.List, error) {
-			return &models.List{}, nil
+			return &models.List{Id: 1, UserId: id, Title: "Groceries"}, nil
+		},
+		CreateListFn: func(list *models.List) (int, error) {
+			created = list
+			list.Id = 2
+			return 2, nil
 		}}, userManager)
 	w := httptest.NewRecorder()
 
-	list := models.List{}
+	list := h.SaveList{Title: "  Work  ", Description: "Due this sprint"}
 	json, _ := json.Marshal(list)
 	req, _ := http.NewRequest("POST", fmt.Sprintf("/CreateList/%d", 1), strings.NewReader(string(json)))
 	router.ServeHTTP(w, req)
 
-	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "Work", created.Title)
+	assert.Equal(t, "Due this sprint", created.Description)
+}
+
+func TestCreateList_RequiresTitle(t *testing.T) {
+	InitManagersDefault()
+	router := setupListRouters(listManager, userManager)
+
+	for _, list := range []h.SaveList{{}, {Title: "   "}, {Title: strings.Repeat("x", 101)}} {
+		w := httptest.NewRecorder()
+		json, _ := json.Marshal(list)
+		req, _ := http.NewRequest("POST", fmt.Sprintf("/CreateList/%d", 1), strings.NewReader(string(json)))
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, 400, w.Code)
+	}
+}
+
+func TestGetLists(t *testing.T) {
+	InitManagersDefault()
+	r := gin.Default()
+	storage.ListManager = &m.MockListManager{
+		GetListsForUserFn: func(userId int, archived bool) ([]models.List, error) {
+			if userId != 3 {
+				return nil, nil
+			}
+			if archived {
+				return []models.List{{Id: 4, UserId: 3, Title: "Old"}}, nil
+			}
+			return []models.List{{Id: 1, UserId: 3, Title: "Groceries"}, {Id: 2, UserId: 3, Title: "Work"}, {Id: 5, UserId: 7, Title: "Sprint"}}, nil
+		}}
+	storage.ListMemberManager = &m.MockListMemberManager{
+		GetListMembershipsForUserFn: func(userId int, accepted bool) ([]models.ListMember, error) {
+			assert.True(t, accepted)
+			return []models.ListMember{{ListId: 5, UserId: userId, Role: "viewer"}}, nil
+		}}
+	r.GET("/Lists", withUser(3, "sid"), app.GetLists)
+	r.GET("/OtherLists", withUser(4, "sid"), app.GetLists)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/Lists", nil)
+	r.ServeHTTP(w, req)
+
+	var lists []models.List
+	json.Unmarshal(w.Body.Bytes(), &lists)
+	assert.Equal(t, 200, w.Code)
+	if assert.Len(t, lists, 3) {
+		assert.Equal(t, "Work", lists[1].Title)
+		assert.Equal(t, "owner", lists[0].Role)
+		assert.Equal(t, "viewer", lists[2].Role)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/Lists?archived=true", nil)
+	r.ServeHTTP(w, req)
+	json.Unmarshal(w.Body.Bytes(), &lists)
+	if assert.Len(t, lists, 1) {
+		assert.Equal(t, "Old", lists[0].Title)
+	}
+
+	// No lists is an empty array, not null.
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/OtherLists", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, "[]", w.Body.String())
 }
 
 func TestDeleteList(t *testing.T) {
@@ -138,7 +213,7 @@ func TestDeleteList(t *testing.T) {
 func TestDeleteList_ListNotFound(t *testing.T) {
 	router := setupListRouters(&m.MockListManager{
 		DeleteListFn: func(id int) (bool, error) {
-			return false, errors.New("list record not found")
+			return false, errors.New(messages.ListNotFoundInDb)
 		}}, userManager)
 
 	w := httptest.NewRecorder()
@@ -150,3 +225,78 @@ func TestDeleteList_ListNotFound(t *testing.T) {
 
 	assert.Equal(t, 400, w.Code)
 }
+
+// storedList serves one list and keeps what UpdateList saved.
+func storedList(list *models.List, saved **models.List) *m.MockListManager {
+	return &m.MockListManager{
+		GetListFn: func(id int) (*models.List, error) {
+			if id != list.Id {
+				return nil, errors.New(messages.ListNotFoundInDb)
+			}
+			found := *list
+			return &found, nil
+		},
+		UpdateListFn: func(list *models.List) (int, error) {
+			*saved = list
+			return list.Id, nil
+		}}
+}
+
+func TestUpdateList_ChangesOnlySentFields(t *testing.T) {
+	var saved *models.List
+	router := setupListRouters(storedList(&models.List{Id: 2, UserId: 1, Title: "Groceries", Description: "Saturday", Icon: "cart"}, &saved), userManager)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("PUT", "/UpdateList/2", map[string]interface{}{"title": "Shopping", "color": "#1E90FF", "position": 3}))
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "Shopping", saved.Title)
+	assert.Equal(t, "Saturday", saved.Description)
+	assert.Equal(t, "#1e90ff", saved.Color)
+	assert.Equal(t, "cart", saved.Icon)
+	assert.Equal(t, 3, saved.Position)
+}
+
+func TestUpdateList_RejectsInvalidFields(t *testing.T) {
+	var saved *models.List
+	router := setupListRouters(storedList(&models.List{Id: 2, UserId: 1, Title: "Groceries"}, &saved), userManager)
+
+	for _, body := range []map[string]interface{}{{"title": " "}, {"color": "blue"}, {"icon": strings.Repeat("x", 51)}} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, jsonRequest("PUT", "/UpdateList/2", body))
+		assert.Equal(t, 400, w.Code)
+	}
+	assert.Nil(t, saved)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("PUT", "/UpdateList/9", map[string]interface{}{"title": "Work"}))
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestSetListArchived(t *testing.T) {
+	var saved *models.List
+	list := &models.List{Id: 2, UserId: 1, Title: "Groceries"}
+	router := setupListRouters(storedList(list, &saved), userManager)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("PUT", "/ListArchived/2", map[string]interface{}{"isArchived": true}))
+	assert.Equal(t, 200, w.Code)
+	if assert.NotNil(t, saved.ArchivedAt) {
+		archivedAt := *saved.ArchivedAt
+
+		// Archiving again keeps the date.
+		list.ArchivedAt = &archivedAt
+		router.ServeHTTP(httptest.NewRecorder(), jsonRequest("PUT", "/ListArchived/2", map[string]interface{}{"isArchived": true}))
+		assert.Equal(t, archivedAt, *saved.ArchivedAt)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("PUT", "/ListArchived/2", map[string]interface{}{"isArchived": false}))
+	assert.Equal(t, 200, w.Code)
+	assert.Nil(t, saved.ArchivedAt)
+
+	// The flag has to be sent.
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("PUT", "/ListArchived/2", map[string]interface{}{}))
+	assert.Equal(t, 400, w.Code)
+}
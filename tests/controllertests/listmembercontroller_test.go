@@ -0,0 +1,266 @@
+package controllertests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	authz "todo-web-api/authorization"
+	app "todo-web-api/controllers"
+	h "todo-web-api/helpers"
+	"todo-web-api/messages"
+	"todo-web-api/middleware"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+	m "todo-web-api/tests/mockmanagers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupListMemberRouters signs in userId. List 10 belongs to u1; members
+// holds its memberships by user id and is updated by the handlers.
+func setupListMemberRouters(userId int, members map[int]*models.ListMember) *gin.Engine {
+	storage.UserManager = &m.MockUserManager{
+		GetUserFn: func(id int) (*models.User, error) {
+			return &models.User{Id: id, Username: fmt.Sprintf("u%d", id)}, nil
+		},
+		FindExistingAccountFn: func(username, password string) (*models.User, error) {
+			id, err := strconv.Atoi(strings.TrimPrefix(username, "u"))
+			if err != nil {
+				return nil, errors.New(messages.AccountNotFound)
+			}
+			return &models.User{Id: id, Username: username}, nil
+		}}
+	storage.ListManager = &m.MockListManager{GetListFn: func(id int) (*models.List, error) {
+		if id != 10 {
+			return nil, errors.New(messages.ListNotFoundInDb)
+		}
+		return &models.List{Id: 10, UserId: 1, Title: "Groceries"}, nil
+	}}
+	storage.ListMemberManager = &m.MockListMemberManager{
+		CreateListMemberFn: func(member *models.ListMember) (int, error) {
+			member.Id = member.UserId * 100
+			members[member.UserId] = member
+			return member.Id, nil
+		},
+		GetListMemberFn: func(listId int, memberId int) (*models.ListMember, error) {
+			if member, ok := members[memberId]; ok && listId == member.ListId {
+				return member, nil
+			}
+			return nil, errors.New(messages.ListMemberNotFoundInDb)
+		},
+		GetListMemberByIdFn: func(id int) (*models.ListMember, error) {
+			for _, member := range members {
+				if member.Id == id {
+					return member, nil
+				}
+			}
+			return nil, errors.New(messages.ListMemberNotFoundInDb)
+		},
+		GetListMembersFn: func(listId int) ([]models.ListMember, error) {
+			results := []models.ListMember{}
+			for _, member := range members {
+				results = append(results, *member)
+			}
+			return results, nil
+		},
+		DeleteListMemberFn: func(id int) (bool, error) {
+			for userId, member := range members {
+				if member.Id == id {
+					delete(members, userId)
+				}
+			}
+			return true, nil
+		}}
+
+	r := gin.Default()
+	auth := r.Group("/", withUser(userId, "sid"))
+	{
+		auth.GET("/ListMembers/:id", middleware.RequireListRole(authz.ListById, "id", authz.ListRoleViewer), app.GetListMembers)
+		auth.POST("/ListMembers/:id", middleware.RequireListRole(authz.ListById, "id", authz.ListRoleOwner), app.InviteListMember)
+		auth.PUT("/ListMembers/:id/:userid", middleware.RequireListRole(authz.ListById, "id", authz.ListRoleOwner), app.UpdateListMember)
+		auth.DELETE("/ListMembers/:id/:userid", middleware.RequireListRole(authz.ListById, "id", authz.ListRoleViewer), app.RemoveListMember)
+		auth.POST("/AcceptInvitation/:id", app.AcceptInvitation)
+		auth.POST("/DeclineInvitation/:id", app.DeclineInvitation)
+	}
+	return r
+}
+
+func acceptedMember(userId int, role string) *models.ListMember {
+	accepted := time.Now()
+	return &models.ListMember{Id: userId * 100, ListId: 10, UserId: userId, Username: fmt.Sprintf("u%d", userId), Role: role, AcceptedAt: &accepted}
+}
+
+func TestInviteListMember(t *testing.T) {
+	members := map[int]*models.ListMember{}
+	router := setupListMemberRouters(1, members)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("POST", "/ListMembers/10", h.InviteListMember{Username: "u2", Role: "editor"}))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	if assert.Contains(t, members, 2) {
+		assert.Equal(t, "editor", members[2].Role)
+		assert.Equal(t, 1, members[2].InvitedBy)
+		assert.Nil(t, members[2].AcceptedAt)
+	}
+
+	// Invited but not accepted yet: no access.
+	w = httptest.NewRecorder()
+	setupListMemberRouters(2, members).ServeHTTP(w, jsonRequest("GET", "/ListMembers/10", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestInviteListMember_Rejected(t *testing.T) {
+	var tests = []struct {
+		name    string
+		req     h.InviteListMember
+		want    int
+		message string
+	}{
+		{"Owner role", h.InviteListMember{Username: "u2", Role: "owner"}, 400, messages.InvalidListRole},
+		{"The owner", h.InviteListMember{Username: "u1", Role: "viewer"}, 400, messages.CannotInviteListOwner},
+		{"Already a member", h.InviteListMember{Username: "u3", Role: "viewer"}, 409, messages.AlreadyListMember},
+		{"Unknown user", h.InviteListMember{Username: "nobody", Role: "viewer"}, 404, messages.AccountNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupListMemberRouters(1, map[int]*models.ListMember{3: acceptedMember(3, "editor")})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, jsonRequest("POST", "/ListMembers/10", tt.req))
+
+			assert.Equal(t, tt.want, w.Code)
+			assert.Contains(t, w.Body.String(), tt.message)
+		})
+	}
+}
+
+func TestInviteListMember_OnlyByOwner(t *testing.T) {
+	router := setupListMemberRouters(3, map[int]*models.ListMember{3: acceptedMember(3, "editor")})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("POST", "/ListMembers/10", h.InviteListMember{Username: "u4", Role: "editor"}))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestGetListMembers(t *testing.T) {
+	router := setupListMemberRouters(3, map[int]*models.ListMember{
+		3: acceptedMember(3, "viewer"),
+		4: {Id: 400, ListId: 10, UserId: 4, Username: "u4", Role: "editor"}})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("GET", "/ListMembers/10", nil))
+
+	var results []h.ListMemberResult
+	json.Unmarshal(w.Body.Bytes(), &results)
+	assert.Equal(t, http.StatusOK, w.Code)
+	if assert.Len(t, results, 3) {
+		assert.Equal(t, h.ListMemberResult{UserId: 1, Username: "u1", Role: "owner"}, results[0])
+		for _, result := range results[1:] {
+			assert.Equal(t, result.UserId == 4, result.Pending)
+		}
+	}
+}
+
+func TestUpdateListMember(t *testing.T) {
+	members := map[int]*models.ListMember{3: acceptedMember(3, "editor")}
+	router := setupListMemberRouters(1, members)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("PUT", "/ListMembers/10/3", h.SetListMemberRole{Role: "viewer"}))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "viewer", members[3].Role)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("PUT", "/ListMembers/10/1", h.SetListMemberRole{Role: "viewer"}))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), messages.CannotChangeListOwner)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("PUT", "/ListMembers/10/5", h.SetListMemberRole{Role: "viewer"}))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRemoveListMember(t *testing.T) {
+	members := map[int]*models.ListMember{3: acceptedMember(3, "viewer"), 4: acceptedMember(4, "editor")}
+
+	// Members can leave, but can't remove anyone else.
+	w := httptest.NewRecorder()
+	setupListMemberRouters(3, members).ServeHTTP(w, jsonRequest("DELETE", "/ListMembers/10/4", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = httptest.NewRecorder()
+	setupListMemberRouters(3, members).ServeHTTP(w, jsonRequest("DELETE", "/ListMembers/10/3", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, members, 3)
+
+	w = httptest.NewRecorder()
+	setupListMemberRouters(1, members).ServeHTTP(w, jsonRequest("DELETE", "/ListMembers/10/4", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, members)
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	members := map[int]*models.ListMember{2: {Id: 200, ListId: 10, UserId: 2, Role: "editor"}}
+
+	// Only the invited user can accept.
+	w := httptest.NewRecorder()
+	setupListMemberRouters(3, members).ServeHTTP(w, jsonRequest("POST", "/AcceptInvitation/200", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	router := setupListMemberRouters(2, members)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("POST", "/AcceptInvitation/200", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotNil(t, members[2].AcceptedAt)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("GET", "/ListMembers/10", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Accepted invitations can't be declined; members leave instead.
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("POST", "/DeclineInvitation/200", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDeclineInvitation(t *testing.T) {
+	members := map[int]*models.ListMember{2: {Id: 200, ListId: 10, UserId: 2, Role: "viewer"}}
+	router := setupListMemberRouters(2, members)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("POST", "/DeclineInvitation/200", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, members)
+}
+
+func TestGetInvitations(t *testing.T) {
+	setupListMemberRouters(2, map[int]*models.ListMember{})
+	storage.ListMemberManager = &m.MockListMemberManager{
+		GetListMembershipsForUserFn: func(userId int, accepted bool) ([]models.ListMember, error) {
+			assert.False(t, accepted)
+			return []models.ListMember{{Id: 200, ListId: 10, UserId: userId, Role: "editor", InvitedBy: 1}}, nil
+		}}
+	r := gin.Default()
+	r.GET("/Invitations", withUser(2, "sid"), app.GetInvitations)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, jsonRequest("GET", "/Invitations", nil))
+
+	var results []h.InvitationResult
+	json.Unmarshal(w.Body.Bytes(), &results)
+	assert.Equal(t, http.StatusOK, w.Code)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "Groceries", results[0].ListTitle)
+		assert.Equal(t, "u1", results[0].InvitedBy)
+		assert.Equal(t, "editor", results[0].Role)
+	}
+}
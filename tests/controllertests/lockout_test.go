@@ -0,0 +1,124 @@
+package controllertests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	h "todo-web-api/helpers"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+	m "todo-web-api/tests/mockmanagers"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func loginRequest(username, password string) *http.Request {
+	jsonValue, _ := json.Marshal(h.User{Username: username, Password: password})
+	req, _ := http.NewRequest("POST", "/Login", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "203.0.113.7:41000"
+	return req
+}
+
+func lockoutUserManager() *m.MockUserManager {
+	existingUser := &models.User{Id: 1, Username: "u1", Role: "user"}
+	existingUser.Password, _ = hashPassword("testpass1")
+	return &m.MockUserManager{FindExistingAccountFn: func(username, password string) (*models.User, error) {
+		return existingUser, nil
+	}}
+}
+
+func TestLogin_LockedAccountReturns423(t *testing.T) {
+	router := setupRouters(lockoutUserManager())
+	storage.LoginAttemptManager = &m.MockLoginAttemptManager{
+		GetLoginAttemptFn: func(subject string) (*models.LoginAttempt, error) {
+			if subject == "user:u1" {
+				return &models.LoginAttempt{Subject: subject, Failures: 5, LockedUntil: time.Now().Add(10 * time.Minute)}, nil
+			}
+			return &models.LoginAttempt{Subject: subject}, nil
+		}}
+
+	w := httptest.NewRecorder()
+	// The right password doesn't help while the account is locked.
+	router.ServeHTTP(w, loginRequest("U1", "testpass1"))
+
+	assert.Equal(t, http.StatusLocked, w.Code)
+	assert.Contains(t, w.Body.String(), messages.AccountLocked)
+	retryAfter, _ := strconv.Atoi(w.Header().Get("Retry-After"))
+	assert.InDelta(t, 600, retryAfter, 2)
+	assert.Empty(t, w.Result().Cookies())
+}
+
+func TestLogin_IPBackoffReturns429(t *testing.T) {
+	router := setupRouters(lockoutUserManager())
+	storage.LoginAttemptManager = &m.MockLoginAttemptManager{
+		GetLoginAttemptFn: func(subject string) (*models.LoginAttempt, error) {
+			if strings.HasPrefix(subject, "ip:") {
+				return &models.LoginAttempt{Subject: subject, Failures: 60, LockedUntil: time.Now().Add(30 * time.Second)}, nil
+			}
+			return nil, nil
+		}}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, loginRequest("u1", "testpass1"))
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
+
+func TestLogin_FailureBacksOffAndLocks(t *testing.T) {
+	router := setupRouters(lockoutUserManager())
+
+	failures := map[string]int{}
+	locks := map[string]time.Time{}
+	storage.LoginAttemptManager = &m.MockLoginAttemptManager{
+		RecordFailedAttemptFn: func(subject string, now time.Time, windowStart time.Time) (*models.LoginAttempt, error) {
+			failures[subject]++
+			return &models.LoginAttempt{Subject: subject, Failures: failures[subject]}, nil
+		},
+		LockLoginAttemptFn: func(subject string, until time.Time) error {
+			locks[subject] = until
+			return nil
+		}}
+
+	for i := 1; i <= 5; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, loginRequest("u1", "wrongpw1"))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		if i == 4 {
+			// Fourth failure: 1s doubled three times.
+			assert.WithinDuration(t, time.Now().Add(8*time.Second), locks["user:u1"], time.Second)
+		}
+	}
+
+	assert.Equal(t, 5, failures["user:u1"])
+	assert.Equal(t, 5, failures["ip:203.0.113.7"])
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), locks["user:u1"], time.Second)
+	// One address isn't throttled until it passes its own, higher limit.
+	_, ipLocked := locks["ip:203.0.113.7"]
+	assert.False(t, ipLocked)
+}
+
+func TestLogin_SuccessResetsUsernameCounter(t *testing.T) {
+	router := setupRouters(lockoutUserManager())
+
+	deleted := []string{}
+	storage.LoginAttemptManager = &m.MockLoginAttemptManager{
+		DeleteLoginAttemptFn: func(subject string) (bool, error) {
+			deleted = append(deleted, subject)
+			return true, nil
+		}}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, loginRequest("u1", "testpass1"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"user:u1"}, deleted)
+}
@@ -0,0 +1,212 @@
+package controllertests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	auth "todo-web-api/authentication"
+	app "todo-web-api/controllers"
+	h "todo-web-api/helpers"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+	m "todo-web-api/tests/mockmanagers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// The RFC 6238 SHA-1 test secret, "12345678901234567890" in base32.
+const testTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func mfaUser() *models.User {
+	user := &models.User{Id: 1, Username: "u1", Role: "user", TOTPSecret: testTOTPSecret, TOTPEnabled: true}
+	user.Password, _ = hashPassword("testpass1")
+	return user
+}
+
+func setupMfaRouters(user *models.User, advanced bool) *gin.Engine {
+	r := gin.Default()
+	storage.UserManager = &m.MockUserManager{
+		FindExistingAccountFn: func(username, password string) (*models.User, error) {
+			return user, nil
+		},
+		GetUserFn: func(id int) (*models.User, error) {
+			return user, nil
+		},
+		UpdateUserFn: func(u *models.User) (int, error) {
+			return u.Id, nil
+		},
+		AdvanceTOTPCounterFn: func(userId int, counter int64) (bool, error) {
+			return advanced, nil
+		}}
+	storage.SessionManager = &m.MockSessionManager{}
+	storage.LoginAttemptManager = &m.MockLoginAttemptManager{}
+	storage.AuditEventManager = &m.MockAuditEventManager{}
+	storage.RecoveryCodeManager = &m.MockRecoveryCodeManager{}
+
+	r.POST("/Login", app.Login)
+	r.POST("/LoginMfa", app.LoginMfa)
+	authed := r.Group("/", withUser(user.Id, "sid"))
+	{
+		authed.POST("/EnrollMfa", app.EnrollMfa)
+		authed.POST("/ConfirmMfa", app.ConfirmMfa)
+		authed.POST("/DisableMfa", app.DisableMfa)
+	}
+	return r
+}
+
+func mfaLoginRequest(body h.MfaLogin) *http.Request {
+	jsonValue, _ := json.Marshal(body)
+	req, _ := http.NewRequest("POST", "/LoginMfa", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	for unix, want := range map[int64]string{59: "287082", 1111111109: "081804", 1234567890: "005924"} {
+		code, err := auth.TOTPCode(testTOTPSecret, time.Unix(unix, 0))
+		assert.Nil(t, err)
+		assert.Equal(t, want, code)
+	}
+}
+
+func TestLogin_MfaEnabledReturnsChallenge(t *testing.T) {
+	router := setupMfaRouters(mfaUser(), true)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, loginRequest("u1", "testpass1"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Result().Cookies())
+
+	var challenge h.MfaChallengeResponse
+	json.Unmarshal(w.Body.Bytes(), &challenge)
+	assert.True(t, challenge.MfaRequired)
+
+	// The mfa token can't stand in for an access token.
+	_, err := auth.ParseToken(challenge.MfaToken)
+	assert.NotNil(t, err)
+}
+
+func TestLoginMfa_ValidCodeStartsSession(t *testing.T) {
+	user := mfaUser()
+	router := setupMfaRouters(user, true)
+	mfaToken, _ := auth.GenerateMfaToken(user)
+	code, _ := auth.TOTPCode(testTOTPSecret, time.Now())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, mfaLoginRequest(h.MfaLogin{MfaToken: mfaToken, Code: code}))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, w.Result().Cookies(), 2)
+}
+
+func TestLoginMfa_ReplayedCodeCountsAsFailure(t *testing.T) {
+	user := mfaUser()
+	// The store refuses to move the counter: this time step was used already.
+	router := setupMfaRouters(user, false)
+	recorded := []string{}
+	storage.LoginAttemptManager = &m.MockLoginAttemptManager{
+		RecordFailedAttemptFn: func(subject string, now time.Time, windowStart time.Time) (*models.LoginAttempt, error) {
+			recorded = append(recorded, subject)
+			return &models.LoginAttempt{Subject: subject, Failures: 1}, nil
+		}}
+	mfaToken, _ := auth.GenerateMfaToken(user)
+	code, _ := auth.TOTPCode(testTOTPSecret, time.Now())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, mfaLoginRequest(h.MfaLogin{MfaToken: mfaToken, Code: code}))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, recorded, "user:u1")
+	assert.Empty(t, w.Result().Cookies())
+}
+
+func TestLoginMfa_RecoveryCode(t *testing.T) {
+	user := mfaUser()
+	router := setupMfaRouters(user, true)
+	used := ""
+	storage.RecoveryCodeManager = &m.MockRecoveryCodeManager{
+		UseRecoveryCodeFn: func(userId int, codeHash string) (bool, error) {
+			used = codeHash
+			return true, nil
+		}}
+	mfaToken, _ := auth.GenerateMfaToken(user)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, mfaLoginRequest(h.MfaLogin{MfaToken: mfaToken, RecoveryCode: "ABCDE-fghij"}))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, tokenDigest("abcdefghij"), used)
+}
+
+func TestLoginMfa_RejectsAccessToken(t *testing.T) {
+	user := mfaUser()
+	router := setupMfaRouters(user, true)
+	accessToken, _ := auth.GenerateAccessToken("u1", 1, "sid", nil)
+	code, _ := auth.TOTPCode(testTOTPSecret, time.Now())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, mfaLoginRequest(h.MfaLogin{MfaToken: accessToken, Code: code}))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), messages.InvalidMfaToken)
+}
+
+func TestEnrollAndConfirmMfa(t *testing.T) {
+	user := &models.User{Id: 1, Username: "u1", Role: "user"}
+	router := setupMfaRouters(user, true)
+	var stored []string
+	storage.RecoveryCodeManager = &m.MockRecoveryCodeManager{
+		ReplaceRecoveryCodesFn: func(userId int, codeHashes []string) error {
+			stored = codeHashes
+			return nil
+		}}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/EnrollMfa", nil)
+	router.ServeHTTP(w, req)
+
+	var enroll h.MfaEnrollResponse
+	json.Unmarshal(w.Body.Bytes(), &enroll)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.HasPrefix(enroll.OtpauthUri, "otpauth://totp/"))
+	assert.Equal(t, enroll.Secret, user.TOTPSecret)
+	assert.False(t, user.TOTPEnabled)
+
+	code, _ := auth.TOTPCode(enroll.Secret, time.Now())
+	jsonValue, _ := json.Marshal(h.MfaCode{Code: code})
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/ConfirmMfa", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	var confirm h.RecoveryCodesResponse
+	json.Unmarshal(w.Body.Bytes(), &confirm)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, user.TOTPEnabled)
+	assert.Len(t, confirm.RecoveryCodes, 10)
+	assert.Len(t, stored, 10)
+	// Only digests are stored.
+	assert.NotContains(t, stored, confirm.RecoveryCodes[0])
+	assert.Equal(t, tokenDigest(strings.ReplaceAll(confirm.RecoveryCodes[0], "-", "")), stored[0])
+}
+
+func TestConfirmMfa_WrongCode(t *testing.T) {
+	user := &models.User{Id: 1, Username: "u1", Role: "user", TOTPSecret: testTOTPSecret}
+	router := setupMfaRouters(user, true)
+
+	jsonValue, _ := json.Marshal(h.MfaCode{Code: "12345x"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/ConfirmMfa", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.False(t, user.TOTPEnabled)
+}
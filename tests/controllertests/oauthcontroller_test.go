@@ -0,0 +1,212 @@
+package controllertests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	auth "todo-web-api/authentication"
+	authz "todo-web-api/authorization"
+	app "todo-web-api/controllers"
+	h "todo-web-api/helpers"
+	"todo-web-api/messages"
+	"todo-web-api/middleware"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+	m "todo-web-api/tests/mockmanagers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+const oauthClientSecret = "s3rvice-secret-0123"
+
+// setupOAuthRouters signs u1 in with session sid and returns its token pair.
+// deleted collects the sessions revoked through /oauth/revoke.
+func setupOAuthRouters(t *testing.T, deleted *[]string) (*gin.Engine, string, string) {
+	assert.Nil(t, auth.ConfigureOAuthClients([]auth.OAuthClient{{ClientID: "reports", ClientSecret: oauthClientSecret}}))
+	accessToken, _ := auth.GenerateAccessToken("u1", 1, "sid", []string{authz.RoleUser})
+	refreshToken, _ := auth.GenerateRefreshToken(1, "u1", "sid")
+	storage.SessionManager = &m.MockSessionManager{
+		GetSessionFn: func(sessionId string) (*models.Session, error) {
+			for _, id := range *deleted {
+				if id == sessionId {
+					return nil, nil
+				}
+			}
+			return &models.Session{SessionId: "sid", UserId: 1, AccessToken: tokenDigest(accessToken),
+				RefreshToken: tokenDigest(refreshToken), ExpiresAt: time.Now().Add(time.Hour)}, nil
+		},
+		DeleteSessionFn: func(sessionId string) (bool, error) {
+			*deleted = append(*deleted, sessionId)
+			return true, nil
+		}}
+
+	r := gin.Default()
+	oauth := r.Group("/oauth", middleware.RequireOAuthClient())
+	{
+		oauth.POST("/introspect", app.Introspect)
+		oauth.POST("/revoke", app.Revoke)
+	}
+	return r, accessToken, refreshToken
+}
+
+func oauthRequest(path string, form url.Values) *http.Request {
+	req, _ := http.NewRequest("POST", path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("reports", oauthClientSecret)
+	return req
+}
+
+func introspect(t *testing.T, router *gin.Engine, token string) h.IntrospectionResponse {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, oauthRequest("/oauth/introspect", url.Values{"token": {token}}))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+	var resp h.IntrospectionResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp
+}
+
+func TestIntrospect_ActiveAccessToken(t *testing.T) {
+	router, accessToken, _ := setupOAuthRouters(t, &[]string{})
+
+	resp := introspect(t, router, accessToken)
+
+	assert.True(t, resp.Active)
+	assert.Equal(t, "Bearer", resp.TokenType)
+	assert.Equal(t, "1", resp.Sub)
+	assert.Equal(t, "u1", resp.Username)
+	assert.Equal(t, "sid", resp.Sid)
+	assert.Equal(t, "Todo-Service", resp.Iss)
+	assert.Contains(t, strings.Fields(resp.Scope), authz.PermListsRead)
+	assert.Greater(t, resp.Exp, time.Now().Unix())
+}
+
+func TestIntrospect_RefreshTokenHasNoScope(t *testing.T) {
+	router, _, refreshToken := setupOAuthRouters(t, &[]string{})
+
+	resp := introspect(t, router, refreshToken)
+
+	assert.True(t, resp.Active)
+	assert.Equal(t, "refresh_token", resp.TokenType)
+	assert.Empty(t, resp.Scope)
+}
+
+func TestIntrospect_InactiveTokensSayOnlyThat(t *testing.T) {
+	router, _, _ := setupOAuthRouters(t, &[]string{})
+	rotatedAway, _ := auth.GenerateAccessToken("u1", 1, "sid", nil)
+
+	for _, token := range []string{"garbage", rotatedAway, auth.AccessTokenPrefix + "unknown"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, oauthRequest("/oauth/introspect", url.Values{"token": {token}}))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"active":false}`, w.Body.String())
+	}
+}
+
+func TestIntrospect_PersonalAccessTokenWithPostedCredentials(t *testing.T) {
+	router, _, _ := setupOAuthRouters(t, &[]string{})
+	plain := auth.AccessTokenPrefix + "script-token"
+	storage.UserManager = &m.MockUserManager{GetUserFn: func(id int) (*models.User, error) {
+		return &models.User{Id: 1, Username: "u1", Role: authz.RoleUser}, nil
+	}}
+	storage.AccessTokenManager = &m.MockAccessTokenManager{GetAccessTokenByHashFn: func(tokenHash string) (*models.PersonalAccessToken, error) {
+		assert.Equal(t, tokenDigest(plain), tokenHash)
+		return &models.PersonalAccessToken{Id: 3, UserId: 1, Scopes: "lists:read", CreatedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)}, nil
+	}}
+
+	form := url.Values{"token": {plain}, "token_type_hint": {"access_token"}, "client_id": {"reports"}, "client_secret": {oauthClientSecret}}
+	req, _ := http.NewRequest("POST", "/oauth/introspect", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp h.IntrospectionResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, resp.Active)
+	assert.Equal(t, "lists:read", resp.Scope)
+	assert.Empty(t, resp.Sid)
+	assert.NotZero(t, resp.Iat)
+}
+
+func TestOAuth_RejectsUnknownClient(t *testing.T) {
+	router, accessToken, _ := setupOAuthRouters(t, &[]string{})
+
+	for _, path := range []string{"/oauth/introspect", "/oauth/revoke"} {
+		req := oauthRequest(path, url.Values{"token": {accessToken}})
+		req.SetBasicAuth("reports", "wrong-secret-0123456")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var resp h.OAuthErrorResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, "invalid_client", resp.Error)
+		assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+	}
+}
+
+func TestRevoke_EndsSession(t *testing.T) {
+	deleted := []string{}
+	router, accessToken, refreshToken := setupOAuthRouters(t, &deleted)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, oauthRequest("/oauth/revoke", url.Values{"token": {refreshToken}, "token_type_hint": {"refresh_token"}}))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"sid"}, deleted)
+
+	// The access token of the session goes with it.
+	assert.False(t, introspect(t, router, accessToken).Active)
+
+	// Revoking again is not an error.
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, oauthRequest("/oauth/revoke", url.Values{"token": {refreshToken}}))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, deleted, 1)
+}
+
+func TestRevoke_PersonalAccessToken(t *testing.T) {
+	router, _, _ := setupOAuthRouters(t, &[]string{})
+	revoked := 0
+	storage.AccessTokenManager = &m.MockAccessTokenManager{
+		GetAccessTokenByHashFn: func(tokenHash string) (*models.PersonalAccessToken, error) {
+			return &models.PersonalAccessToken{Id: 3, UserId: 1}, nil
+		},
+		DeleteAccessTokenFn: func(userId int, id int) (bool, error) {
+			assert.Equal(t, 1, userId)
+			revoked = id
+			return true, nil
+		}}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, oauthRequest("/oauth/revoke", url.Values{"token": {auth.AccessTokenPrefix + "script-token"}}))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 3, revoked)
+}
+
+func TestRevoke_RequiresToken(t *testing.T) {
+	router, _, _ := setupOAuthRouters(t, &[]string{})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, oauthRequest("/oauth/revoke", url.Values{}))
+
+	var resp h.OAuthErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "invalid_request", resp.Error)
+	assert.Equal(t, messages.TokenRequired, resp.ErrorDescription)
+}
+
+func TestConfigureOAuthClients_RejectsShortSecrets(t *testing.T) {
+	assert.NotNil(t, auth.ConfigureOAuthClients([]auth.OAuthClient{{ClientID: "reports", ClientSecret: "short"}}))
+	assert.NotNil(t, auth.ConfigureOAuthClients([]auth.OAuthClient{
+		{ClientID: "reports", ClientSecret: oauthClientSecret},
+		{ClientID: "reports", ClientSecret: oauthClientSecret}}))
+}
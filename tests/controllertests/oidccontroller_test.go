@@ -0,0 +1,274 @@
+package controllertests
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	auth "todo-web-api/authentication"
+	app "todo-web-api/controllers"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+	m "todo-web-api/tests/mockmanagers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	oidcClientID     = "todo-client"
+	oidcClientSecret = "s3cret"
+	oidcRedirectURL  = "http://todo.test/OidcCallback/corp"
+	oidcPostLogin    = "https://app.todo.test/"
+)
+
+// fakeIdP is a minimal OpenID provider: discovery, an authorize endpoint
+// that signs the user in straight away, a token endpoint that checks the
+// client and the PKCE verifier, and a JWKS.
+type fakeIdP struct {
+	server        *httptest.Server
+	key           *rsa.PrivateKey
+	subject       string
+	email         string
+	emailVerified bool
+	grants        map[string]url.Values
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	idp := &fakeIdP{key: key, subject: "corp-123", email: "Ada@Corp.example", emailVerified: true, grants: map[string]url.Values{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		code := "code-" + query.Get("state")[:8]
+		idp.grants[code] = query
+		http.Redirect(w, r, query.Get("redirect_uri")+"?code="+code+"&state="+url.QueryEscape(query.Get("state")), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		grant, ok := idp.grants[r.PostForm.Get("code")]
+		delete(idp.grants, r.PostForm.Get("code"))
+		clientID, secret, _ := r.BasicAuth()
+		verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if !ok || clientID != oidcClientID || secret != oidcClientSecret ||
+			r.PostForm.Get("redirect_uri") != grant.Get("redirect_uri") ||
+			base64.RawURLEncoding.EncodeToString(verifier[:]) != grant.Get("code_challenge") {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":            idp.server.URL,
+			"aud":            oidcClientID,
+			"sub":            idp.subject,
+			"email":          idp.email,
+			"email_verified": idp.emailVerified,
+			"nonce":          grant.Get("nonce"),
+			"exp":            time.Now().Add(5 * time.Minute).Unix(),
+		})
+		token.Header["kid"] = "idp-1"
+		idToken, _ := token.SignedString(idp.key)
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idToken, "access_token": "at", "token_type": "Bearer"})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(auth.JWKS{Keys: []auth.JWK{{
+			Kty: "RSA", Kid: "idp-1", Use: "sig", Alg: "RS256",
+			N: base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func setupOidcRouters(t *testing.T, idp *fakeIdP, allowSignup bool) *gin.Engine {
+	err := auth.ConfigureOIDC([]auth.OIDCProviderConfig{{
+		Name: "corp", Issuer: idp.server.URL, ClientID: oidcClientID, ClientSecret: oidcClientSecret,
+		RedirectURL: oidcRedirectURL, AllowSignup: allowSignup,
+	}}, oidcPostLogin)
+	assert.Nil(t, err)
+	t.Cleanup(func() { auth.ConfigureOIDC(nil, "") })
+
+	storage.SessionManager = &m.MockSessionManager{}
+	storage.LoginAttemptManager = &m.MockLoginAttemptManager{}
+	storage.AuditEventManager = &m.MockAuditEventManager{}
+	storage.ExternalIdentityManager = &m.MockExternalIdentityManager{}
+
+	r := gin.Default()
+	r.GET("/OidcLogin/:provider", app.OidcLogin)
+	r.GET("/OidcCallback/:provider", app.OidcCallback)
+	return r
+}
+
+// signInAtIdP runs the browser's side of the flow up to the callback:
+// start the login, follow the redirect to the provider and back.
+func signInAtIdP(t *testing.T, router *gin.Engine) (callback *http.Request) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/OidcLogin/corp", nil)
+	router.ServeHTTP(w, req)
+	if !assert.Equal(t, http.StatusFound, w.Code) {
+		t.FailNow()
+	}
+	authorize := w.Header().Get("Location")
+	assert.Contains(t, authorize, "code_challenge_method=S256")
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authorize)
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	back, _ := url.Parse(resp.Header.Get("Location"))
+
+	callback, _ = http.NewRequest("GET", "/OidcCallback/corp?"+back.RawQuery, nil)
+	for _, cookie := range w.Result().Cookies() {
+		callback.AddCookie(cookie)
+	}
+	return callback
+}
+
+func TestOidcLogin_SignupCreatesLinkedAccount(t *testing.T) {
+	idp := newFakeIdP(t)
+	router := setupOidcRouters(t, idp, true)
+
+	var created *models.User
+	var linked *models.ExternalIdentity
+	storage.UserManager = &m.MockUserManager{
+		GetUserByVerifiedEmailFn: func(email string) (*models.User, error) {
+			return nil, errors.New(messages.UserNotFound)
+		},
+		FindExistingAccountFn: func(username, password string) (*models.User, error) {
+			return nil, errors.New(messages.AccountNotFound)
+		},
+		CreateUserFn: func(user *models.User) (int, error) {
+			created = user
+			return 7, nil
+		}}
+	storage.ExternalIdentityManager = &m.MockExternalIdentityManager{
+		CreateExternalIdentityFn: func(identity *models.ExternalIdentity) (int, error) {
+			linked = identity
+			return 1, nil
+		}}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, signInAtIdP(t, router))
+
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, oidcPostLogin, w.Header().Get("Location"))
+	cookies := map[string]string{}
+	for _, cookie := range w.Result().Cookies() {
+		cookies[cookie.Name] = cookie.Value
+	}
+	assert.NotEmpty(t, cookies["access_token"])
+	assert.NotEmpty(t, cookies["refresh_token"])
+	assert.Equal(t, "", cookies["oidc_state"])
+
+	if assert.NotNil(t, created) && assert.NotNil(t, linked) {
+		assert.Equal(t, "ada@corp.example", created.Username)
+		assert.True(t, created.EmailVerified)
+		if assert.NotNil(t, created.VerifiedEmail) {
+			assert.Equal(t, "ada@corp.example", *created.VerifiedEmail)
+		}
+		assert.Equal(t, 7, linked.UserId)
+		assert.Equal(t, "corp", linked.Provider)
+		assert.Equal(t, "corp-123", linked.Subject)
+	}
+}
+
+func TestOidcLogin_LinkedIdentitySignsIn(t *testing.T) {
+	idp := newFakeIdP(t)
+	router := setupOidcRouters(t, idp, false)
+
+	user := &models.User{Id: 3, Username: "ada", Role: "user"}
+	storage.UserManager = &m.MockUserManager{GetUserFn: func(id int) (*models.User, error) {
+		assert.Equal(t, 3, id)
+		return user, nil
+	}}
+	storage.ExternalIdentityManager = &m.MockExternalIdentityManager{
+		GetExternalIdentityFn: func(provider, subject string) (*models.ExternalIdentity, error) {
+			if provider == "corp" && subject == "corp-123" {
+				return &models.ExternalIdentity{Id: 1, UserId: 3, Provider: provider, Subject: subject}, nil
+			}
+			return nil, errors.New(messages.ExternalIdentityNotFoundInDb)
+		}}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, signInAtIdP(t, router))
+
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Len(t, w.Result().Cookies(), 3)
+}
+
+func TestOidcLogin_SignupDisabled(t *testing.T) {
+	idp := newFakeIdP(t)
+	idp.emailVerified = false
+	router := setupOidcRouters(t, idp, false)
+	storage.UserManager = &m.MockUserManager{}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, signInAtIdP(t, router))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), messages.OIDCSignupDisabled)
+}
+
+func TestOidcCallback_StateMismatch(t *testing.T) {
+	idp := newFakeIdP(t)
+	router := setupOidcRouters(t, idp, true)
+
+	callback := signInAtIdP(t, router)
+	query := callback.URL.Query()
+	query.Set("state", "forged")
+	callback.URL.RawQuery = query.Encode()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, callback)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), messages.InvalidOIDCState)
+}
+
+func TestOidcCallback_WithoutStateCookie(t *testing.T) {
+	idp := newFakeIdP(t)
+	router := setupOidcRouters(t, idp, true)
+
+	callback := signInAtIdP(t, router)
+	stripped, _ := http.NewRequest("GET", callback.URL.String(), nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, stripped)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestOidcLogin_UnknownProvider(t *testing.T) {
+	idp := newFakeIdP(t)
+	router := setupOidcRouters(t, idp, true)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/OidcLogin/nope", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.False(t, strings.Contains(w.Header().Get("Location"), "authorize"))
+}
@@ -0,0 +1,153 @@
+package controllertests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	authz "todo-web-api/authorization"
+	app "todo-web-api/controllers"
+	h "todo-web-api/helpers"
+	"todo-web-api/messages"
+	"todo-web-api/middleware"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+	m "todo-web-api/tests/mockmanagers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// Lists 10 to 50 belong to users 1 to 5, and task 100 is in list 10, task
+// 200 in list 20 and so on. User 1 is an editor of list 30, a viewer of
+// list 40 and has yet to accept an invitation to list 50.
+func setupOwnershipRouters(userId int) *gin.Engine {
+	r := gin.Default()
+	storage.UserManager = &m.MockUserManager{GetUserFn: func(id int) (*models.User, error) {
+		return &models.User{Id: id}, nil
+	}}
+	storage.ListManager = &m.MockListManager{
+		GetListFn: func(id int) (*models.List, error) {
+			if id%10 != 0 || id < 10 || id > 50 {
+				return nil, errors.New(messages.ListNotFoundInDb)
+			}
+			return &models.List{Id: id, UserId: id / 10}, nil
+		},
+		GetListForUserFn: func(id int) (*models.List, error) {
+			return &models.List{Id: id * 10, UserId: id}, nil
+		},
+		DeleteListFn: func(id int) (bool, error) {
+			return true, nil
+		}}
+	storage.ListMemberManager = &m.MockListMemberManager{GetListMemberFn: func(listId int, memberId int) (*models.ListMember, error) {
+		accepted := time.Now()
+		members := map[int]*models.ListMember{
+			30: {ListId: 30, UserId: 1, Role: authz.ListRoleEditor, AcceptedAt: &accepted},
+			40: {ListId: 40, UserId: 1, Role: authz.ListRoleViewer, AcceptedAt: &accepted},
+			50: {ListId: 50, UserId: 1, Role: authz.ListRoleEditor}}
+		if member, ok := members[listId]; ok && memberId == member.UserId {
+			return member, nil
+		}
+		return nil, errors.New(messages.ListMemberNotFoundInDb)
+	}}
+	storage.TaskManager = &m.MockTaskManager{
+		GetTaskFn: func(id int) (*models.Task, error) {
+			return &models.Task{Id: id, ListId: id / 10}, nil
+		},
+		DeleteTaskFn: func(id int) (bool, error) {
+			return true, nil
+		}}
+
+	auth := r.Group("/", withUser(userId, "sid"))
+	{
+		auth.GET("/GetUser/:id", middleware.RequireOwner(authz.UserOwner, "id"), app.GetUserById)
+		auth.GET("/GetList/:userid", middleware.RequireOwner(authz.UserOwner, "userid"), app.GetListByUserId)
+		auth.DELETE("/DeleteList/:id", middleware.RequireListRole(authz.ListById, "id", authz.ListRoleOwner), app.DeleteList)
+		auth.POST("/CreateTask/:listid", middleware.RequireListRole(authz.ListById, "listid", authz.ListRoleEditor), app.AddTaskToList)
+		auth.DELETE("/DeleteTask/:id", middleware.RequireListRole(authz.ListOfTask, "id", authz.ListRoleEditor), app.DeleteTask)
+		auth.PUT("/UpdateTask/:id", middleware.RequireListRole(authz.ListOfTask, "id", authz.ListRoleEditor), app.UpdateTask)
+		auth.PUT("/TaskCompleted/:id", middleware.RequireListRole(authz.ListOfTask, "id", authz.ListRoleEditor), app.ChangeStatus)
+	}
+	return r
+}
+
+func Test_Ownership_Cases(t *testing.T) {
+	task, _ := json.Marshal(h.SaveTask{Title: "test_task"})
+	status, _ := json.Marshal(h.SetStatus{IsCompleted: true})
+
+	var tests = []struct {
+		name   string
+		method string
+		url    string
+		body   []byte
+		want   int
+	}{
+		{"Own user", "GET", "/GetUser/1", nil, 200},
+		{"Other user", "GET", "/GetUser/2", nil, 403},
+		{"Own list by user", "GET", "/GetList/1", nil, 200},
+		{"Other user's list by user", "GET", "/GetList/2", nil, 403},
+		{"Delete own list", "DELETE", "/DeleteList/10", nil, 200},
+		{"Delete other user's list", "DELETE", "/DeleteList/20", nil, 403},
+		{"Missing list", "DELETE", "/DeleteList/90", nil, 404},
+		{"Task in a missing list", "DELETE", "/DeleteTask/900", nil, 404},
+		{"Editor can't delete the list", "DELETE", "/DeleteList/30", nil, 403},
+		{"Add task as editor", "POST", "/CreateTask/30", task, 200},
+		{"Add task as viewer", "POST", "/CreateTask/40", task, 403},
+		{"Add task before accepting the invitation", "POST", "/CreateTask/50", task, 403},
+		{"Complete task as editor", "PUT", "/TaskCompleted/300", status, 200},
+		{"Delete task as viewer", "DELETE", "/DeleteTask/400", nil, 403},
+		{"Add task to own list", "POST", "/CreateTask/10", task, 200},
+		{"Add task to other user's list", "POST", "/CreateTask/20", task, 403},
+		{"Delete own task", "DELETE", "/DeleteTask/100", nil, 200},
+		{"Delete other user's task", "DELETE", "/DeleteTask/200", nil, 403},
+		{"Update other user's task", "PUT", "/UpdateTask/200", task, 403},
+		{"Complete other user's task", "PUT", "/TaskCompleted/200", status, 403},
+		{"Invalid id", "GET", "/GetUser/abc", nil, 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupOwnershipRouters(1)
+			w := httptest.NewRecorder()
+
+			req, _ := http.NewRequest(tt.method, tt.url, bytes.NewBuffer(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.want, w.Code, fmt.Sprintf("%s %s", tt.method, tt.url))
+			if tt.want == 403 {
+				assert.Contains(t, w.Body.String(), messages.Forbidden)
+			}
+		})
+	}
+}
+
+func TestRequireOwner_MissingResource(t *testing.T) {
+	var tests = []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"Not found", errors.New(messages.ListTemplateNotFoundInDb), 404},
+		{"Lookup failed", errors.New("template lookup failed: not found in cache"), 500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reached := false
+			r := gin.Default()
+			resolve := func(id int) (int, error) { return 0, tt.err }
+			r.DELETE("/ListTemplates/:id", withUser(1, "sid"), middleware.RequireOwner(resolve, "id"), func(c *gin.Context) {
+				reached = true
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, jsonRequest("DELETE", "/ListTemplates/7", nil))
+
+			assert.Equal(t, tt.want, w.Code)
+			assert.False(t, reached)
+		})
+	}
+}
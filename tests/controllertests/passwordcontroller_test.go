@@ -0,0 +1,256 @@
+package controllertests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+	auth "todo-web-api/authentication"
+	app "todo-web-api/controllers"
+	h "todo-web-api/helpers"
+	"todo-web-api/mailer"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+	m "todo-web-api/tests/mockmanagers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryResetTokens keeps one-time tokens in a map, redeeming them the way
+// the stores do.
+func memoryResetTokens() *m.MockOneTimeTokenManager {
+	tokens := map[string]*models.OneTimeToken{}
+	return &m.MockOneTimeTokenManager{
+		SaveOneTimeTokenFn: func(token *models.OneTimeToken) (int, error) {
+			tokens[token.TokenHash] = token
+			return len(tokens), nil
+		},
+		ConsumeOneTimeTokenFn: func(purpose string, tokenHash string, now time.Time) (*models.OneTimeToken, error) {
+			token, ok := tokens[tokenHash]
+			if !ok || token.Purpose != purpose || token.UsedAt != nil || !token.ExpiresAt.After(now) {
+				return nil, errors.New(messages.OneTimeTokenNotFoundInDb)
+			}
+			token.UsedAt = &now
+			return token, nil
+		},
+	}
+}
+
+func setupPasswordRouters(user *models.User, sessionManager m.ISessionMockManager) *gin.Engine {
+	r := gin.Default()
+	storage.UserManager = &m.MockUserManager{
+		FindExistingAccountFn: func(username, password string) (*models.User, error) {
+			if username != user.Username {
+				return nil, errors.New(messages.AccountNotFound)
+			}
+			return user, nil
+		},
+		GetUserFn: func(id int) (*models.User, error) {
+			return user, nil
+		},
+		UpdateUserFn: func(u *models.User) (int, error) {
+			return u.Id, nil
+		}}
+	storage.SessionManager = sessionManager
+	storage.LoginAttemptManager = &m.MockLoginAttemptManager{}
+	storage.AuditEventManager = &m.MockAuditEventManager{}
+	storage.OneTimeTokenManager = memoryResetTokens()
+
+	r.POST("/ForgotPassword", app.ForgotPassword)
+	r.POST("/ResetPassword", app.ResetPassword)
+	authed := r.Group("/", withUser(user.Id, "sid"))
+	{
+		authed.PUT("/ChangePassword", app.ChangePassword)
+	}
+	return r
+}
+
+func jsonRequest(method, path string, body interface{}) *http.Request {
+	jsonValue, _ := json.Marshal(body)
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+// sentResetToken reads the token out of the one reset mail in dir.
+func sentResetToken(t *testing.T, dir string) string {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if !assert.Len(t, files, 1) {
+		t.FailNow()
+	}
+	mail, _ := os.ReadFile(files[0])
+	match := regexp.MustCompile(`\?token=([A-Za-z0-9_-]+)`).FindSubmatch(mail)
+	if !assert.NotNil(t, match) {
+		t.FailNow()
+	}
+	return string(match[1])
+}
+
+// verifies reports whether password matches a stored hash, whichever
+// hasher made it.
+func verifies(hash, password string) bool {
+	match, _ := auth.VerifyPassword(hash, password)
+	return match
+}
+
+func passwordUser() *models.User {
+	user := &models.User{Id: 1, Username: "u1", Role: "user", Email: "u1@example.com", EmailVerified: true}
+	user.Password, _ = hashPassword("testpass1")
+	return user
+}
+
+func TestChangePassword_RevokesOtherSessions(t *testing.T) {
+	user := passwordUser()
+	kept := ""
+	router := setupPasswordRouters(user, &m.MockSessionManager{
+		DeleteOtherSessionsFn: func(userId int, keepSessionId string) (int64, error) {
+			kept = keepSessionId
+			return 2, nil
+		}})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("PUT", "/ChangePassword", h.ChangePassword{CurrentPassword: "testpass1", NewPassword: "newpass22"}))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "sid", kept)
+	assert.True(t, verifies(user.Password, "newpass22"))
+}
+
+func TestChangePassword_WrongCurrentPassword(t *testing.T) {
+	user := passwordUser()
+	revoked := false
+	router := setupPasswordRouters(user, &m.MockSessionManager{
+		DeleteOtherSessionsFn: func(userId int, keepSessionId string) (int64, error) {
+			revoked = true
+			return 0, nil
+		}})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("PUT", "/ChangePassword", h.ChangePassword{CurrentPassword: "wrongpw1", NewPassword: "newpass22"}))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), messages.InvalidCurrentPassword)
+	assert.False(t, revoked)
+	assert.True(t, verifies(user.Password, "testpass1"))
+}
+
+func TestPasswordReset_TokenWorksOnce(t *testing.T) {
+	dir := t.TempDir()
+	mailer.SetSender(mailer.FileSender{Dir: dir})
+	defer mailer.SetSender(mailer.LogSender{})
+	auth.ConfigurePasswordReset("https://todo.example/reset", 0)
+
+	user := passwordUser()
+	revokedFor := 0
+	router := setupPasswordRouters(user, &m.MockSessionManager{
+		DeleteSessionsForUserFn: func(userId int) (int64, error) {
+			revokedFor = userId
+			return 1, nil
+		}})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("POST", "/ForgotPassword", h.ForgotPassword{Username: user.Username}))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	token := sentResetToken(t, dir)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("POST", "/ResetPassword", h.ResetPassword{Token: token, NewPassword: "newpass22"}))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, user.Id, revokedFor)
+	assert.True(t, verifies(user.Password, "newpass22"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("POST", "/ResetPassword", h.ResetPassword{Token: token, NewPassword: "another33"}))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), messages.InvalidResetToken)
+}
+
+func TestPasswordReset_ExpiredToken(t *testing.T) {
+	dir := t.TempDir()
+	mailer.SetSender(mailer.FileSender{Dir: dir})
+	defer mailer.SetSender(mailer.LogSender{})
+	auth.ConfigurePasswordReset("https://todo.example/reset", 0)
+
+	user := passwordUser()
+	router := setupPasswordRouters(user, &m.MockSessionManager{})
+	saved := storage.OneTimeTokenManager.(*m.MockOneTimeTokenManager)
+	save := saved.SaveOneTimeTokenFn
+	saved.SaveOneTimeTokenFn = func(token *models.OneTimeToken) (int, error) {
+		token.ExpiresAt = time.Now().Add(-time.Minute)
+		return save(token)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("POST", "/ForgotPassword", h.ForgotPassword{Username: user.Username}))
+	token := sentResetToken(t, dir)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("POST", "/ResetPassword", h.ResetPassword{Token: token, NewPassword: "newpass22"}))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.True(t, verifies(user.Password, "testpass1"))
+}
+
+func TestForgotPassword_UnverifiedEmailGetsNoMail(t *testing.T) {
+	dir := t.TempDir()
+	mailer.SetSender(mailer.FileSender{Dir: dir})
+	defer mailer.SetSender(mailer.LogSender{})
+
+	user := passwordUser()
+	user.EmailVerified = false
+	router := setupPasswordRouters(user, &m.MockSessionManager{})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("POST", "/ForgotPassword", h.ForgotPassword{Username: user.Username}))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	assert.Empty(t, files)
+}
+
+func TestForgotPassword_UnknownUserLooksTheSame(t *testing.T) {
+	dir := t.TempDir()
+	mailer.SetSender(mailer.FileSender{Dir: dir})
+	defer mailer.SetSender(mailer.LogSender{})
+
+	router := setupPasswordRouters(passwordUser(), &m.MockSessionManager{})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("POST", "/ForgotPassword", h.ForgotPassword{Username: "nobody"}))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), messages.SuccessPasswordResetRequested)
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	assert.Empty(t, files)
+}
+
+func TestConfigureMailer_LocalSendersOnlyInDevelopment(t *testing.T) {
+	defer mailer.SetSender(mailer.LogSender{})
+	var tests = []struct {
+		name       string
+		config     mailer.Config
+		wantFailed bool
+	}{
+		{"Default outside development", mailer.Config{}, true},
+		{"Log outside development", mailer.Config{Sender: "log"}, true},
+		{"File outside development", mailer.Config{Sender: "file", Dir: "mail"}, true},
+		{"Log in development", mailer.Config{Sender: "log", AllowLocal: true}, false},
+		{"File in development", mailer.Config{Sender: "file", Dir: "mail", AllowLocal: true}, false},
+		{"SMTP", mailer.Config{Sender: "smtp", SMTPHost: "smtp.example.com", From: "todo@example.com"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mailer.Configure(tt.config)
+			assert.Equal(t, tt.wantFailed, err != nil)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package controllertests
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	auth "todo-web-api/authentication"
+	h "todo-web-api/helpers"
+	"todo-web-api/mailer"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+	m "todo-web-api/tests/mockmanagers"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// breachedDir writes a range file holding the given passwords, the way the
+// breached-password downloader lays them out.
+func breachedDir(t *testing.T, passwords ...string) string {
+	dir := t.TempDir()
+	for _, password := range passwords {
+		sum := sha1.Sum([]byte(password))
+		digest := strings.ToUpper(hex.EncodeToString(sum[:]))
+		file, _ := os.OpenFile(filepath.Join(dir, digest[:5]+".txt"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		file.WriteString("0018A45C4D1DEF81644B54AB7F969B88D65:3\r\n" + digest[5:] + ":24230577\r\n")
+		file.Close()
+	}
+	return dir
+}
+
+func violatedRules(t *testing.T, w *httptest.ResponseRecorder) []string {
+	var resp h.PasswordPolicyResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, messages.PasswordPolicyViolated, resp.Message)
+	rules := []string{}
+	for _, violation := range resp.Violations {
+		rules = append(rules, violation.Rule)
+	}
+	return rules
+}
+
+func TestRegister_ListsEveryViolatedRule(t *testing.T) {
+	assert.Nil(t, auth.ConfigurePasswordPolicy(auth.PasswordPolicy{MinLength: 10, RequireUpper: true, RequireDigit: true, RequireSymbol: true}))
+	defer auth.ConfigurePasswordPolicy(auth.DefaultPasswordPolicy())
+	created := false
+	router := setupRouters(&m.MockUserManager{CreateUserFn: func(user *models.User) (int, error) {
+		created = true
+		return 1, nil
+	}})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("POST", "/api/v1/Register", h.Register{Username: "alice", Password: "alicepw"}))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.ElementsMatch(t, []string{"min_length", "uppercase", "digit", "symbol", "username"}, violatedRules(t, w))
+	assert.False(t, created)
+}
+
+func TestRegister_RejectsPasswordPastBcryptLimit(t *testing.T) {
+	router := setupRouters(&m.MockUserManager{CreateUserFn: func(user *models.User) (int, error) {
+		return 1, nil
+	}})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("POST", "/api/v1/Register", h.Register{Username: "alice", Password: strings.Repeat("x", 73)}))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, []string{"max_length"}, violatedRules(t, w))
+}
+
+func TestRegister_RejectsBreachedPassword(t *testing.T) {
+	assert.Nil(t, auth.ConfigurePasswordPolicy(auth.PasswordPolicy{BreachedPasswordsDir: breachedDir(t, "password1")}))
+	defer auth.ConfigurePasswordPolicy(auth.DefaultPasswordPolicy())
+	router := setupRouters(&m.MockUserManager{CreateUserFn: func(user *models.User) (int, error) {
+		return 1, nil
+	}})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("POST", "/api/v1/Register", h.Register{Username: "alice", Password: "password1"}))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, []string{"breached"}, violatedRules(t, w))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("POST", "/api/v1/Register", h.Register{Username: "alice", Password: "tangerine-kettle"}))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestChangePassword_EnforcesPolicy(t *testing.T) {
+	user := passwordUser()
+	router := setupPasswordRouters(user, &m.MockSessionManager{})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("PUT", "/ChangePassword", h.ChangePassword{CurrentPassword: "testpass1", NewPassword: "my-u1-pass"}))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, []string{"username"}, violatedRules(t, w))
+	assert.True(t, verifies(user.Password, "testpass1"))
+}
+
+func TestResetPassword_WeakPasswordKeepsToken(t *testing.T) {
+	dir := t.TempDir()
+	mailer.SetSender(mailer.FileSender{Dir: dir})
+	defer mailer.SetSender(mailer.LogSender{})
+	auth.ConfigurePasswordReset("https://todo.example/reset", 0)
+
+	user := passwordUser()
+	router := setupPasswordRouters(user, &m.MockSessionManager{})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("POST", "/ForgotPassword", h.ForgotPassword{Username: user.Username}))
+	token := sentResetToken(t, dir)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("POST", "/ResetPassword", h.ResetPassword{Token: token, NewPassword: "short"}))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, []string{"min_length"}, violatedRules(t, w))
+
+	// The refused attempt didn't spend the link.
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("POST", "/ResetPassword", h.ResetPassword{Token: token, NewPassword: "newpass22"}))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
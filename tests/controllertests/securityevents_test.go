@@ -0,0 +1,113 @@
+package controllertests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	app "todo-web-api/controllers"
+	h "todo-web-api/helpers"
+	"todo-web-api/messages"
+	"todo-web-api/middleware"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+	m "todo-web-api/tests/mockmanagers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupAuditRouters routes Login behind the request ID middleware and keeps
+// every audit event written.
+func setupAuditRouters(events *[]models.AuditEvent) *gin.Engine {
+	r := gin.Default()
+	storage.UserManager = lockoutUserManager()
+	storage.SessionManager = &m.MockSessionManager{}
+	storage.LoginAttemptManager = &m.MockLoginAttemptManager{}
+	storage.AuditEventManager = &m.MockAuditEventManager{CreateAuditEventFn: func(event *models.AuditEvent) (int, error) {
+		*events = append(*events, *event)
+		return len(*events), nil
+	}}
+	r.POST("/Login", middleware.RequestIDMiddleware(), app.Login)
+	return r
+}
+
+func TestLogin_RecordsAuditEvents(t *testing.T) {
+	events := []models.AuditEvent{}
+	router := setupAuditRouters(&events)
+
+	req := loginRequest("u1", "wrongpw1")
+	req.Header.Set("User-Agent", "audit-test/1.0")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, loginRequest("u1", "testpass1"))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	if !assert.Len(t, events, 2) {
+		t.FailNow()
+	}
+	failed := events[0]
+	assert.Equal(t, 1, failed.UserId)
+	assert.Equal(t, "login", failed.EventType)
+	assert.Equal(t, "failure", failed.Outcome)
+	assert.Equal(t, "invalid_password", failed.Detail)
+	assert.Equal(t, "203.0.113.7", failed.IPAddress)
+	assert.Equal(t, "audit-test/1.0", failed.UserAgent)
+	assert.Len(t, failed.RequestId, 36)
+
+	assert.Equal(t, "success", events[1].Outcome)
+	assert.NotEqual(t, failed.RequestId, events[1].RequestId)
+}
+
+func TestLogin_UnknownAccountIsAudited(t *testing.T) {
+	events := []models.AuditEvent{}
+	router := setupAuditRouters(&events)
+	storage.UserManager = &m.MockUserManager{FindExistingAccountFn: func(username, password string) (*models.User, error) {
+		return nil, errors.New(messages.AccountNotFound)
+	}}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, loginRequest("nobody", "testpass1"))
+
+	if assert.Len(t, events, 1) {
+		assert.Zero(t, events[0].UserId)
+		assert.Equal(t, "nobody", events[0].Username)
+		assert.Equal(t, "account_not_found", events[0].Detail)
+	}
+}
+
+func TestGetSecurityEvents(t *testing.T) {
+	r := gin.Default()
+	askedFor := 0
+	storage.AuditEventManager = &m.MockAuditEventManager{GetAuditEventsForUserFn: func(userId int, limit int) ([]models.AuditEvent, error) {
+		askedFor = userId
+		assert.Equal(t, 10, limit)
+		return []models.AuditEvent{
+			{Id: 2, UserId: userId, EventType: "logout", Outcome: "success", CreatedAt: time.Now()},
+			{Id: 1, UserId: userId, EventType: "login", Outcome: "success", CreatedAt: time.Now().Add(-time.Hour)},
+		}, nil
+	}}
+	r.GET("/SecurityEvents", withUser(7, "sid"), app.GetSecurityEvents)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/SecurityEvents?limit=10", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 7, askedFor)
+	var results []h.SecurityEventResult
+	json.Unmarshal(w.Body.Bytes(), &results)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, "logout", results[0].EventType)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/SecurityEvents?limit=abc", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,97 @@
+package controllertests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	app "todo-web-api/controllers"
+	h "todo-web-api/helpers"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+	m "todo-web-api/tests/mockmanagers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// withUser stands in for AuthMiddleware by setting the authenticated user.
+func withUser(userId int, sessionID string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("user_id", userId)
+		c.Set("session_id", sessionID)
+		c.Next()
+	}
+}
+
+func setupSessionRouters(sessionManager m.ISessionMockManager, userId int, sessionID string) *gin.Engine {
+	r := gin.Default()
+	storage.SessionManager = sessionManager
+	auth := r.Group("/", withUser(userId, sessionID))
+	{
+		auth.GET("/Sessions", app.GetSessions)
+		auth.DELETE("/Sessions/:id", app.RevokeSession)
+	}
+	return r
+}
+
+func TestGetSessions(t *testing.T) {
+	router := setupSessionRouters(&m.MockSessionManager{
+		GetSessionsForUserFn: func(userId int) ([]models.Session, error) {
+			return []models.Session{
+				{SessionId: "laptop", UserId: userId, ExpiresAt: time.Now().Add(time.Hour)},
+				{SessionId: "phone", UserId: userId, ExpiresAt: time.Now().Add(time.Hour)},
+				{SessionId: "expired", UserId: userId, ExpiresAt: time.Now().Add(-time.Hour)},
+			}, nil
+		}}, 1, "phone")
+	w := httptest.NewRecorder()
+
+	req, _ := http.NewRequest("GET", "/Sessions", nil)
+	router.ServeHTTP(w, req)
+
+	var sessions []h.SessionResult
+	json.Unmarshal(w.Body.Bytes(), &sessions)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Len(t, sessions, 2)
+	assert.False(t, sessions[0].Current)
+	assert.True(t, sessions[1].Current)
+}
+
+func TestRevokeSession(t *testing.T) {
+	deleted := ""
+	router := setupSessionRouters(&m.MockSessionManager{
+		GetSessionFn: func(sessionId string) (*models.Session, error) {
+			return &models.Session{SessionId: sessionId, UserId: 1}, nil
+		},
+		DeleteSessionFn: func(sessionId string) (bool, error) {
+			deleted = sessionId
+			return true, nil
+		}}, 1, "laptop")
+	w := httptest.NewRecorder()
+
+	req, _ := http.NewRequest("DELETE", fmt.Sprintf("/Sessions/%s", "phone"), nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "phone", deleted)
+}
+
+func TestRevokeSession_OtherUser(t *testing.T) {
+	router := setupSessionRouters(&m.MockSessionManager{
+		GetSessionFn: func(sessionId string) (*models.Session, error) {
+			return &models.Session{SessionId: sessionId, UserId: 2}, nil
+		},
+		DeleteSessionFn: func(sessionId string) (bool, error) {
+			t.Errorf("session of another user was deleted")
+			return true, nil
+		}}, 1, "laptop")
+	w := httptest.NewRecorder()
+
+	req, _ := http.NewRequest("DELETE", fmt.Sprintf("/Sessions/%s", "phone"), nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 404, w.Code)
+}
@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 	app "todo-web-api/controllers"
 	h "todo-web-api/helpers"
 	"todo-web-api/messages"
@@ -196,3 +197,125 @@ func UpdateTask(c TaskCase, s *h.SetStatus) (int, error) {
 
 	return w.Code, nil
 }
+
+func TestTaskSchedule(t *testing.T) {
+	paris, _ := time.LoadLocation("Europe/Paris")
+	start := time.Date(2024, 5, 1, 9, 0, 0, 0, paris)
+	due := time.Date(2024, 5, 1, 17, 0, 0, 0, paris)
+	// Task 5 already starts at 8:00 and is due at 18:00.
+	existingStart := start.Add(-time.Hour)
+	existingDue := due.Add(time.Hour)
+	var tests = []struct {
+		name    string
+		method  string
+		path    string
+		body    gin.H
+		want    int
+		startAt *time.Time
+		dueAt   *time.Time
+	}{
+		{"Create with dates", "POST", "/CreateTask/1", gin.H{"title": "Report", "start_at": start, "due_at": due}, 200, &start, &due},
+		{"Create without dates", "POST", "/CreateTask/1", gin.H{"title": "Report"}, 200, nil, nil},
+		{"Create starting after it's due", "POST", "/CreateTask/1", gin.H{"title": "Report", "start_at": due, "due_at": start}, 400, nil, nil},
+		{"Update without dates keeps them", "PUT", "/UpdateTask/5", gin.H{"title": "Report"}, 200, &existingStart, &existingDue},
+		{"Update sets the due date", "PUT", "/UpdateTask/5", gin.H{"title": "Report", "due_at": due}, 200, &existingStart, &due},
+		{"Update clears the start date with null", "PUT", "/UpdateTask/5", gin.H{"title": "Report", "start_at": nil}, 200, nil, &existingDue},
+		{"Update starting after the kept due date", "PUT", "/UpdateTask/5", gin.H{"title": "Report", "start_at": existingDue.Add(time.Hour)}, 400, nil, nil},
+		{"Update starting after it's due", "PUT", "/UpdateTask/5", gin.H{"title": "Report", "start_at": due, "due_at": start}, 400, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved *models.Task
+			save := func(task *models.Task) (int, error) {
+				saved = task
+				return task.Id, nil
+			}
+			router := setupTasksRouters(
+				&m.MockListManager{GetListFn: func(id int) (*models.List, error) {
+					return &models.List{Id: id, UserId: 1}, nil
+				}},
+				&m.MockTaskManager{
+					CreateTaskFn: func(task *models.Task, listId int) (int, error) { return save(task) },
+					UpdateTaskFn: save,
+					GetTaskFn: func(id int) (*models.Task, error) {
+						startAt, dueAt := existingStart.UTC(), existingDue.UTC()
+						return &models.Task{Id: id, ListId: 1, Title: "Report", StartAt: &startAt, DueAt: &dueAt}, nil
+					}})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, jsonRequest(tt.method, tt.path, tt.body))
+
+			assert.Equal(t, tt.want, w.Code)
+			if tt.want != 200 {
+				assert.Contains(t, w.Body.String(), messages.TaskStartAfterDue)
+				assert.Nil(t, saved)
+				return
+			}
+			if assert.NotNil(t, saved) {
+				// Dates are kept in UTC.
+				for _, pair := range [][2]*time.Time{{tt.startAt, saved.StartAt}, {tt.dueAt, saved.DueAt}} {
+					if pair[0] == nil {
+						assert.Nil(t, pair[1])
+					} else if assert.NotNil(t, pair[1]) {
+						assert.True(t, pair[0].Equal(*pair[1]))
+						assert.Equal(t, time.UTC, pair[1].Location())
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGetDueTasks(t *testing.T) {
+	var tests = []struct {
+		name     string
+		query    string
+		want     int
+		openOnly bool
+		check    func(t *testing.T, from time.Time, to time.Time)
+	}{
+		{"Overdue", "?due=overdue", 200, true, func(t *testing.T, from time.Time, to time.Time) {
+			assert.True(t, from.IsZero())
+			assert.WithinDuration(t, time.Now(), to, time.Minute)
+		}},
+		{"Today in a time zone", "?due=today&tz=Asia/Tokyo", 200, false, func(t *testing.T, from time.Time, to time.Time) {
+			tokyo, _ := time.LoadLocation("Asia/Tokyo")
+			now := time.Now().In(tokyo)
+			assert.True(t, from.Equal(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, tokyo)))
+			assert.Equal(t, 24*time.Hour, to.Sub(from))
+		}},
+		{"Range", "?from=2024-05-01T00:00:00%2B02:00&to=2024-05-08T00:00:00%2B02:00", 200, false, func(t *testing.T, from time.Time, to time.Time) {
+			assert.True(t, from.Equal(time.Date(2024, 4, 30, 22, 0, 0, 0, time.UTC)))
+			assert.True(t, to.Equal(time.Date(2024, 5, 7, 22, 0, 0, 0, time.UTC)))
+		}},
+		{"Range the wrong way round", "?from=2024-05-08T00:00:00Z&to=2024-05-01T00:00:00Z", 400, false, nil},
+		{"Range without an end", "?from=2024-05-01T00:00:00Z", 400, false, nil},
+		{"Unknown time zone", "?due=today&tz=Mars/Olympus", 400, false, nil},
+		{"Unknown filter", "?due=someday", 400, false, nil},
+		{"No filter", "", 400, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			storage.TaskManager = &m.MockTaskManager{
+				GetDueTasksForUserFn: func(userId int, from time.Time, to time.Time, openOnly bool) ([]models.Task, error) {
+					called = true
+					assert.Equal(t, 1, userId)
+					assert.Equal(t, tt.openOnly, openOnly)
+					tt.check(t, from, to)
+					return nil, nil
+				}}
+			r := gin.Default()
+			r.GET("/DueTasks", withUser(1, "sid"), app.GetDueTasks)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, jsonRequest("GET", "/DueTasks"+tt.query, nil))
+
+			assert.Equal(t, tt.want, w.Code)
+			assert.Equal(t, tt.want == 200, called)
+			if tt.want == 200 {
+				assert.Equal(t, "[]", w.Body.String())
+			}
+		})
+	}
+}
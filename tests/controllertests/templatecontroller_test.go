@@ -0,0 +1,249 @@
+package controllertests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	authz "todo-web-api/authorization"
+	app "todo-web-api/controllers"
+	h "todo-web-api/helpers"
+	"todo-web-api/messages"
+	"todo-web-api/middleware"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+	m "todo-web-api/tests/mockmanagers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// templateRecorder collects what the handlers create and delete.
+type templateRecorder struct {
+	lists     []*models.List
+	templates []*models.ListTemplate
+	deleted   []int
+}
+
+// setupTemplateRouters signs in userId. u1 owns list 10, which u4 views,
+// and template 30.
+func setupTemplateRouters(userId int, created *templateRecorder) *gin.Engine {
+	storage.ListManager = &m.MockListManager{
+		GetListFn: func(id int) (*models.List, error) {
+			if id == 10 {
+				return &models.List{Id: 10, UserId: 1, Title: "Groceries", Color: "#00ff00", Icon: "cart"}, nil
+			}
+			return nil, errors.New(messages.ListNotFoundInDb)
+		},
+		CreateListFn: func(list *models.List) (int, error) {
+			list.Id = 50
+			created.lists = append(created.lists, list)
+			return list.Id, nil
+		}}
+	dueAt := time.Date(2024, 5, 1, 17, 0, 0, 0, time.UTC)
+	storage.TaskManager = &m.MockTaskManager{
+		GetTasksForListFn: func(listId int) ([]models.Task, error) {
+			return []models.Task{
+				{Id: 1, ListId: 10, Title: "Milk", IsCompleted: true},
+				{Id: 2, ListId: 10, Title: "Eggs", Description: "A dozen", DueAt: &dueAt},
+			}, nil
+		}}
+	storage.ListTemplateManager = &m.MockListTemplateManager{
+		CreateListTemplateFn: func(template *models.ListTemplate) (int, error) {
+			template.Id = 60
+			created.templates = append(created.templates, template)
+			return template.Id, nil
+		},
+		GetListTemplateFn: func(id int) (*models.ListTemplate, error) {
+			if id == 30 {
+				return &models.ListTemplate{Id: 30, UserId: 1, Title: "Weekly shop",
+					Tasks: []models.TemplateTask{{Title: "Milk"}, {Title: "Bread"}}}, nil
+			}
+			return nil, errors.New(messages.ListTemplateNotFoundInDb)
+		},
+		GetListTemplatesForUserFn: func(userId int) ([]models.ListTemplate, error) {
+			if userId == 1 {
+				return []models.ListTemplate{{Id: 30, UserId: 1, Title: "Weekly shop"}}, nil
+			}
+			return nil, nil
+		},
+		DeleteListTemplateFn: func(id int) (bool, error) {
+			if id != 30 {
+				return false, errors.New(messages.ListTemplateNotFoundInDb)
+			}
+			created.deleted = append(created.deleted, id)
+			return true, nil
+		}}
+	accepted := time.Now()
+	storage.ListMemberManager = &m.MockListMemberManager{
+		GetListMemberFn: func(listId int, memberId int) (*models.ListMember, error) {
+			if memberId == 4 && listId == 10 {
+				return &models.ListMember{ListId: 10, UserId: 4, Role: "viewer", AcceptedAt: &accepted}, nil
+			}
+			return nil, errors.New(messages.ListMemberNotFoundInDb)
+		}}
+
+	r := gin.Default()
+	auth := r.Group("/", withUser(userId, "sid"))
+	{
+		auth.POST("/CloneList/:id", middleware.RequireListRole(authz.ListById, "id", authz.ListRoleViewer), app.CloneList)
+		auth.POST("/SaveListAsTemplate/:id", middleware.RequireListRole(authz.ListById, "id", authz.ListRoleViewer), app.SaveListAsTemplate)
+		auth.GET("/ListTemplates", app.GetListTemplates)
+		auth.DELETE("/ListTemplates/:id", middleware.RequireOwner(authz.TemplateOwner, "id"), app.DeleteListTemplate)
+		auth.POST("/CreateListFromTemplate/:id", middleware.RequireOwner(authz.TemplateOwner, "id"), app.CreateListFromTemplate)
+	}
+	return r
+}
+
+func TestSaveListAsTemplate(t *testing.T) {
+	created := &templateRecorder{}
+	w := httptest.NewRecorder()
+	setupTemplateRouters(4, created).ServeHTTP(w, jsonRequest("POST", "/SaveListAsTemplate/10", h.CopyList{}))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), messages.SuccessTemplateSaved)
+	if assert.Len(t, created.templates, 1) {
+		template := created.templates[0]
+		assert.Equal(t, 4, template.UserId)
+		assert.Equal(t, "Groceries", template.Title)
+		assert.Equal(t, "cart", template.Icon)
+		assert.Equal(t, []models.TemplateTask{{Title: "Milk"}, {Title: "Eggs", Description: "A dozen"}}, template.Tasks)
+	}
+}
+
+func TestSaveListAsTemplate_NotAMember(t *testing.T) {
+	created := &templateRecorder{}
+	w := httptest.NewRecorder()
+	setupTemplateRouters(3, created).ServeHTTP(w, jsonRequest("POST", "/SaveListAsTemplate/10", h.CopyList{Title: "Mine now"}))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, created.templates)
+}
+
+func TestGetListTemplates(t *testing.T) {
+	var tests = []struct {
+		name   string
+		userId int
+		want   int
+	}{
+		{"Owner", 1, 1},
+		{"No templates", 3, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			setupTemplateRouters(tt.userId, &templateRecorder{}).ServeHTTP(w, jsonRequest("GET", "/ListTemplates", nil))
+
+			var templates []models.ListTemplate
+			json.Unmarshal(w.Body.Bytes(), &templates)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.NotNil(t, templates)
+			assert.Len(t, templates, tt.want)
+		})
+	}
+}
+
+func TestDeleteListTemplate(t *testing.T) {
+	var tests = []struct {
+		name       string
+		userId     int
+		templateId string
+		want       int
+	}{
+		{"Owner", 1, "30", 200},
+		{"Not the owner", 4, "30", 403},
+		{"Unknown template", 1, "99", 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := &templateRecorder{}
+			w := httptest.NewRecorder()
+			setupTemplateRouters(tt.userId, created).ServeHTTP(w, jsonRequest("DELETE", "/ListTemplates/"+tt.templateId, nil))
+
+			assert.Equal(t, tt.want, w.Code)
+			if tt.want == 200 {
+				assert.Equal(t, []int{30}, created.deleted)
+			} else {
+				assert.Empty(t, created.deleted)
+			}
+		})
+	}
+}
+
+func TestCreateListFromTemplate(t *testing.T) {
+	var tests = []struct {
+		name   string
+		userId int
+		body   h.CopyList
+		want   int
+		title  string
+	}{
+		{"Template's title", 1, h.CopyList{}, 200, "Weekly shop"},
+		{"New title", 1, h.CopyList{Title: "  Party  "}, 200, "Party"},
+		{"Not the owner", 4, h.CopyList{}, 403, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := &templateRecorder{}
+			w := httptest.NewRecorder()
+			setupTemplateRouters(tt.userId, created).ServeHTTP(w, jsonRequest("POST", "/CreateListFromTemplate/30", tt.body))
+
+			assert.Equal(t, tt.want, w.Code)
+			if tt.want != 200 {
+				assert.Empty(t, created.lists)
+				return
+			}
+			assert.Contains(t, w.Body.String(), messages.SuccessListFromTemplate)
+			if assert.Len(t, created.lists, 1) {
+				list := created.lists[0]
+				assert.Equal(t, 1, list.UserId)
+				assert.Equal(t, tt.title, list.Title)
+				assert.Equal(t, []models.Task{{Title: "Milk"}, {Title: "Bread"}}, list.Tasks)
+			}
+		})
+	}
+}
+
+func TestCloneList(t *testing.T) {
+	var tests = []struct {
+		name      string
+		userId    int
+		body      h.CloneList
+		want      int
+		completed []bool
+	}{
+		{"Owner keeps completion", 1, h.CloneList{}, 200, []bool{true, false}},
+		{"Viewer resets completion", 4, h.CloneList{ResetCompleted: true}, 200, []bool{false, false}},
+		{"Not a member", 3, h.CloneList{}, 403, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := &templateRecorder{}
+			w := httptest.NewRecorder()
+			setupTemplateRouters(tt.userId, created).ServeHTTP(w, jsonRequest("POST", "/CloneList/10", tt.body))
+
+			assert.Equal(t, tt.want, w.Code)
+			if tt.want != 200 {
+				assert.Empty(t, created.lists)
+				return
+			}
+			assert.Contains(t, w.Body.String(), messages.SuccessListCloned)
+			if assert.Len(t, created.lists, 1) {
+				list := created.lists[0]
+				// The copy belongs to whoever made it.
+				assert.Equal(t, tt.userId, list.UserId)
+				assert.Equal(t, "Groceries", list.Title)
+				assert.Equal(t, "#00ff00", list.Color)
+				var completed []bool
+				for _, task := range list.Tasks {
+					assert.Zero(t, task.Id)
+					completed = append(completed, task.IsCompleted)
+				}
+				assert.Equal(t, tt.completed, completed)
+				assert.NotNil(t, list.Tasks[1].DueAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package controllertests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	authz "todo-web-api/authorization"
+	app "todo-web-api/controllers"
+	h "todo-web-api/helpers"
+	"todo-web-api/messages"
+	"todo-web-api/middleware"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+	m "todo-web-api/tests/mockmanagers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupTrashRouters signs in userId. u1 owns list 10 and list 20, which is
+// in the trash; u3 edits list 10 and u4 views it. Task 5 was deleted from
+// list 10 on its own, task 6 went in the trash with list 20. restored
+// collects what was restored, as "list" or "task" ids.
+func setupTrashRouters(userId int, restored map[string][]int) *gin.Engine {
+	deletedAt := time.Now().Add(-time.Hour)
+	lists := map[int]*models.List{
+		10: {Id: 10, UserId: 1, Title: "Groceries"},
+		20: {Id: 20, UserId: 1, Title: "Work"},
+	}
+	lists[20].DeletedAt.Time, lists[20].DeletedAt.Valid = deletedAt, true
+	tasks := map[int]*models.Task{
+		5: {Id: 5, ListId: 10, Title: "Milk"},
+		6: {Id: 6, ListId: 20, Title: "Report"},
+	}
+	for _, task := range tasks {
+		task.DeletedAt.Time, task.DeletedAt.Valid = deletedAt, true
+	}
+
+	storage.ListManager = &m.MockListManager{
+		GetListFn: func(id int) (*models.List, error) {
+			if list, ok := lists[id]; ok && !list.DeletedAt.Valid {
+				return list, nil
+			}
+			return nil, errors.New(messages.ListNotFoundInDb)
+		},
+		GetTrashedListFn: func(id int) (*models.List, error) {
+			if list, ok := lists[id]; ok && list.DeletedAt.Valid {
+				return list, nil
+			}
+			return nil, errors.New(messages.ListNotFoundInDb)
+		},
+		GetTrashedListsForUserFn: func(userId int) ([]models.List, error) {
+			return []models.List{*lists[20]}, nil
+		},
+		RestoreListFn: func(id int) (bool, error) {
+			if list, ok := lists[id]; !ok || !list.DeletedAt.Valid {
+				return false, errors.New(messages.ListNotFoundInDb)
+			}
+			restored["list"] = append(restored["list"], id)
+			return true, nil
+		}}
+	storage.TaskManager = &m.MockTaskManager{
+		GetTrashedTaskFn: func(id int) (*models.Task, error) {
+			if task, ok := tasks[id]; ok {
+				return task, nil
+			}
+			return nil, errors.New(messages.TaskNotFoundInDb)
+		},
+		GetTrashedTasksForUserFn: func(userId int) ([]models.Task, error) {
+			return []models.Task{*tasks[5]}, nil
+		},
+		RestoreTaskFn: func(id int) (bool, error) {
+			restored["task"] = append(restored["task"], id)
+			return true, nil
+		}}
+	accepted := time.Now()
+	storage.ListMemberManager = &m.MockListMemberManager{
+		GetListMemberFn: func(listId int, memberId int) (*models.ListMember, error) {
+			roles := map[int]string{3: "editor", 4: "viewer"}
+			if role, ok := roles[memberId]; ok && listId == 10 {
+				return &models.ListMember{ListId: 10, UserId: memberId, Role: role, AcceptedAt: &accepted}, nil
+			}
+			return nil, errors.New(messages.ListMemberNotFoundInDb)
+		}}
+
+	r := gin.Default()
+	auth := r.Group("/", withUser(userId, "sid"))
+	{
+		auth.GET("/Trash", app.GetTrash)
+		auth.POST("/RestoreList/:id", middleware.RequireOwner(authz.TrashedListOwner, "id"), app.RestoreList)
+		auth.POST("/RestoreTask/:id", middleware.RequireListRole(authz.ListOfTrashedTask, "id", authz.ListRoleEditor), app.RestoreTask)
+	}
+	return r
+}
+
+func TestGetTrash(t *testing.T) {
+	router := setupTrashRouters(1, map[string][]int{})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("GET", "/Trash", nil))
+
+	var result h.TrashResult
+	json.Unmarshal(w.Body.Bytes(), &result)
+	assert.Equal(t, http.StatusOK, w.Code)
+	if assert.Len(t, result.Lists, 1) {
+		assert.Equal(t, "Work", result.Lists[0].Title)
+		assert.False(t, result.Lists[0].DeletedAt.IsZero())
+	}
+	if assert.Len(t, result.Tasks, 1) {
+		assert.Equal(t, h.TrashedTaskResult{Id: 5, Title: "Milk", ListId: 10, DeletedAt: result.Tasks[0].DeletedAt}, result.Tasks[0])
+	}
+}
+
+func TestRestoreList(t *testing.T) {
+	var tests = []struct {
+		name   string
+		userId int
+		listId string
+		want   int
+	}{
+		{"Owner", 1, "20", 200},
+		{"Not the owner", 3, "20", 403},
+		{"Not in the trash", 1, "10", 404},
+		{"Unknown list", 1, "99", 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restored := map[string][]int{}
+			w := httptest.NewRecorder()
+			setupTrashRouters(tt.userId, restored).ServeHTTP(w, jsonRequest("POST", "/RestoreList/"+tt.listId, nil))
+
+			assert.Equal(t, tt.want, w.Code)
+			if tt.want == 200 {
+				assert.Equal(t, []int{20}, restored["list"])
+			} else {
+				assert.Empty(t, restored["list"])
+			}
+		})
+	}
+}
+
+func TestRestoreTask(t *testing.T) {
+	var tests = []struct {
+		name    string
+		userId  int
+		taskId  string
+		want    int
+		message string
+	}{
+		{"Owner", 1, "5", 200, messages.SuccessTaskRestored},
+		{"Editor", 3, "5", 200, messages.SuccessTaskRestored},
+		{"Viewer", 4, "5", 403, messages.Forbidden},
+		// Restoring its list brings the task back.
+		{"List in the trash", 1, "6", 404, messages.ListNotFoundInDb},
+		{"Not in the trash", 1, "7", 404, messages.TaskNotFoundInDb},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restored := map[string][]int{}
+			w := httptest.NewRecorder()
+			setupTrashRouters(tt.userId, restored).ServeHTTP(w, jsonRequest("POST", "/RestoreTask/"+tt.taskId, nil))
+
+			assert.Equal(t, tt.want, w.Code)
+			assert.Contains(t, w.Body.String(), tt.message)
+			if tt.want == 200 {
+				assert.Equal(t, []int{5}, restored["task"])
+			} else {
+				assert.Empty(t, restored["task"])
+			}
+		})
+	}
+}
@@ -2,14 +2,20 @@ package controllertests
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
+	auth "todo-web-api/authentication"
 	app "todo-web-api/controllers"
 	h "todo-web-api/helpers"
+	"todo-web-api/messages"
 	"todo-web-api/models"
 	"todo-web-api/storage"
 	m "todo-web-api/tests/mockmanagers"
@@ -28,11 +34,15 @@ type loginCase struct {
 func setupRouters(userManager m.IUserMockManager) *gin.Engine {
 	r := gin.Default()
 	storage.UserManager = userManager
+	storage.SessionManager = &m.MockSessionManager{}
+	storage.LoginAttemptManager = &m.MockLoginAttemptManager{}
+	storage.AuditEventManager = &m.MockAuditEventManager{}
 	v1 := r.Group("/api/v1")
 	{
 		v1.POST("/Register", app.Register)
 		r.POST("/Login", app.Login)
 		r.GET("/GetUser/:id", app.GetUserById)
+		r.POST("/RefreshToken", app.RefreshToken)
 	}
 	return r
 }
@@ -110,7 +120,7 @@ func Test_Register_Url(t *testing.T) {
 
 	testUser := h.User{
 		Username: "TEST_U1",
-		Password: "PW1",
+		Password: "Test_PW1x",
 	}
 
 	jsonValue, _ := json.Marshal(testUser)
@@ -157,3 +167,183 @@ func hashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	return string(bytes), err
 }
+
+func TestLogin_SessionSaveFails(t *testing.T) {
+	existingUser := &models.User{Username: "u1"}
+	existingUser.Password, _ = hashPassword("testpass1")
+
+	router := setupRouters(&m.MockUserManager{FindExistingAccountFn: func(username, password string) (*models.User, error) {
+		return existingUser, nil
+	}})
+	storage.SessionManager = &m.MockSessionManager{SaveSessionFn: func(session *models.Session) (int, error) {
+		return 0, errors.New("database unavailable")
+	}}
+
+	jsonValue, _ := json.Marshal(h.User{Username: "u1", Password: "testpass1"})
+	req, _ := http.NewRequest("POST", "/Login", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Result().Cookies())
+}
+
+func refreshRequest(refreshToken string) *http.Request {
+	req, _ := http.NewRequest("POST", "/RefreshToken", nil)
+	req.AddCookie(&http.Cookie{Name: "refresh_token", Value: refreshToken})
+	return req
+}
+
+func tokenDigest(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func refreshUserManager(disabled bool) *m.MockUserManager {
+	return &m.MockUserManager{GetUserFn: func(id int) (*models.User, error) {
+		return &models.User{Id: id, Username: "u1", Role: "user", IsDisabled: disabled}, nil
+	}}
+}
+
+func TestRefreshToken_RotatesRefreshToken(t *testing.T) {
+	refreshToken, _ := auth.GenerateRefreshToken(1, "u1", "sid-1")
+	router := setupRouters(refreshUserManager(false))
+
+	rotatedFrom := ""
+	storage.SessionManager = &m.MockSessionManager{
+		GetSessionFn: func(sessionId string) (*models.Session, error) {
+			return &models.Session{SessionId: sessionId, UserId: 1, RefreshToken: tokenDigest(refreshToken),
+				ExpiresAt: time.Now().Add(time.Hour)}, nil
+		},
+		RotateSessionTokensFn: func(sessionId, currentRefreshToken, accessToken, refreshToken string, expiresAt time.Time) (bool, error) {
+			rotatedFrom = currentRefreshToken
+			return true, nil
+		}}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, refreshRequest(refreshToken))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, tokenDigest(refreshToken), rotatedFrom)
+
+	var newRefreshToken string
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == "refresh_token" {
+			newRefreshToken = cookie.Value
+		}
+	}
+	assert.NotEmpty(t, newRefreshToken)
+	assert.NotEqual(t, refreshToken, newRefreshToken)
+}
+
+func TestRefreshToken_ReuseRevokesAllSessions(t *testing.T) {
+	refreshToken, _ := auth.GenerateRefreshToken(1, "u1", "sid-1")
+	router := setupRouters(refreshUserManager(false))
+
+	// u1 is also signed in on another device.
+	sessions := map[string]int{"sid-1": 1, "sid-2": 1, "sid-3": 2}
+	storage.SessionManager = &m.MockSessionManager{
+		GetSessionFn: func(sessionId string) (*models.Session, error) {
+			// The session has already moved on to a newer refresh token.
+			return &models.Session{SessionId: sessionId, UserId: 1, RefreshToken: tokenDigest("newer-token"),
+				ExpiresAt: time.Now().Add(time.Hour)}, nil
+		},
+		DeleteSessionsForUserFn: func(userId int) (int64, error) {
+			var count int64
+			for sessionId, owner := range sessions {
+				if owner == userId {
+					delete(sessions, sessionId)
+					count++
+				}
+			}
+			return count, nil
+		}}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, refreshRequest(refreshToken))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, map[string]int{"sid-3": 2}, sessions)
+}
+
+func TestRefreshToken_OtherSessionTokensAreNotReuse(t *testing.T) {
+	refreshToken, _ := auth.GenerateRefreshToken(1, "u1", "sid-1")
+	accessToken, _ := auth.GenerateAccessToken("u1", 1, "sid-1", []string{"user"})
+	csrfToken, _ := auth.GenerateCSRFToken("sid-1")
+
+	for name, token := range map[string]string{"access token": accessToken, "csrf token": csrfToken} {
+		t.Run(name, func(t *testing.T) {
+			router := setupRouters(refreshUserManager(false))
+			revoked := ""
+			storage.SessionManager = &m.MockSessionManager{
+				GetSessionFn: func(sessionId string) (*models.Session, error) {
+					return &models.Session{SessionId: sessionId, UserId: 1, RefreshToken: tokenDigest(refreshToken),
+						AccessToken: tokenDigest(accessToken), ExpiresAt: time.Now().Add(time.Hour)}, nil
+				},
+				DeleteSessionFn: func(sessionId string) (bool, error) {
+					revoked = sessionId
+					return true, nil
+				},
+				DeleteSessionsForUserFn: func(userId int) (int64, error) {
+					revoked = "all"
+					return 1, nil
+				}}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, refreshRequest(token))
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Contains(t, w.Body.String(), messages.InvalidRefreshToken)
+			assert.Empty(t, revoked)
+		})
+	}
+}
+
+func TestRefreshToken_LostRotationRaceRevokesAllSessions(t *testing.T) {
+	refreshToken, _ := auth.GenerateRefreshToken(1, "u1", "sid-1")
+	router := setupRouters(refreshUserManager(false))
+
+	revoked := 0
+	storage.SessionManager = &m.MockSessionManager{
+		GetSessionFn: func(sessionId string) (*models.Session, error) {
+			return &models.Session{SessionId: sessionId, UserId: 1, RefreshToken: tokenDigest(refreshToken),
+				ExpiresAt: time.Now().Add(time.Hour)}, nil
+		},
+		RotateSessionTokensFn: func(sessionId, currentRefreshToken, accessToken, refreshToken string, expiresAt time.Time) (bool, error) {
+			return false, nil
+		},
+		DeleteSessionsForUserFn: func(userId int) (int64, error) {
+			revoked = userId
+			return 2, nil
+		}}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, refreshRequest(refreshToken))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, 1, revoked)
+}
+
+func TestRefreshToken_DisabledUserRevokesSession(t *testing.T) {
+	refreshToken, _ := auth.GenerateRefreshToken(1, "u1", "sid-1")
+	router := setupRouters(refreshUserManager(true))
+
+	revoked := ""
+	storage.SessionManager = &m.MockSessionManager{
+		GetSessionFn: func(sessionId string) (*models.Session, error) {
+			return &models.Session{SessionId: sessionId, UserId: 1, RefreshToken: tokenDigest(refreshToken),
+				ExpiresAt: time.Now().Add(time.Hour)}, nil
+		},
+		DeleteSessionFn: func(sessionId string) (bool, error) {
+			revoked = sessionId
+			return true, nil
+		}}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, refreshRequest(refreshToken))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "sid-1", revoked)
+}
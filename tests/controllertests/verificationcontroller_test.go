@@ -0,0 +1,182 @@
+package controllertests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+	auth "todo-web-api/authentication"
+	authz "todo-web-api/authorization"
+	app "todo-web-api/controllers"
+	h "todo-web-api/helpers"
+	"todo-web-api/mailer"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+	m "todo-web-api/tests/mockmanagers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupVerificationRouters(user *models.User) *gin.Engine {
+	r := gin.Default()
+	storage.UserManager = &m.MockUserManager{
+		CreateUserFn: func(u *models.User) (int, error) {
+			*user = *u
+			user.Id = 1
+			return 1, nil
+		},
+		GetUserFn: func(id int) (*models.User, error) {
+			return user, nil
+		},
+		GetUserByVerifiedEmailFn: func(email string) (*models.User, error) {
+			if user.VerifiedEmail != nil && email == *user.VerifiedEmail {
+				return user, nil
+			}
+			return nil, errors.New(messages.UserNotFound)
+		},
+		UpdateUserFn: func(u *models.User) (int, error) {
+			return u.Id, nil
+		}}
+	storage.OneTimeTokenManager = memoryResetTokens()
+	storage.AuditEventManager = &m.MockAuditEventManager{}
+
+	r.POST("/Register", app.Register)
+	r.POST("/VerifyEmail", app.VerifyEmail)
+	authed := r.Group("/", withUser(1, "sid"))
+	{
+		authed.POST("/ResendVerification", app.ResendVerification)
+	}
+	return r
+}
+
+func TestRegister_WithEmailSendsVerification(t *testing.T) {
+	dir := t.TempDir()
+	mailer.SetSender(mailer.FileSender{Dir: dir})
+	defer mailer.SetSender(mailer.LogSender{})
+	auth.ConfigureEmailVerification("https://todo.example/verify", 0, 0)
+
+	user := &models.User{}
+	router := setupVerificationRouters(user)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("POST", "/Register", h.Register{Username: "u1", Password: "testpass1", Email: "U1@Example.com"}))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "u1@example.com", user.Email)
+	assert.False(t, user.EmailVerified)
+	assert.Equal(t, []string{authz.RoleUnverified}, auth.Roles(user))
+	assert.False(t, authz.HasPermission(authz.PermissionsFor(auth.Roles(user)...), authz.PermListsWrite))
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if !assert.Len(t, files, 1) {
+		t.FailNow()
+	}
+	mail, _ := os.ReadFile(files[0])
+	assert.Contains(t, string(mail), "To: u1@example.com")
+	token := string(regexp.MustCompile(`\?token=([A-Za-z0-9_-]+)`).FindSubmatch(mail)[1])
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("POST", "/VerifyEmail", h.VerifyEmail{Token: token}))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, user.EmailVerified)
+	if assert.NotNil(t, user.VerifiedEmail) {
+		assert.Equal(t, "u1@example.com", *user.VerifiedEmail)
+	}
+	assert.Equal(t, []string{authz.RoleUser}, auth.Roles(user))
+
+	// Links work once.
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("POST", "/VerifyEmail", h.VerifyEmail{Token: token}))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRegister_EmailInUse(t *testing.T) {
+	email := "u1@example.com"
+	user := &models.User{Id: 2, Username: "u2", Email: email, EmailVerified: true, VerifiedEmail: &email}
+	router := setupVerificationRouters(user)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("POST", "/Register", h.Register{Username: "u1", Password: "testpass1", Email: "u1@example.com"}))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), messages.EmailInUse)
+}
+
+func TestRegister_UnverifiedEmailIsNotClaimed(t *testing.T) {
+	user := &models.User{Id: 2, Username: "squatter", Email: "u1@example.com"}
+	router := setupVerificationRouters(user)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, jsonRequest("POST", "/Register", h.Register{Username: "u1", Password: "testpass1", Email: "u1@example.com"}))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "u1", user.Username)
+	assert.Nil(t, user.VerifiedEmail)
+}
+
+func TestVerifyEmail_AddressVerifiedElsewhere(t *testing.T) {
+	token := "verify-me"
+	storage.OneTimeTokenManager = &m.MockOneTimeTokenManager{
+		ConsumeOneTimeTokenFn: func(purpose string, tokenHash string, now time.Time) (*models.OneTimeToken, error) {
+			return &models.OneTimeToken{UserId: 1, Purpose: purpose}, nil
+		}}
+	email := "u1@example.com"
+	user := &models.User{Id: 1, Username: "u1", Email: email}
+	updated := false
+	storage.UserManager = &m.MockUserManager{
+		GetUserFn: func(id int) (*models.User, error) {
+			return user, nil
+		},
+		GetUserByVerifiedEmailFn: func(address string) (*models.User, error) {
+			return &models.User{Id: 2, Username: "u2", Email: email, EmailVerified: true, VerifiedEmail: &email}, nil
+		},
+		UpdateUserFn: func(u *models.User) (int, error) {
+			updated = true
+			return u.Id, nil
+		}}
+	r := gin.Default()
+	r.POST("/VerifyEmail", app.VerifyEmail)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, jsonRequest("POST", "/VerifyEmail", h.VerifyEmail{Token: token}))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), messages.EmailInUse)
+	assert.False(t, updated)
+	assert.False(t, user.EmailVerified)
+}
+
+func TestResendVerification_RateLimited(t *testing.T) {
+	user := &models.User{Id: 1, Username: "u1", Role: authz.RoleUser, Email: "u1@example.com"}
+	router := setupVerificationRouters(user)
+	auth.ConfigureEmailVerification("", 0, time.Minute)
+	storage.OneTimeTokenManager.(*m.MockOneTimeTokenManager).GetLatestOneTimeTokenFn = func(userId int, purpose string) (*models.OneTimeToken, error) {
+		return &models.OneTimeToken{UserId: userId, Purpose: purpose, CreatedAt: time.Now().Add(-20 * time.Second)}, nil
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/ResendVerification", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "40", w.Header().Get("Retry-After"))
+}
+
+func TestResendVerification_AlreadyVerified(t *testing.T) {
+	user := &models.User{Id: 1, Username: "u1", Role: authz.RoleUser, Email: "u1@example.com", EmailVerified: true}
+	router := setupVerificationRouters(user)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/ResendVerification", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), messages.EmailAlreadyVerified)
+}
@@ -0,0 +1,68 @@
+package mockmanagers
+
+import (
+	"errors"
+	"time"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+)
+
+type IAccessTokenMockManager interface {
+	CreateAccessToken(token *models.PersonalAccessToken) (ID int, err error)
+	GetAccessTokenByHash(tokenHash string) (*models.PersonalAccessToken, error)
+	GetAccessTokensForUser(userId int) ([]models.PersonalAccessToken, error)
+	TouchAccessToken(id int, usedAt time.Time) error
+	DeleteAccessToken(userId int, id int) (success bool, err error)
+	DeleteExpiredAccessTokens(now time.Time) (count int64, err error)
+}
+
+type MockAccessTokenManager struct {
+	CreateAccessTokenFn         func(token *models.PersonalAccessToken) (ID int, err error)
+	GetAccessTokenByHashFn      func(tokenHash string) (*models.PersonalAccessToken, error)
+	GetAccessTokensForUserFn    func(userId int) ([]models.PersonalAccessToken, error)
+	TouchAccessTokenFn          func(id int, usedAt time.Time) error
+	DeleteAccessTokenFn         func(userId int, id int) (success bool, err error)
+	DeleteExpiredAccessTokensFn func(now time.Time) (count int64, err error)
+}
+
+func (m *MockAccessTokenManager) CreateAccessToken(token *models.PersonalAccessToken) (int, error) {
+	if m.CreateAccessTokenFn != nil {
+		return m.CreateAccessTokenFn(token)
+	}
+	return 0, nil
+}
+
+func (m *MockAccessTokenManager) GetAccessTokenByHash(tokenHash string) (*models.PersonalAccessToken, error) {
+	if m.GetAccessTokenByHashFn != nil {
+		return m.GetAccessTokenByHashFn(tokenHash)
+	}
+	return nil, errors.New(messages.AccessTokenNotFoundInDb)
+}
+
+func (m *MockAccessTokenManager) GetAccessTokensForUser(userId int) ([]models.PersonalAccessToken, error) {
+	if m.GetAccessTokensForUserFn != nil {
+		return m.GetAccessTokensForUserFn(userId)
+	}
+	return nil, nil
+}
+
+func (m *MockAccessTokenManager) TouchAccessToken(id int, usedAt time.Time) error {
+	if m.TouchAccessTokenFn != nil {
+		return m.TouchAccessTokenFn(id, usedAt)
+	}
+	return nil
+}
+
+func (m *MockAccessTokenManager) DeleteAccessToken(userId int, id int) (bool, error) {
+	if m.DeleteAccessTokenFn != nil {
+		return m.DeleteAccessTokenFn(userId, id)
+	}
+	return false, nil
+}
+
+func (m *MockAccessTokenManager) DeleteExpiredAccessTokens(now time.Time) (int64, error) {
+	if m.DeleteExpiredAccessTokensFn != nil {
+		return m.DeleteExpiredAccessTokensFn(now)
+	}
+	return 0, nil
+}
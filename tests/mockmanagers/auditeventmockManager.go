@@ -0,0 +1,27 @@
+package mockmanagers
+
+import "todo-web-api/models"
+
+type IAuditEventMockManager interface {
+	CreateAuditEvent(event *models.AuditEvent) (ID int, err error)
+	GetAuditEventsForUser(userId int, limit int) ([]models.AuditEvent, error)
+}
+
+type MockAuditEventManager struct {
+	CreateAuditEventFn      func(event *models.AuditEvent) (ID int, err error)
+	GetAuditEventsForUserFn func(userId int, limit int) ([]models.AuditEvent, error)
+}
+
+func (m *MockAuditEventManager) CreateAuditEvent(event *models.AuditEvent) (int, error) {
+	if m.CreateAuditEventFn != nil {
+		return m.CreateAuditEventFn(event)
+	}
+	return 0, nil
+}
+
+func (m *MockAuditEventManager) GetAuditEventsForUser(userId int, limit int) ([]models.AuditEvent, error) {
+	if m.GetAuditEventsForUserFn != nil {
+		return m.GetAuditEventsForUserFn(userId, limit)
+	}
+	return nil, nil
+}
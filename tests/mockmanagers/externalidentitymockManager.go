@@ -0,0 +1,41 @@
+package mockmanagers
+
+import (
+	"errors"
+	"time"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+)
+
+type IExternalIdentityMockManager interface {
+	CreateExternalIdentity(identity *models.ExternalIdentity) (ID int, err error)
+	GetExternalIdentity(provider string, subject string) (*models.ExternalIdentity, error)
+	TouchExternalIdentity(id int, lastLoginAt time.Time) error
+}
+
+type MockExternalIdentityManager struct {
+	CreateExternalIdentityFn func(identity *models.ExternalIdentity) (ID int, err error)
+	GetExternalIdentityFn    func(provider string, subject string) (*models.ExternalIdentity, error)
+	TouchExternalIdentityFn  func(id int, lastLoginAt time.Time) error
+}
+
+func (m *MockExternalIdentityManager) CreateExternalIdentity(identity *models.ExternalIdentity) (int, error) {
+	if m.CreateExternalIdentityFn != nil {
+		return m.CreateExternalIdentityFn(identity)
+	}
+	return 0, nil
+}
+
+func (m *MockExternalIdentityManager) GetExternalIdentity(provider string, subject string) (*models.ExternalIdentity, error) {
+	if m.GetExternalIdentityFn != nil {
+		return m.GetExternalIdentityFn(provider, subject)
+	}
+	return nil, errors.New(messages.ExternalIdentityNotFoundInDb)
+}
+
+func (m *MockExternalIdentityManager) TouchExternalIdentity(id int, lastLoginAt time.Time) error {
+	if m.TouchExternalIdentityFn != nil {
+		return m.TouchExternalIdentityFn(id, lastLoginAt)
+	}
+	return nil
+}
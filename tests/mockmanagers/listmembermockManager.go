@@ -0,0 +1,77 @@
+package mockmanagers
+
+import (
+	"errors"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+)
+
+type IListMemberMockManager interface {
+	CreateListMember(member *models.ListMember) (ID int, err error)
+	GetListMember(listId int, userId int) (*models.ListMember, error)
+	GetListMemberById(id int) (*models.ListMember, error)
+	GetListMembers(listId int) ([]models.ListMember, error)
+	GetListMembershipsForUser(userId int, accepted bool) ([]models.ListMember, error)
+	UpdateListMember(member *models.ListMember) (ID int, err error)
+	DeleteListMember(id int) (success bool, err error)
+}
+
+type MockListMemberManager struct {
+	CreateListMemberFn          func(member *models.ListMember) (ID int, err error)
+	GetListMemberFn             func(listId int, userId int) (*models.ListMember, error)
+	GetListMemberByIdFn         func(id int) (*models.ListMember, error)
+	GetListMembersFn            func(listId int) ([]models.ListMember, error)
+	GetListMembershipsForUserFn func(userId int, accepted bool) ([]models.ListMember, error)
+	UpdateListMemberFn          func(member *models.ListMember) (ID int, err error)
+	DeleteListMemberFn          func(id int) (success bool, err error)
+}
+
+func (m *MockListMemberManager) CreateListMember(member *models.ListMember) (int, error) {
+	if m.CreateListMemberFn != nil {
+		return m.CreateListMemberFn(member)
+	}
+	return 0, nil
+}
+
+// Without a GetListMemberFn nobody is a member of anything.
+func (m *MockListMemberManager) GetListMember(listId int, userId int) (*models.ListMember, error) {
+	if m.GetListMemberFn != nil {
+		return m.GetListMemberFn(listId, userId)
+	}
+	return nil, errors.New(messages.ListMemberNotFoundInDb)
+}
+
+func (m *MockListMemberManager) GetListMemberById(id int) (*models.ListMember, error) {
+	if m.GetListMemberByIdFn != nil {
+		return m.GetListMemberByIdFn(id)
+	}
+	return nil, errors.New(messages.ListMemberNotFoundInDb)
+}
+
+func (m *MockListMemberManager) GetListMembers(listId int) ([]models.ListMember, error) {
+	if m.GetListMembersFn != nil {
+		return m.GetListMembersFn(listId)
+	}
+	return nil, nil
+}
+
+func (m *MockListMemberManager) GetListMembershipsForUser(userId int, accepted bool) ([]models.ListMember, error) {
+	if m.GetListMembershipsForUserFn != nil {
+		return m.GetListMembershipsForUserFn(userId, accepted)
+	}
+	return nil, nil
+}
+
+func (m *MockListMemberManager) UpdateListMember(member *models.ListMember) (int, error) {
+	if m.UpdateListMemberFn != nil {
+		return m.UpdateListMemberFn(member)
+	}
+	return member.Id, nil
+}
+
+func (m *MockListMemberManager) DeleteListMember(id int) (bool, error) {
+	if m.DeleteListMemberFn != nil {
+		return m.DeleteListMemberFn(id)
+	}
+	return true, nil
+}
@@ -1,19 +1,37 @@
 package mockmanagers
 
-import "todo-web-api/models"
+import (
+	"errors"
+	"time"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+)
 
 type IListMockManager interface {
 	CreateList(list *models.List) (ID int, err error)
+	UpdateList(list *models.List) (ID int, err error)
 	DeleteList(id int) (success bool, err error)
 	GetListForUser(id int) (*models.List, error)
+	GetListsForUser(userId int, archived bool) ([]models.List, error)
 	GetList(id int) (*models.List, error)
+	GetTrashedList(id int) (*models.List, error)
+	GetTrashedListsForUser(userId int) ([]models.List, error)
+	RestoreList(id int) (success bool, err error)
+	PurgeDeletedLists(before time.Time) (count int64, err error)
 }
 
 type MockListManager struct {
-	CreateListFn     func(list *models.List) (ID int, err error)
-	DeleteListFn     func(id int) (success bool, err error)
-	GetListForUserFn func(id int) (*models.List, error)
-	GetListFn        func(id int) (*models.List, error)
+	CreateListFn      func(list *models.List) (ID int, err error)
+	UpdateListFn      func(list *models.List) (ID int, err error)
+	DeleteListFn      func(id int) (success bool, err error)
+	GetListForUserFn  func(id int) (*models.List, error)
+	GetListsForUserFn func(userId int, archived bool) ([]models.List, error)
+	GetListFn         func(id int) (*models.List, error)
+
+	GetTrashedListFn         func(id int) (*models.List, error)
+	GetTrashedListsForUserFn func(userId int) ([]models.List, error)
+	RestoreListFn            func(id int) (success bool, err error)
+	PurgeDeletedListsFn      func(before time.Time) (count int64, err error)
 }
 
 func (m *MockListManager) CreateList(list *models.List) (int, error) {
@@ -23,6 +41,13 @@ func (m *MockListManager) CreateList(list *models.List) (int, error) {
 	return 0, nil
 }
 
+func (m *MockListManager) UpdateList(list *models.List) (int, error) {
+	if m.UpdateListFn != nil {
+		return m.UpdateListFn(list)
+	}
+	return list.Id, nil
+}
+
 func (m *MockListManager) DeleteList(id int) (bool, error) {
 	if m.DeleteListFn != nil {
 		return m.DeleteListFn(id)
@@ -37,9 +62,44 @@ func (m *MockListManager) GetListForUser(id int) (*models.List, error) {
 	return nil, nil
 }
 
+func (m *MockListManager) GetListsForUser(userId int, archived bool) ([]models.List, error) {
+	if m.GetListsForUserFn != nil {
+		return m.GetListsForUserFn(userId, archived)
+	}
+	return nil, nil
+}
+
 func (m *MockListManager) GetList(id int) (*models.List, error) {
 	if m.GetListFn != nil {
 		return m.GetListFn(id)
 	}
 	return nil, nil
 }
+
+func (m *MockListManager) GetTrashedList(id int) (*models.List, error) {
+	if m.GetTrashedListFn != nil {
+		return m.GetTrashedListFn(id)
+	}
+	return nil, errors.New(messages.ListNotFoundInDb)
+}
+
+func (m *MockListManager) GetTrashedListsForUser(userId int) ([]models.List, error) {
+	if m.GetTrashedListsForUserFn != nil {
+		return m.GetTrashedListsForUserFn(userId)
+	}
+	return nil, nil
+}
+
+func (m *MockListManager) RestoreList(id int) (bool, error) {
+	if m.RestoreListFn != nil {
+		return m.RestoreListFn(id)
+	}
+	return false, nil
+}
+
+func (m *MockListManager) PurgeDeletedLists(before time.Time) (int64, error) {
+	if m.PurgeDeletedListsFn != nil {
+		return m.PurgeDeletedListsFn(before)
+	}
+	return 0, nil
+}
@@ -0,0 +1,49 @@
+package mockmanagers
+
+import (
+	"errors"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+)
+
+type IListTemplateMockManager interface {
+	CreateListTemplate(template *models.ListTemplate) (ID int, err error)
+	GetListTemplate(id int) (*models.ListTemplate, error)
+	GetListTemplatesForUser(userId int) ([]models.ListTemplate, error)
+	DeleteListTemplate(id int) (success bool, err error)
+}
+
+type MockListTemplateManager struct {
+	CreateListTemplateFn      func(template *models.ListTemplate) (ID int, err error)
+	GetListTemplateFn         func(id int) (*models.ListTemplate, error)
+	GetListTemplatesForUserFn func(userId int) ([]models.ListTemplate, error)
+	DeleteListTemplateFn      func(id int) (success bool, err error)
+}
+
+func (m *MockListTemplateManager) CreateListTemplate(template *models.ListTemplate) (int, error) {
+	if m.CreateListTemplateFn != nil {
+		return m.CreateListTemplateFn(template)
+	}
+	return 0, nil
+}
+
+func (m *MockListTemplateManager) GetListTemplate(id int) (*models.ListTemplate, error) {
+	if m.GetListTemplateFn != nil {
+		return m.GetListTemplateFn(id)
+	}
+	return nil, errors.New(messages.ListTemplateNotFoundInDb)
+}
+
+func (m *MockListTemplateManager) GetListTemplatesForUser(userId int) ([]models.ListTemplate, error) {
+	if m.GetListTemplatesForUserFn != nil {
+		return m.GetListTemplatesForUserFn(userId)
+	}
+	return nil, nil
+}
+
+func (m *MockListTemplateManager) DeleteListTemplate(id int) (bool, error) {
+	if m.DeleteListTemplateFn != nil {
+		return m.DeleteListTemplateFn(id)
+	}
+	return false, nil
+}
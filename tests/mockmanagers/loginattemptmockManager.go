@@ -0,0 +1,59 @@
+package mockmanagers
+
+import (
+	"errors"
+	"time"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+)
+
+type ILoginAttemptMockManager interface {
+	GetLoginAttempt(subject string) (*models.LoginAttempt, error)
+	RecordFailedAttempt(subject string, now time.Time, windowStart time.Time) (*models.LoginAttempt, error)
+	LockLoginAttempt(subject string, until time.Time) error
+	DeleteLoginAttempt(subject string) (success bool, err error)
+	DeleteStaleLoginAttempts(before time.Time) (count int64, err error)
+}
+
+type MockLoginAttemptManager struct {
+	GetLoginAttemptFn          func(subject string) (*models.LoginAttempt, error)
+	RecordFailedAttemptFn      func(subject string, now time.Time, windowStart time.Time) (*models.LoginAttempt, error)
+	LockLoginAttemptFn         func(subject string, until time.Time) error
+	DeleteLoginAttemptFn       func(subject string) (success bool, err error)
+	DeleteStaleLoginAttemptsFn func(before time.Time) (count int64, err error)
+}
+
+func (m *MockLoginAttemptManager) GetLoginAttempt(subject string) (*models.LoginAttempt, error) {
+	if m.GetLoginAttemptFn != nil {
+		return m.GetLoginAttemptFn(subject)
+	}
+	return nil, errors.New(messages.LoginAttemptNotFoundInDb)
+}
+
+func (m *MockLoginAttemptManager) RecordFailedAttempt(subject string, now time.Time, windowStart time.Time) (*models.LoginAttempt, error) {
+	if m.RecordFailedAttemptFn != nil {
+		return m.RecordFailedAttemptFn(subject, now, windowStart)
+	}
+	return &models.LoginAttempt{Subject: subject, Failures: 1, LastFailedAt: now}, nil
+}
+
+func (m *MockLoginAttemptManager) LockLoginAttempt(subject string, until time.Time) error {
+	if m.LockLoginAttemptFn != nil {
+		return m.LockLoginAttemptFn(subject, until)
+	}
+	return nil
+}
+
+func (m *MockLoginAttemptManager) DeleteLoginAttempt(subject string) (bool, error) {
+	if m.DeleteLoginAttemptFn != nil {
+		return m.DeleteLoginAttemptFn(subject)
+	}
+	return true, nil
+}
+
+func (m *MockLoginAttemptManager) DeleteStaleLoginAttempts(before time.Time) (int64, error) {
+	if m.DeleteStaleLoginAttemptsFn != nil {
+		return m.DeleteStaleLoginAttemptsFn(before)
+	}
+	return 0, nil
+}
@@ -0,0 +1,59 @@
+package mockmanagers
+
+import (
+	"errors"
+	"time"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+)
+
+type IOneTimeTokenMockManager interface {
+	SaveOneTimeToken(token *models.OneTimeToken) (ID int, err error)
+	ConsumeOneTimeToken(purpose string, tokenHash string, now time.Time) (*models.OneTimeToken, error)
+	GetLatestOneTimeToken(userId int, purpose string) (*models.OneTimeToken, error)
+	DeleteOneTimeTokens(userId int, purpose string) (count int64, err error)
+	DeleteExpiredOneTimeTokens(now time.Time) (count int64, err error)
+}
+
+type MockOneTimeTokenManager struct {
+	SaveOneTimeTokenFn           func(token *models.OneTimeToken) (ID int, err error)
+	ConsumeOneTimeTokenFn        func(purpose string, tokenHash string, now time.Time) (*models.OneTimeToken, error)
+	GetLatestOneTimeTokenFn      func(userId int, purpose string) (*models.OneTimeToken, error)
+	DeleteOneTimeTokensFn        func(userId int, purpose string) (count int64, err error)
+	DeleteExpiredOneTimeTokensFn func(now time.Time) (count int64, err error)
+}
+
+func (m *MockOneTimeTokenManager) SaveOneTimeToken(token *models.OneTimeToken) (int, error) {
+	if m.SaveOneTimeTokenFn != nil {
+		return m.SaveOneTimeTokenFn(token)
+	}
+	return 0, nil
+}
+
+func (m *MockOneTimeTokenManager) ConsumeOneTimeToken(purpose string, tokenHash string, now time.Time) (*models.OneTimeToken, error) {
+	if m.ConsumeOneTimeTokenFn != nil {
+		return m.ConsumeOneTimeTokenFn(purpose, tokenHash, now)
+	}
+	return nil, nil
+}
+
+func (m *MockOneTimeTokenManager) GetLatestOneTimeToken(userId int, purpose string) (*models.OneTimeToken, error) {
+	if m.GetLatestOneTimeTokenFn != nil {
+		return m.GetLatestOneTimeTokenFn(userId, purpose)
+	}
+	return nil, errors.New(messages.OneTimeTokenNotFoundInDb)
+}
+
+func (m *MockOneTimeTokenManager) DeleteOneTimeTokens(userId int, purpose string) (int64, error) {
+	if m.DeleteOneTimeTokensFn != nil {
+		return m.DeleteOneTimeTokensFn(userId, purpose)
+	}
+	return 0, nil
+}
+
+func (m *MockOneTimeTokenManager) DeleteExpiredOneTimeTokens(now time.Time) (int64, error) {
+	if m.DeleteExpiredOneTimeTokensFn != nil {
+		return m.DeleteExpiredOneTimeTokensFn(now)
+	}
+	return 0, nil
+}
@@ -0,0 +1,34 @@
+package mockmanagers
+
+type IRecoveryCodeMockManager interface {
+	ReplaceRecoveryCodes(userId int, codeHashes []string) error
+	UseRecoveryCode(userId int, codeHash string) (success bool, err error)
+	DeleteRecoveryCodes(userId int) (count int64, err error)
+}
+
+type MockRecoveryCodeManager struct {
+	ReplaceRecoveryCodesFn func(userId int, codeHashes []string) error
+	UseRecoveryCodeFn      func(userId int, codeHash string) (success bool, err error)
+	DeleteRecoveryCodesFn  func(userId int) (count int64, err error)
+}
+
+func (m *MockRecoveryCodeManager) ReplaceRecoveryCodes(userId int, codeHashes []string) error {
+	if m.ReplaceRecoveryCodesFn != nil {
+		return m.ReplaceRecoveryCodesFn(userId, codeHashes)
+	}
+	return nil
+}
+
+func (m *MockRecoveryCodeManager) UseRecoveryCode(userId int, codeHash string) (bool, error) {
+	if m.UseRecoveryCodeFn != nil {
+		return m.UseRecoveryCodeFn(userId, codeHash)
+	}
+	return false, nil
+}
+
+func (m *MockRecoveryCodeManager) DeleteRecoveryCodes(userId int) (int64, error) {
+	if m.DeleteRecoveryCodesFn != nil {
+		return m.DeleteRecoveryCodesFn(userId)
+	}
+	return 0, nil
+}
@@ -0,0 +1,84 @@
+package mockmanagers
+
+import (
+	"time"
+	"todo-web-api/models"
+)
+
+type ISessionMockManager interface {
+	SaveSession(session *models.Session) (ID int, err error)
+	GetSession(sessionId string) (*models.Session, error)
+	GetSessionsForUser(userId int) ([]models.Session, error)
+	RotateSessionTokens(sessionId, currentRefreshToken, accessToken, refreshToken string, expiresAt time.Time) (success bool, err error)
+	DeleteSession(sessionId string) (success bool, err error)
+	DeleteSessionsForUser(userId int) (count int64, err error)
+	DeleteOtherSessions(userId int, keepSessionId string) (count int64, err error)
+	DeleteExpiredSessions(now time.Time) (count int64, err error)
+}
+
+type MockSessionManager struct {
+	SaveSessionFn           func(session *models.Session) (ID int, err error)
+	GetSessionFn            func(sessionId string) (*models.Session, error)
+	GetSessionsForUserFn    func(userId int) ([]models.Session, error)
+	RotateSessionTokensFn   func(sessionId, currentRefreshToken, accessToken, refreshToken string, expiresAt time.Time) (success bool, err error)
+	DeleteSessionFn         func(sessionId string) (success bool, err error)
+	DeleteSessionsForUserFn func(userId int) (count int64, err error)
+	DeleteOtherSessionsFn   func(userId int, keepSessionId string) (count int64, err error)
+	DeleteExpiredSessionsFn func(now time.Time) (count int64, err error)
+}
+
+func (m *MockSessionManager) SaveSession(session *models.Session) (int, error) {
+	if m.SaveSessionFn != nil {
+		return m.SaveSessionFn(session)
+	}
+	return 0, nil
+}
+
+func (m *MockSessionManager) GetSession(sessionId string) (*models.Session, error) {
+	if m.GetSessionFn != nil {
+		return m.GetSessionFn(sessionId)
+	}
+	return nil, nil
+}
+
+func (m *MockSessionManager) GetSessionsForUser(userId int) ([]models.Session, error) {
+	if m.GetSessionsForUserFn != nil {
+		return m.GetSessionsForUserFn(userId)
+	}
+	return nil, nil
+}
+
+func (m *MockSessionManager) RotateSessionTokens(sessionId, currentRefreshToken, accessToken, refreshToken string, expiresAt time.Time) (bool, error) {
+	if m.RotateSessionTokensFn != nil {
+		return m.RotateSessionTokensFn(sessionId, currentRefreshToken, accessToken, refreshToken, expiresAt)
+	}
+	return true, nil
+}
+
+func (m *MockSessionManager) DeleteSession(sessionId string) (bool, error) {
+	if m.DeleteSessionFn != nil {
+		return m.DeleteSessionFn(sessionId)
+	}
+	return false, nil
+}
+
+func (m *MockSessionManager) DeleteSessionsForUser(userId int) (int64, error) {
+	if m.DeleteSessionsForUserFn != nil {
+		return m.DeleteSessionsForUserFn(userId)
+	}
+	return 0, nil
+}
+
+func (m *MockSessionManager) DeleteOtherSessions(userId int, keepSessionId string) (int64, error) {
+	if m.DeleteOtherSessionsFn != nil {
+		return m.DeleteOtherSessionsFn(userId, keepSessionId)
+	}
+	return 0, nil
+}
+
+func (m *MockSessionManager) DeleteExpiredSessions(now time.Time) (int64, error) {
+	if m.DeleteExpiredSessionsFn != nil {
+		return m.DeleteExpiredSessionsFn(now)
+	}
+	return 0, nil
+}
@@ -1,12 +1,23 @@
 package mockmanagers
 
-import "todo-web-api/models"
+import (
+	"errors"
+	"time"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+)
 
 type ITaskMockManager interface {
 	CreateTask(task *models.Task, listId int) (ID int, err error)
 	DeleteTask(id int) (success bool, err error)
 	GetTask(id int) (*models.Task, error)
 	UpdateTask(task *models.Task) (ID int, err error)
+	GetTasksForList(listId int) ([]models.Task, error)
+	GetDueTasksForUser(userId int, from time.Time, to time.Time, openOnly bool) ([]models.Task, error)
+	GetTrashedTask(id int) (*models.Task, error)
+	GetTrashedTasksForUser(userId int) ([]models.Task, error)
+	RestoreTask(id int) (success bool, err error)
+	PurgeDeletedTasks(before time.Time) (count int64, err error)
 }
 
 type MockTaskManager struct {
@@ -14,6 +25,14 @@ type MockTaskManager struct {
 	DeleteTaskFn func(id int) (success bool, err error)
 	GetTaskFn    func(id int) (*models.Task, error)
 	UpdateTaskFn func(task *models.Task) (ID int, err error)
+
+	GetTasksForListFn    func(listId int) ([]models.Task, error)
+	GetDueTasksForUserFn func(userId int, from time.Time, to time.Time, openOnly bool) ([]models.Task, error)
+
+	GetTrashedTaskFn         func(id int) (*models.Task, error)
+	GetTrashedTasksForUserFn func(userId int) ([]models.Task, error)
+	RestoreTaskFn            func(id int) (success bool, err error)
+	PurgeDeletedTasksFn      func(before time.Time) (count int64, err error)
 }
 
 func (m *MockTaskManager) CreateTask(task *models.Task, listId int) (ID int, err error) {
@@ -43,3 +62,45 @@ func (m *MockTaskManager) UpdateTask(task *models.Task) (int, error) {
 	}
 	return 0, nil
 }
+
+func (m *MockTaskManager) GetTasksForList(listId int) ([]models.Task, error) {
+	if m.GetTasksForListFn != nil {
+		return m.GetTasksForListFn(listId)
+	}
+	return nil, nil
+}
+
+func (m *MockTaskManager) GetDueTasksForUser(userId int, from time.Time, to time.Time, openOnly bool) ([]models.Task, error) {
+	if m.GetDueTasksForUserFn != nil {
+		return m.GetDueTasksForUserFn(userId, from, to, openOnly)
+	}
+	return nil, nil
+}
+
+func (m *MockTaskManager) GetTrashedTask(id int) (*models.Task, error) {
+	if m.GetTrashedTaskFn != nil {
+		return m.GetTrashedTaskFn(id)
+	}
+	return nil, errors.New(messages.TaskNotFoundInDb)
+}
+
+func (m *MockTaskManager) GetTrashedTasksForUser(userId int) ([]models.Task, error) {
+	if m.GetTrashedTasksForUserFn != nil {
+		return m.GetTrashedTasksForUserFn(userId)
+	}
+	return nil, nil
+}
+
+func (m *MockTaskManager) RestoreTask(id int) (bool, error) {
+	if m.RestoreTaskFn != nil {
+		return m.RestoreTaskFn(id)
+	}
+	return false, nil
+}
+
+func (m *MockTaskManager) PurgeDeletedTasks(before time.Time) (int64, error) {
+	if m.PurgeDeletedTasksFn != nil {
+		return m.PurgeDeletedTasksFn(before)
+	}
+	return 0, nil
+}
@@ -1,19 +1,32 @@
 package mockmanagers
 
-import "todo-web-api/models"
+import (
+	"time"
+	"todo-web-api/models"
+)
 
 type IUserMockManager interface {
 	CreateUser(user *models.User) (ID int, err error)
 	DeleteUser(id int) (success bool, err error)
 	GetUser(id int) (*models.User, error)
+	GetUsers(search string) ([]models.User, error)
+	UpdateUser(user *models.User) (ID int, err error)
 	FindExistingAccount(username string, password string) (*models.User, error)
+	GetUserByVerifiedEmail(email string) (*models.User, error)
+	AdvanceTOTPCounter(userId int, counter int64) (success bool, err error)
+	GetUsersDueForDeletion(now time.Time) ([]models.User, error)
 }
 
 type MockUserManager struct {
-	CreateUserFn          func(user *models.User) (int, error)
-	DeleteUserFn          func(id int) (bool, error)
-	GetUserFn             func(id int) (*models.User, error)
-	FindExistingAccountFn func(username string, password string) (*models.User, error)
+	CreateUserFn             func(user *models.User) (int, error)
+	DeleteUserFn             func(id int) (bool, error)
+	GetUserFn                func(id int) (*models.User, error)
+	GetUsersFn               func(search string) ([]models.User, error)
+	UpdateUserFn             func(user *models.User) (int, error)
+	FindExistingAccountFn    func(username string, password string) (*models.User, error)
+	GetUserByVerifiedEmailFn func(email string) (*models.User, error)
+	AdvanceTOTPCounterFn     func(userId int, counter int64) (bool, error)
+	GetUsersDueForDeletionFn func(now time.Time) ([]models.User, error)
 }
 
 func (m *MockUserManager) CreateUser(user *models.User) (int, error) {
@@ -28,6 +41,29 @@ func (m *MockUserManager) GetUser(id int) (*models.User, error) {
 	return m.GetUserFn(id)
 }
 
+func (m *MockUserManager) GetUsers(search string) ([]models.User, error) {
+	return m.GetUsersFn(search)
+}
+
+func (m *MockUserManager) UpdateUser(user *models.User) (int, error) {
+	if m.UpdateUserFn == nil {
+		return user.Id, nil
+	}
+	return m.UpdateUserFn(user)
+}
+
 func (m *MockUserManager) FindExistingAccount(username string, password string) (*models.User, error) {
 	return m.FindExistingAccountFn(username, password)
 }
+
+func (m *MockUserManager) GetUserByVerifiedEmail(email string) (*models.User, error) {
+	return m.GetUserByVerifiedEmailFn(email)
+}
+
+func (m *MockUserManager) AdvanceTOTPCounter(userId int, counter int64) (bool, error) {
+	return m.AdvanceTOTPCounterFn(userId, counter)
+}
+
+func (m *MockUserManager) GetUsersDueForDeletion(now time.Time) ([]models.User, error) {
+	return m.GetUsersDueForDeletionFn(now)
+}
@@ -0,0 +1,47 @@
+package storagetests
+
+import (
+	"testing"
+	"todo-web-api/storage"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func Test_Delete_Access_Token_Scoped_To_Owner(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	defer mock.ExpectClose()
+
+	storage.Context = db
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `personal_access_tokens` WHERE id = \\? AND user_id = \\?").
+		WithArgs(3, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	success, err := storage.AccessTokenManager.DeleteAccessToken(2, 3)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	assert.Nil(t, err)
+	assert.False(t, success)
+}
+
+func Test_Get_Access_Token_By_Hash_NotFound(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	defer mock.ExpectClose()
+
+	storage.Context = db
+
+	mock.ExpectQuery("SELECT \\* FROM `personal_access_tokens` WHERE token_hash = \\? ORDER BY `personal_access_tokens`.`id` LIMIT \\?").
+		WithArgs("digest", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "token_hash"}))
+
+	token, err := storage.AccessTokenManager.GetAccessTokenByHash("digest")
+
+	assert.Nil(t, token)
+	assert.Equal(t, "Access token record not found in db", err.Error())
+}
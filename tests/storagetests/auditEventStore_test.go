@@ -0,0 +1,82 @@
+package storagetests
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func Test_Create_Audit_Event(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	defer mock.ExpectClose()
+
+	storage.Context = db
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `audit_events`").
+		WithArgs(1, "u1", "login", "failure", "invalid_password", "203.0.113.7", "curl/8.0", "req-1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(5, 1))
+	mock.ExpectCommit()
+
+	id, err := storage.AuditEventManager.CreateAuditEvent(&models.AuditEvent{
+		UserId:    1,
+		Username:  "u1",
+		EventType: "login",
+		Outcome:   "failure",
+		Detail:    "invalid_password",
+		IPAddress: "203.0.113.7",
+		UserAgent: "curl/8.0",
+		RequestId: "req-1",
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	assert.Nil(t, err)
+	assert.Equal(t, 5, id)
+}
+
+func Test_Get_Audit_Events_For_User(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	defer mock.ExpectClose()
+
+	storage.Context = db
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT \\* FROM `audit_events` WHERE user_id = \\? ORDER BY created_at desc, id desc LIMIT \\?").
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "event_type", "outcome", "created_at"}).
+			AddRow(7, 1, "logout", "success", now).
+			AddRow(6, 1, "login", "success", now.Add(-time.Minute)))
+
+	events, err := storage.AuditEventManager.GetAuditEventsForUser(1, 2)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	assert.Nil(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, "logout", events[0].EventType)
+}
+
+func Test_Get_Audit_Events_Error(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	defer mock.ExpectClose()
+
+	storage.Context = db
+
+	mock.ExpectQuery("SELECT \\* FROM `audit_events` WHERE user_id = \\?").
+		WillReturnError(errors.New("connection reset"))
+
+	_, err := storage.AuditEventManager.GetAuditEventsForUser(1, 50)
+
+	assert.EqualError(t, err, messages.AuditEventQueryInternalError)
+}
@@ -0,0 +1,29 @@
+package storagetests
+
+import (
+	"testing"
+	"todo-web-api/storage"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func Test_Get_External_Identity_By_Provider_And_Subject(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	defer mock.ExpectClose()
+
+	storage.Context = db
+
+	mock.ExpectQuery("SELECT \\* FROM `external_identities` WHERE provider = \\? AND subject = \\? ORDER BY `external_identities`.`id` LIMIT \\?").
+		WithArgs("corp", "corp-123", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "provider", "subject"}).AddRow(1, 3, "corp", "corp-123"))
+
+	identity, err := storage.ExternalIdentityManager.GetExternalIdentity("corp", "corp-123")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, identity.UserId)
+}
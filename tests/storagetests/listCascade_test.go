@@ -0,0 +1,162 @@
+package storagetests
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+	"todo-web-api/storagelite"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// Sqlite_Db_Setup connects the SQLite stores to a new database file in a
+// temporary directory, the way the server does. Unlike the mocked MySQL
+// tests, the foreign keys here are real.
+func Sqlite_Db_Setup(t *testing.T) string {
+	dbPath := filepath.Join(t.TempDir(), "todo.db")
+	t.Setenv("SQLITE_PATH", dbPath)
+	t.Cleanup(func() {
+		if sqlDb, err := storagelite.Context.DB(); err == nil {
+			sqlDb.Close()
+		}
+	})
+	return dbPath
+}
+
+// countTasks counts the list's tasks, trashed or not.
+func countTasks(listId int) int64 {
+	var count int64
+	storagelite.Context.Unscoped().Model(&models.Task{}).Where("list_id = ?", listId).Count(&count)
+	return count
+}
+
+func Test_Restore_List_Brings_Back_Its_Tasks(t *testing.T) {
+	Sqlite_Db_Setup(t)
+	(&storagelite.StoreManagerLite{}).Connect("", "", "", "", "")
+	storage.Sqlite()
+
+	list := &models.List{Title: "Groceries", UserId: 1}
+	storage.ListManager.CreateList(list)
+	milk := &models.Task{Title: "Milk", ListId: list.Id}
+	eggs := &models.Task{Title: "Eggs", ListId: list.Id}
+	storage.TaskManager.CreateTask(milk, list.Id)
+	storage.TaskManager.CreateTask(eggs, list.Id)
+	storage.ListMemberManager.CreateListMember(&models.ListMember{ListId: list.Id, UserId: 2, Role: "viewer"})
+
+	// Eggs goes in the trash on its own, then the list with Milk.
+	storage.TaskManager.DeleteTask(eggs.Id)
+	success, err := storage.ListManager.DeleteList(list.Id)
+
+	assert.Nil(t, err)
+	assert.True(t, success)
+	_, err = storage.ListManager.GetList(list.Id)
+	assert.NotNil(t, err)
+	_, err = storage.TaskManager.GetTask(milk.Id)
+	assert.NotNil(t, err)
+	lists, _ := storage.ListManager.GetTrashedListsForUser(1)
+	assert.Len(t, lists, 1)
+	// Tasks in a trashed list come back with it, so aren't listed alone.
+	tasks, _ := storage.TaskManager.GetTrashedTasksForUser(1)
+	assert.Empty(t, tasks)
+
+	success, err = storage.ListManager.RestoreList(list.Id)
+
+	assert.Nil(t, err)
+	assert.True(t, success)
+	restored, err := storage.ListManager.GetList(list.Id)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "Groceries", restored.Title)
+	}
+	_, err = storage.TaskManager.GetTask(milk.Id)
+	assert.Nil(t, err)
+	tasks, _ = storage.TaskManager.GetTrashedTasksForUser(1)
+	if assert.Len(t, tasks, 1) {
+		assert.Equal(t, eggs.Id, tasks[0].Id)
+	}
+	_, err = storage.ListMemberManager.GetListMember(list.Id, 2)
+	assert.Nil(t, err)
+}
+
+func Test_Purge_Leaves_No_Orphans(t *testing.T) {
+	Sqlite_Db_Setup(t)
+	(&storagelite.StoreManagerLite{}).Connect("", "", "", "", "")
+	storage.Sqlite()
+
+	doomed := &models.List{Title: "Groceries", UserId: 1}
+	kept := &models.List{Title: "Work", UserId: 1}
+	storage.ListManager.CreateList(doomed)
+	storage.ListManager.CreateList(kept)
+	report := &models.Task{Title: "Report", ListId: kept.Id}
+	for _, task := range []*models.Task{{Title: "Milk", ListId: doomed.Id}, {Title: "Eggs", ListId: doomed.Id}, report, {Title: "Slides", ListId: kept.Id}} {
+		storage.TaskManager.CreateTask(task, task.ListId)
+	}
+	storage.ListMemberManager.CreateListMember(&models.ListMember{ListId: doomed.Id, UserId: 2, Role: "viewer"})
+	storage.ListManager.DeleteList(doomed.Id)
+	storage.TaskManager.DeleteTask(report.Id)
+
+	// Nothing has been in the trash for long enough yet.
+	count, err := storage.ListManager.PurgeDeletedLists(time.Now().Add(-time.Hour))
+	assert.Nil(t, err)
+	assert.Zero(t, count)
+	assert.EqualValues(t, 2, countTasks(doomed.Id))
+
+	count, err = storage.ListManager.PurgeDeletedLists(time.Now())
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, count)
+	count, err = storage.TaskManager.PurgeDeletedTasks(time.Now())
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, count)
+
+	assert.Zero(t, countTasks(doomed.Id))
+	assert.EqualValues(t, 1, countTasks(kept.Id))
+	_, err = storage.ListManager.GetTrashedList(doomed.Id)
+	assert.NotNil(t, err)
+	_, err = storage.ListMemberManager.GetListMember(doomed.Id, 2)
+	assert.NotNil(t, err)
+}
+
+func Test_List_Foreign_Keys_Cascade(t *testing.T) {
+	Sqlite_Db_Setup(t)
+	(&storagelite.StoreManagerLite{}).Connect("", "", "", "", "")
+	storage.Sqlite()
+
+	list := &models.List{Title: "Groceries", UserId: 1}
+	storage.ListManager.CreateList(list)
+	storage.TaskManager.CreateTask(&models.Task{Title: "Milk", ListId: list.Id}, list.Id)
+
+	// Even a delete that bypasses the stores takes the tasks with it...
+	assert.Nil(t, storagelite.Context.Exec("DELETE FROM lists WHERE id = ?", list.Id).Error)
+	assert.Zero(t, countTasks(list.Id))
+
+	// ...and a task can't point at a list that doesn't exist.
+	_, err := storage.TaskManager.CreateTask(&models.Task{Title: "Stray", ListId: list.Id}, list.Id)
+	assert.NotNil(t, err)
+}
+
+func Test_Migration_Removes_Existing_Orphans(t *testing.T) {
+	dbPath := Sqlite_Db_Setup(t)
+
+	// A database from before the foreign keys, with a task left behind by
+	// a deleted list.
+	old, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	old.Exec("CREATE TABLE `lists` (`id` integer PRIMARY KEY AUTOINCREMENT, `user_id` integer, `created_at` datetime)")
+	old.Exec("CREATE TABLE `tasks` (`id` integer PRIMARY KEY AUTOINCREMENT, `title` text NOT NULL, `description` text, `is_completed` numeric DEFAULT false, `list_id` integer, `created_at` datetime)")
+	old.Exec("INSERT INTO lists (id, user_id) VALUES (1, 1)")
+	old.Exec("INSERT INTO tasks (title, list_id) VALUES ('Milk', 1), ('Orphan', 2)")
+	if sqlDb, err := old.DB(); err == nil {
+		sqlDb.Close()
+	}
+
+	(&storagelite.StoreManagerLite{}).Connect("", "", "", "", "")
+
+	assert.EqualValues(t, 1, countTasks(1))
+	assert.Zero(t, countTasks(2))
+	assert.True(t, storagelite.Context.Migrator().HasConstraint(&models.List{}, "Tasks"))
+}
@@ -0,0 +1,93 @@
+package storagetests
+
+import (
+	"testing"
+	"time"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func Test_Create_List_Member(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	defer mock.ExpectClose()
+
+	storage.Context = db
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `list_members`").
+		WithArgs(10, 2, "u2", "editor", 1, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(4, 1))
+	mock.ExpectCommit()
+
+	id, err := storage.ListMemberManager.CreateListMember(&models.ListMember{
+		ListId: 10, UserId: 2, Username: "u2", Role: "editor", InvitedBy: 1})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	assert.Nil(t, err)
+	assert.Equal(t, 4, id)
+}
+
+func Test_Get_List_Member_Not_Found(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	defer mock.ExpectClose()
+
+	storage.Context = db
+
+	mock.ExpectQuery("SELECT \\* FROM `list_members` WHERE list_id = \\? AND user_id = \\?").
+		WithArgs(10, 2, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	member, err := storage.ListMemberManager.GetListMember(10, 2)
+
+	assert.Nil(t, member)
+	assert.EqualError(t, err, messages.ListMemberNotFoundInDb)
+}
+
+func Test_Get_Pending_Invitations(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	defer mock.ExpectClose()
+
+	storage.Context = db
+
+	mock.ExpectQuery("SELECT \\* FROM `list_members` WHERE user_id = \\? AND accepted_at IS NULL ORDER BY id desc").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "list_id", "user_id", "role", "created_at"}).
+			AddRow(5, 20, 2, "viewer", time.Now()))
+
+	invitations, err := storage.ListMemberManager.GetListMembershipsForUser(2, false)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	assert.Nil(t, err)
+	if assert.Len(t, invitations, 1) {
+		assert.Equal(t, 20, invitations[0].ListId)
+		assert.Nil(t, invitations[0].AcceptedAt)
+	}
+}
+
+func Test_Delete_List_Member_Not_Found(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	defer mock.ExpectClose()
+
+	storage.Context = db
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `list_members` WHERE `list_members`.`id` = \\?").
+		WithArgs(5).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	success, err := storage.ListMemberManager.DeleteListMember(5)
+
+	assert.False(t, success)
+	assert.EqualError(t, err, messages.ListMemberNotFoundInDb)
+}
@@ -1,8 +1,10 @@
 package storagetests
 
 import (
+	"errors"
 	"testing"
 	"time"
+	"todo-web-api/messages"
 	"todo-web-api/models"
 	"todo-web-api/storage"
 
@@ -17,12 +19,12 @@ func Test_Create_List(t *testing.T) {
 	storage.Context = db
 
 	mock.ExpectBegin()
-	mock.ExpectExec("INSERT INTO `lists` \\(`user_id`,`created_at`,`id`\\) VALUES \\(\\?,\\?,\\?\\)").
-		WithArgs(1, sqlmock.AnyArg(), 1).
+	mock.ExpectExec("INSERT INTO `lists` \\(`title`,`description`,`color`,`icon`,`position`,`archived_at`,`user_id`,`created_at`,`deleted_at`,`id`\\) VALUES \\(\\?,\\?,\\?,\\?,\\?,\\?,\\?,\\?,\\?,\\?\\)").
+		WithArgs("Groceries", "", "", "", 0, nil, 1, sqlmock.AnyArg(), nil, 1).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
-	_, err := storage.ListManager.CreateList(&models.List{UserId: 1, Id: 1, CreatedAt: time.Now()})
+	_, err := storage.ListManager.CreateList(&models.List{Title: "Groceries", UserId: 1, Id: 1, CreatedAt: time.Now()})
 
 	if err != nil {
 		t.Errorf("Failed to create list: %s", err)
@@ -43,7 +45,7 @@ func Test_Get_List_By_Id(t *testing.T) {
 
 	storage.Context = db
 
-	mock.ExpectQuery("SELECT \\* FROM `lists` WHERE `lists`.`id` = \\? ORDER BY `lists`.`id` LIMIT \\?").
+	mock.ExpectQuery("SELECT \\* FROM `lists` WHERE `lists`.`id` = \\? AND `lists`.`deleted_at` IS NULL ORDER BY `lists`.`id` LIMIT \\?").
 		WithArgs(1, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "created_at"}).
 			AddRow(listID, userID, createdAt))
@@ -59,6 +61,39 @@ func Test_Get_List_By_Id(t *testing.T) {
 	}
 }
 
+func Test_Get_List_Errors(t *testing.T) {
+	var tests = []struct {
+		name    string
+		dbError error
+		want    string
+	}{
+		{"Not found", nil, messages.ListNotFoundInDb},
+		{"Query failed", errors.New("connection reset"), messages.ListQueryInternalError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := Mock_Db_Setup()
+			storage.Context = db
+
+			query := mock.ExpectQuery("SELECT \\* FROM `lists` WHERE `lists`.`id` = \\? AND `lists`.`deleted_at` IS NULL").
+				WithArgs(1, 1)
+			if tt.dbError != nil {
+				query.WillReturnError(tt.dbError)
+			} else {
+				query.WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			}
+
+			list, err := storage.ListManager.GetList(1)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+			assert.Nil(t, list)
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
+
 func Test_Delete_List(t *testing.T) {
 	db, mock := Mock_Db_Setup()
 	//defer mock.ExpectClose()
@@ -69,15 +104,18 @@ func Test_Delete_List(t *testing.T) {
 	userID := 1
 	createdAt := time.Now()
 
-	mock.ExpectQuery("SELECT \\* FROM `lists` WHERE `lists`.`id` = \\? ORDER BY `lists`.`id` LIMIT \\?").
+	mock.ExpectQuery("SELECT \\* FROM `lists` WHERE `lists`.`id` = \\? AND `lists`.`deleted_at` IS NULL ORDER BY `lists`.`id` LIMIT \\?").
 		WithArgs(1, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "created_at"}).
 			AddRow(listID, userID, createdAt))
 
 	mock.ExpectBegin()
-	mock.ExpectExec("DELETE FROM `lists` WHERE `lists`.`id` = \\?").
-		WithArgs(listID).
-		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE `tasks` SET `deleted_at`=\\? WHERE list_id = \\? AND `tasks`.`deleted_at` IS NULL").
+		WithArgs(sqlmock.AnyArg(), listID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("UPDATE `lists` SET `deleted_at`=\\? WHERE `lists`.`deleted_at` IS NULL AND `id` = \\?").
+		WithArgs(sqlmock.AnyArg(), listID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectCommit()
 
 	success, err := storage.ListManager.DeleteList(1)
@@ -96,3 +134,155 @@ func Test_Delete_List(t *testing.T) {
 
 	assert.True(t, success)
 }
+
+func Test_Delete_List_Rolls_Back_On_Failure(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	defer mock.ExpectClose()
+
+	storage.Context = db
+
+	mock.ExpectQuery("SELECT \\* FROM `lists` WHERE `lists`.`id` = \\? AND `lists`.`deleted_at` IS NULL ORDER BY `lists`.`id` LIMIT \\?").
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(1, 1))
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `tasks` SET `deleted_at`").
+		WithArgs(sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("UPDATE `lists` SET `deleted_at`").
+		WithArgs(sqlmock.AnyArg(), 1).
+		WillReturnError(errors.New("lock wait timeout"))
+	mock.ExpectRollback()
+
+	success, err := storage.ListManager.DeleteList(1)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	assert.NotNil(t, err)
+	assert.False(t, success)
+}
+
+func Test_Get_Lists_For_User(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+
+	storage.Context = db
+	createdAt := time.Now()
+
+	mock.ExpectQuery("SELECT \\* FROM `lists` WHERE \\(user_id = \\? OR id IN \\(SELECT `list_id` FROM `list_members` WHERE user_id = \\? AND accepted_at IS NOT NULL\\)\\) AND archived_at IS NULL AND `lists`.`deleted_at` IS NULL ORDER BY position, id").
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "user_id", "created_at"}).
+			AddRow(1, "Groceries", 1, createdAt).
+			AddRow(2, "Work", 3, createdAt))
+	mock.ExpectQuery("SELECT \\* FROM `tasks` WHERE `tasks`.`list_id` IN \\(\\?,\\?\\) AND `tasks`.`deleted_at` IS NULL").
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "list_id"}).
+			AddRow(5, "Milk", 1))
+
+	lists, err := storage.ListManager.GetListsForUser(1, false)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	assert.Nil(t, err)
+	if assert.Len(t, lists, 2) {
+		assert.Equal(t, "Work", lists[1].Title)
+		assert.Len(t, lists[0].Tasks, 1)
+		assert.Empty(t, lists[1].Tasks)
+	}
+}
+
+func Test_Update_List(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+
+	storage.Context = db
+	archivedAt := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `lists` SET `title`=\\?,`description`=\\?,`color`=\\?,`icon`=\\?,`position`=\\?,`archived_at`=\\?,`user_id`=\\?,`created_at`=\\?,`deleted_at`=\\? WHERE `lists`.`deleted_at` IS NULL AND `id` = \\?").
+		WithArgs("Work", "", "#1e90ff", "briefcase", 2, archivedAt, 1, sqlmock.AnyArg(), nil, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	id, err := storage.ListManager.UpdateList(&models.List{Id: 3, Title: "Work", Color: "#1e90ff", Icon: "briefcase",
+		Position: 2, ArchivedAt: &archivedAt, UserId: 1, CreatedAt: time.Now()})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, id)
+}
+
+func Test_Restore_List(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	storage.Context = db
+	deletedAt := time.Now().Add(-time.Hour)
+
+	mock.ExpectQuery("SELECT \\* FROM `lists` WHERE deleted_at IS NOT NULL AND `lists`.`id` = \\? ORDER BY `lists`.`id` LIMIT \\?").
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "deleted_at"}).AddRow(1, 1, deletedAt))
+	mock.ExpectBegin()
+	// Only the tasks that went in the trash with the list.
+	mock.ExpectExec("UPDATE `tasks` SET `deleted_at`=\\? WHERE list_id = \\? AND deleted_at = \\?").
+		WithArgs(nil, 1, deletedAt).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("UPDATE `lists` SET `deleted_at`=\\? WHERE `id` = \\?").
+		WithArgs(nil, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	success, err := storage.ListManager.RestoreList(1)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	assert.Nil(t, err)
+	assert.True(t, success)
+}
+
+func Test_Get_Trashed_Lists_For_User(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	storage.Context = db
+
+	mock.ExpectQuery("SELECT \\* FROM `lists` WHERE user_id = \\? AND deleted_at IS NOT NULL ORDER BY deleted_at desc, id").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "user_id", "deleted_at"}).
+			AddRow(2, "Work", 1, time.Now()).
+			AddRow(1, "Groceries", 1, time.Now().Add(-time.Hour)))
+
+	lists, err := storage.ListManager.GetTrashedListsForUser(1)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	assert.Nil(t, err)
+	assert.Len(t, lists, 2)
+}
+
+func Test_Purge_Deleted_Lists(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	storage.Context = db
+	before := time.Now().AddDate(0, 0, -30)
+
+	mock.ExpectBegin()
+	for _, table := range []string{"tasks", "list_members"} {
+		mock.ExpectExec("DELETE FROM `" + table + "` WHERE list_id IN \\(SELECT `id` FROM `lists` WHERE deleted_at < \\?\\)").
+			WithArgs(before).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+	}
+	mock.ExpectExec("DELETE FROM `lists` WHERE deleted_at < \\?").
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	count, err := storage.ListManager.PurgeDeletedLists(before)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, count)
+}
@@ -0,0 +1,169 @@
+package storagetests
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"todo-web-api/messages"
+	"todo-web-api/models"
+	"todo-web-api/storage"
+	"todo-web-api/storagelite"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func Test_Create_List_Template(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	defer mock.ExpectClose()
+
+	storage.Context = db
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `list_templates`").
+		WithArgs(1, "Packing", "", "", "suitcase", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+	mock.ExpectExec("INSERT INTO `template_tasks` \\(`template_id`,`title`,`description`\\) VALUES \\(\\?,\\?,\\?\\),\\(\\?,\\?,\\?\\) ON DUPLICATE KEY UPDATE `template_id`=VALUES\\(`template_id`\\)").
+		WithArgs(7, "Passport", "", 7, "Charger", "").
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectCommit()
+
+	id, err := storage.ListTemplateManager.CreateListTemplate(&models.ListTemplate{UserId: 1, Title: "Packing", Icon: "suitcase",
+		Tasks: []models.TemplateTask{{Title: "Passport"}, {Title: "Charger"}}})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	assert.Nil(t, err)
+	assert.Equal(t, 7, id)
+}
+
+func Test_Get_List_Template(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	defer mock.ExpectClose()
+
+	storage.Context = db
+
+	mock.ExpectQuery("SELECT \\* FROM `list_templates` WHERE `list_templates`.`id` = \\? ORDER BY `list_templates`.`id` LIMIT \\?").
+		WithArgs(7, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "title"}).AddRow(7, 1, "Packing"))
+	mock.ExpectQuery("SELECT \\* FROM `template_tasks` WHERE `template_tasks`.`template_id` = \\? ORDER BY id").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "template_id", "title"}).
+			AddRow(1, 7, "Passport").
+			AddRow(2, 7, "Charger"))
+
+	template, err := storage.ListTemplateManager.GetListTemplate(7)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	assert.Nil(t, err)
+	if assert.NotNil(t, template) && assert.Len(t, template.Tasks, 2) {
+		assert.Equal(t, "Passport", template.Tasks[0].Title)
+	}
+}
+
+func Test_Get_List_Template_Not_Found(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	defer mock.ExpectClose()
+
+	storage.Context = db
+
+	mock.ExpectQuery("SELECT \\* FROM `list_templates`").
+		WithArgs(7, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	template, err := storage.ListTemplateManager.GetListTemplate(7)
+
+	assert.Nil(t, template)
+	assert.EqualError(t, err, messages.ListTemplateNotFoundInDb)
+}
+
+func Test_Delete_List_Template(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	defer mock.ExpectClose()
+
+	storage.Context = db
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `template_tasks` WHERE template_id = \\?").
+		WithArgs(7).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM `list_templates` WHERE `list_templates`.`id` = \\?").
+		WithArgs(7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	success, err := storage.ListTemplateManager.DeleteListTemplate(7)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	assert.Nil(t, err)
+	assert.True(t, success)
+}
+
+func Test_Delete_List_Template_Rolls_Back_On_Failure(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	defer mock.ExpectClose()
+
+	storage.Context = db
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `template_tasks`").
+		WithArgs(7).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM `list_templates`").
+		WithArgs(7).
+		WillReturnError(errors.New("lock wait timeout"))
+	mock.ExpectRollback()
+
+	success, err := storage.ListTemplateManager.DeleteListTemplate(7)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	assert.EqualError(t, err, messages.ListTemplateQueryInternalError)
+	assert.False(t, success)
+}
+
+func Test_List_From_Template_Round_Trip(t *testing.T) {
+	Sqlite_Db_Setup(t)
+	(&storagelite.StoreManagerLite{}).Connect("", "", "", "", "")
+	storage.Sqlite()
+
+	template := &models.ListTemplate{UserId: 1, Title: "Packing",
+		Tasks: []models.TemplateTask{{Title: "Passport"}, {Title: "Charger"}}}
+	_, err := storage.ListTemplateManager.CreateListTemplate(template)
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+
+	saved, err := storage.ListTemplateManager.GetListTemplate(template.Id)
+	assert.Nil(t, err)
+	list := &models.List{UserId: 1, Title: saved.Title, CreatedAt: time.Now()}
+	for _, task := range saved.Tasks {
+		list.Tasks = append(list.Tasks, models.Task{Title: task.Title})
+	}
+	_, err = storage.ListManager.CreateList(list)
+	assert.Nil(t, err)
+
+	tasks, err := storage.TaskManager.GetTasksForList(list.Id)
+	assert.Nil(t, err)
+	if assert.Len(t, tasks, 2) {
+		assert.Equal(t, "Passport", tasks[0].Title)
+		assert.Equal(t, "Charger", tasks[1].Title)
+	}
+
+	// Deleting the template leaves the list it made alone.
+	success, err := storage.ListTemplateManager.DeleteListTemplate(template.Id)
+	assert.Nil(t, err)
+	assert.True(t, success)
+	templates, _ := storage.ListTemplateManager.GetListTemplatesForUser(1)
+	assert.Empty(t, templates)
+	assert.EqualValues(t, 2, countTasks(list.Id))
+
+	_, err = storage.ListTemplateManager.DeleteListTemplate(template.Id)
+	assert.EqualError(t, err, messages.ListTemplateNotFoundInDb)
+}
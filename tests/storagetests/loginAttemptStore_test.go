@@ -0,0 +1,52 @@
+package storagetests
+
+import (
+	"testing"
+	"time"
+	"todo-web-api/storage"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func Test_Record_Failed_Attempt_Upserts(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	defer mock.ExpectClose()
+
+	storage.Context = db
+	now := time.Now()
+	windowStart := now.Add(-time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `login_attempts` .* ON DUPLICATE KEY UPDATE `failures`=CASE WHEN last_failed_at < \\? THEN 1 ELSE failures \\+ 1 END,`last_failed_at`=\\?").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT \\* FROM `login_attempts` WHERE subject = \\? ORDER BY `login_attempts`.`id` LIMIT \\?").
+		WithArgs("user:u1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "subject", "failures"}).AddRow(1, "user:u1", 3))
+
+	attempt, err := storage.LoginAttemptManager.RecordFailedAttempt("user:u1", now, windowStart)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, attempt.Failures)
+}
+
+func Test_Get_Login_Attempt_NotFound(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	defer mock.ExpectClose()
+
+	storage.Context = db
+
+	mock.ExpectQuery("SELECT \\* FROM `login_attempts` WHERE subject = \\? ORDER BY `login_attempts`.`id` LIMIT \\?").
+		WithArgs("ip:203.0.113.7", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "subject", "failures"}))
+
+	attempt, err := storage.LoginAttemptManager.GetLoginAttempt("ip:203.0.113.7")
+
+	assert.Nil(t, attempt)
+	assert.Equal(t, "Login attempt record not found in db", err.Error())
+}
@@ -0,0 +1,33 @@
+package storagetests
+
+import (
+	"testing"
+	"time"
+	"todo-web-api/storage"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func Test_Consume_One_Time_Token_Already_Used(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	defer mock.ExpectClose()
+
+	storage.Context = db
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `one_time_tokens` SET `used_at`=\\? WHERE token_hash = \\? AND purpose = \\? AND used_at IS NULL AND expires_at > \\?").
+		WithArgs(now, "digest", "password_reset", now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	token, err := storage.OneTimeTokenManager.ConsumeOneTimeToken("password_reset", "digest", now)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	assert.Nil(t, token)
+	assert.Equal(t, "One-time token record not found in db", err.Error())
+}
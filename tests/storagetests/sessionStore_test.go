@@ -0,0 +1,147 @@
+package storagetests
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"todo-web-api/messages"
+	"todo-web-api/storage"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+	"gorm.io/gorm"
+)
+
+func Test_Get_Session(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	storage.Context = db
+
+	expiresAt := time.Now().Add(time.Hour)
+
+	mock.ExpectQuery("SELECT \\* FROM `sessions` WHERE session_id = \\? ORDER BY `sessions`.`id` LIMIT \\?").
+		WithArgs("sid-1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "session_id", "user_id", "username", "access_token", "refresh_token", "expires_at"}).
+			AddRow(1, "sid-1", 1, "TestUser", "access_hash", "refresh_hash", expiresAt))
+
+	session, err := storage.SessionManager.GetSession("sid-1")
+
+	if err != nil {
+		t.Errorf("Failed to fetch session: %s", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	assert.Equal(t, "access_hash", session.AccessToken)
+	assert.Equal(t, "refresh_hash", session.RefreshToken)
+}
+
+func Test_Get_Session_Not_Found(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	storage.Context = db
+
+	mock.ExpectQuery("SELECT \\* FROM `sessions` WHERE session_id = \\? ORDER BY `sessions`.`id` LIMIT \\?").
+		WithArgs("sid-1", 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	_, err := storage.SessionManager.GetSession("sid-1")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	assert.EqualError(t, errors.New(messages.SessionNotFoundInDb), err.Error())
+}
+
+func Test_Delete_Expired_Sessions(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	storage.Context = db
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `sessions` WHERE expires_at < \\?").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	count, err := storage.SessionManager.DeleteExpiredSessions(time.Now())
+
+	if err != nil {
+		t.Errorf("Failed to delete expired sessions: %s", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	assert.Equal(t, int64(2), count)
+}
+
+func Test_Get_Sessions_For_User(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	storage.Context = db
+
+	expiresAt := time.Now().Add(time.Hour)
+
+	mock.ExpectQuery("SELECT \\* FROM `sessions` WHERE user_id = \\? ORDER BY created_at desc").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "session_id", "user_id", "username", "expires_at"}).
+			AddRow(1, "sid-1", 1, "TestUser", expiresAt).
+			AddRow(2, "sid-2", 1, "TestUser", expiresAt))
+
+	sessions, err := storage.SessionManager.GetSessionsForUser(1)
+
+	if err != nil {
+		t.Errorf("Failed to fetch sessions: %s", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	assert.Len(t, sessions, 2)
+}
+
+func Test_Rotate_Session_Tokens_Stale_Token(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	storage.Context = db
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `sessions` SET .* WHERE session_id = \\? AND refresh_token = \\?").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	rotated, err := storage.SessionManager.RotateSessionTokens("sid-1", "stale_hash", "access_hash", "refresh_hash", time.Now())
+
+	if err != nil {
+		t.Errorf("Failed to rotate session tokens: %s", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	assert.False(t, rotated)
+}
+
+func Test_Delete_Other_Sessions_Keeps_Current(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	defer mock.ExpectClose()
+
+	storage.Context = db
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `sessions` WHERE user_id = \\? AND session_id <> \\?").
+		WithArgs(1, "sid").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	count, err := storage.SessionManager.DeleteOtherSessions(1, "sid")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), count)
+}
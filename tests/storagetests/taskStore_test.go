@@ -3,8 +3,10 @@ package storagetests
 import (
 	"testing"
 	"time"
+	"todo-web-api/messages"
 	"todo-web-api/models"
 	"todo-web-api/storage"
+	"todo-web-api/storagelite"
 
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/DATA-DOG/go-sqlmock.v1"
@@ -26,8 +28,8 @@ func Test_Create_Task(t *testing.T) {
 	}
 
 	mock.ExpectBegin()
-	mock.ExpectExec("INSERT INTO `tasks` \\(`title`,`description`,`is_completed`,`list_id`,`created_at`,`id`\\) VALUES \\(\\?,\\?,\\?,\\?,\\?,\\?\\)").
-		WithArgs(task.Title, task.Description, task.IsCompleted, task.ListId, sqlmock.AnyArg(), task.Id).
+	mock.ExpectExec("INSERT INTO `tasks` \\(`title`,`description`,`is_completed`,`list_id`,`created_at`,`start_at`,`due_at`,`deleted_at`,`id`\\) VALUES \\(\\?,\\?,\\?,\\?,\\?,\\?,\\?,\\?,\\?\\)").
+		WithArgs(task.Title, task.Description, task.IsCompleted, task.ListId, sqlmock.AnyArg(), nil, nil, nil, task.Id).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
@@ -50,7 +52,7 @@ func Test_Get_Task_By_Id(t *testing.T) {
 	listID := 1
 	createdAt := time.Now()
 
-	mock.ExpectQuery("SELECT \\* FROM `tasks` WHERE `tasks`.`id` = \\? ORDER BY `tasks`.`id` LIMIT \\?").
+	mock.ExpectQuery("SELECT \\* FROM `tasks` WHERE `tasks`.`id` = \\? AND `tasks`.`deleted_at` IS NULL ORDER BY `tasks`.`id` LIMIT \\?").
 		WithArgs(taskID, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "is_completed", "list_id", "created_at"}).
 			AddRow(1, "New Task", "This is a task description", false, listID, createdAt))
@@ -76,14 +78,14 @@ func Test_Delete_Task(t *testing.T) {
 	listID := 1
 	createdAt := time.Now()
 
-	mock.ExpectQuery("SELECT \\* FROM `tasks` WHERE `tasks`.`id` = \\? ORDER BY `tasks`.`id` LIMIT \\?").
+	mock.ExpectQuery("SELECT \\* FROM `tasks` WHERE `tasks`.`id` = \\? AND `tasks`.`deleted_at` IS NULL ORDER BY `tasks`.`id` LIMIT \\?").
 		WithArgs(taskID, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "is_completed", "list_id", "created_at"}).
 			AddRow(1, "New Task", "This is a task description", false, listID, createdAt))
 
 	mock.ExpectBegin()
-	mock.ExpectExec("DELETE FROM `tasks` WHERE `tasks`.`id` = \\?").
-		WithArgs(taskID).
+	mock.ExpectExec("UPDATE `tasks` SET `deleted_at`=\\? WHERE `tasks`.`id` = \\? AND `tasks`.`deleted_at` IS NULL").
+		WithArgs(sqlmock.AnyArg(), taskID).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
@@ -103,3 +105,146 @@ func Test_Delete_Task(t *testing.T) {
 
 	assert.True(t, success)
 }
+
+func Test_Restore_Task(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	storage.Context = db
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `tasks` SET `deleted_at`=\\? WHERE id = \\? AND deleted_at IS NOT NULL").
+		WithArgs(nil, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `tasks` SET `deleted_at`=\\? WHERE id = \\? AND deleted_at IS NOT NULL").
+		WithArgs(nil, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	success, err := storage.TaskManager.RestoreTask(1)
+	assert.Nil(t, err)
+	assert.True(t, success)
+
+	// A task that isn't in the trash can't be restored.
+	success, err = storage.TaskManager.RestoreTask(2)
+	assert.EqualError(t, err, messages.TaskNotFoundInDb)
+	assert.False(t, success)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func Test_Get_Trashed_Tasks_For_User(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	storage.Context = db
+
+	mock.ExpectQuery("SELECT \\* FROM `tasks` WHERE deleted_at IS NOT NULL AND list_id IN \\(SELECT `id` FROM `lists` WHERE \\(user_id = \\? OR id IN \\(SELECT `list_id` FROM `list_members` WHERE user_id = \\? AND role = \\? AND accepted_at IS NOT NULL\\)\\) AND `lists`.`deleted_at` IS NULL\\) ORDER BY deleted_at desc, id").
+		WithArgs(1, 1, "editor").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "list_id", "deleted_at"}).
+			AddRow(5, "Milk", 10, time.Now()))
+
+	tasks, err := storage.TaskManager.GetTrashedTasksForUser(1)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	assert.Nil(t, err)
+	if assert.Len(t, tasks, 1) {
+		assert.True(t, tasks[0].DeletedAt.Valid)
+	}
+}
+
+func Test_Get_Due_Tasks_For_User(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	storage.Context = db
+	paris, _ := time.LoadLocation("Europe/Paris")
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, paris)
+	to := from.AddDate(0, 0, 1)
+
+	mock.ExpectQuery("SELECT \\* FROM `tasks` WHERE \\(list_id IN \\(SELECT `id` FROM `lists` WHERE \\(\\(user_id = \\? OR id IN \\(SELECT `list_id` FROM `list_members` WHERE user_id = \\? AND accepted_at IS NOT NULL\\)\\) AND archived_at IS NULL\\) AND `lists`.`deleted_at` IS NULL\\) AND due_at < \\?\\) AND due_at >= \\? AND is_completed = \\? AND `tasks`.`deleted_at` IS NULL ORDER BY due_at, id").
+		WithArgs(1, 1, to.UTC(), from.UTC(), false).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "list_id", "due_at"}).
+			AddRow(5, "Milk", 10, from.Add(time.Hour).UTC()))
+
+	tasks, err := storage.TaskManager.GetDueTasksForUser(1, from, to, true)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	assert.Nil(t, err)
+	if assert.Len(t, tasks, 1) && assert.NotNil(t, tasks[0].DueAt) {
+		assert.True(t, tasks[0].DueAt.Equal(from.Add(time.Hour)))
+	}
+}
+
+func Test_Due_Tasks_Across_Time_Zones(t *testing.T) {
+	Sqlite_Db_Setup(t)
+	(&storagelite.StoreManagerLite{}).Connect("", "", "", "", "")
+	storage.Sqlite()
+
+	own := &models.List{Title: "Groceries", UserId: 1}
+	shared := &models.List{Title: "Work", UserId: 2}
+	archived := &models.List{Title: "Old", UserId: 1, ArchivedAt: &time.Time{}}
+	other := &models.List{Title: "Not mine", UserId: 2}
+	for _, list := range []*models.List{own, shared, archived, other} {
+		storage.ListManager.CreateList(list)
+	}
+	accepted := time.Now()
+	storage.ListMemberManager.CreateListMember(&models.ListMember{ListId: shared.Id, UserId: 1, Role: "viewer", AcceptedAt: &accepted})
+
+	// 23:30 in New York is already the next day in UTC.
+	newYork, _ := time.LoadLocation("America/New_York")
+	at := func(day int, hour int, min int) *time.Time {
+		t := time.Date(2024, 5, day, hour, min, 0, 0, newYork).UTC()
+		return &t
+	}
+	late := &models.Task{Title: "Late", ListId: own.Id, DueAt: at(1, 23, 30)}
+	done := &models.Task{Title: "Done", ListId: own.Id, DueAt: at(1, 9, 0), IsCompleted: true}
+	report := &models.Task{Title: "Report", ListId: shared.Id, DueAt: at(1, 8, 0)}
+	for _, task := range []*models.Task{late, done, report,
+		{Title: "Tomorrow", ListId: own.Id, DueAt: at(2, 0, 0)},
+		{Title: "Someday", ListId: own.Id},
+		{Title: "Archived", ListId: archived.Id, DueAt: at(1, 10, 0)},
+		{Title: "Someone else's", ListId: other.Id, DueAt: at(1, 10, 0)}} {
+		storage.TaskManager.CreateTask(task, task.ListId)
+	}
+
+	titles := func(tasks []models.Task) []string {
+		var titles []string
+		for _, task := range tasks {
+			titles = append(titles, task.Title)
+		}
+		return titles
+	}
+
+	// May 1st in New York, whatever offset the bounds are given in.
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, newYork)
+	tasks, err := storage.TaskManager.GetDueTasksForUser(1, from, from.AddDate(0, 0, 1), false)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Report", "Done", "Late"}, titles(tasks))
+
+	tasks, err = storage.TaskManager.GetDueTasksForUser(1, time.Time{}, *at(1, 12, 0), true)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Report"}, titles(tasks))
+}
+
+func Test_Purge_Deleted_Tasks(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	storage.Context = db
+	before := time.Now().AddDate(0, 0, -30)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `tasks` WHERE deleted_at < \\?").
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	count, err := storage.TaskManager.PurgeDeletedTasks(before)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	assert.Nil(t, err)
+	assert.EqualValues(t, 3, count)
+}
@@ -4,8 +4,10 @@ import (
 	"errors"
 	"testing"
 	"time"
+	"todo-web-api/messages"
 	"todo-web-api/models"
 	"todo-web-api/storage"
+	"todo-web-api/storagelite"
 
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/DATA-DOG/go-sqlmock.v1"
@@ -29,7 +31,7 @@ func Test_Create_User_Successful(t *testing.T) {
 
 	mock.ExpectBegin()
 	mock.ExpectExec("INSERT INTO `users`").
-		WithArgs(newUser.Username, newUser.Password, newUser.CreatedAt, newUser.Id).
+		WithArgs(newUser.Username, newUser.Password, "user", false, "", false, nil, "", false, 0, nil, newUser.CreatedAt, newUser.Id).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	mock.ExpectCommit()
@@ -158,3 +160,143 @@ func Test_Find_Existing_Account(t *testing.T) {
 	assert.Equal(t, user.Username, newUser.Username)
 	assert.Equal(t, user.Id, newUser.Id)
 }
+
+func Test_Get_Users_Filtered(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	defer mock.ExpectClose()
+
+	storage.Context = db
+
+	mock.ExpectQuery("SELECT \\* FROM `users` WHERE username LIKE \\? ORDER BY id").
+		WithArgs("%admin%").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "role"}).AddRow(1, "admin", "admin"))
+
+	users, err := storage.UserManager.GetUsers("admin")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	assert.Nil(t, err)
+	assert.Len(t, users, 1)
+	assert.Equal(t, "admin", users[0].Role)
+}
+
+func Test_Get_User_By_Verified_Email_Not_Found(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+
+	storage.Context = db
+	mock.ExpectQuery("SELECT \\* FROM `users` WHERE verified_email = \\? ORDER BY `users`.`id` LIMIT \\?").
+		WithArgs("u1@example.com", 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	user, err := storage.UserManager.GetUserByVerifiedEmail("u1@example.com")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	assert.Nil(t, user)
+	assert.EqualError(t, errors.New("user not found"), err.Error())
+}
+
+func Test_Delete_User_Cascades(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	defer mock.ExpectClose()
+
+	storage.Context = db
+
+	mock.ExpectQuery("SELECT \\* FROM `users` WHERE `users`.`id` = \\? ORDER BY `users`.`id` LIMIT \\?").
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(1, "u1"))
+
+	mock.ExpectBegin()
+	for _, table := range []string{"tasks", "list_members"} {
+		mock.ExpectExec("DELETE FROM `" + table + "` WHERE list_id IN \\(SELECT `id` FROM `lists` WHERE user_id = \\?\\)").
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+	}
+	mock.ExpectExec("DELETE FROM `template_tasks` WHERE template_id IN \\(SELECT `id` FROM `list_templates` WHERE user_id = \\?\\)").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	for _, table := range []string{"lists", "list_members", "list_templates", "sessions", "recovery_codes", "personal_access_tokens", "one_time_tokens", "external_identities"} {
+		mock.ExpectExec("DELETE FROM `" + table + "` WHERE user_id = \\?").
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectExec("DELETE FROM `users` WHERE `users`.`id` = \\?").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	success, err := storage.UserManager.DeleteUser(1)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	assert.Nil(t, err)
+	assert.True(t, success)
+}
+
+func Test_Delete_User_Rolls_Back_On_Failure(t *testing.T) {
+	db, mock := Mock_Db_Setup()
+	defer mock.ExpectClose()
+
+	storage.Context = db
+
+	mock.ExpectQuery("SELECT \\* FROM `users` WHERE `users`.`id` = \\? ORDER BY `users`.`id` LIMIT \\?").
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(1, "u1"))
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `tasks`").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("DELETE FROM `list_members`").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM `template_tasks`").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM `lists`").
+		WithArgs(1).
+		WillReturnError(errors.New("lock wait timeout"))
+	mock.ExpectRollback()
+
+	success, err := storage.UserManager.DeleteUser(1)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	assert.NotNil(t, err)
+	assert.False(t, success)
+}
+
+func Test_Verified_Email_Is_Unique(t *testing.T) {
+	Sqlite_Db_Setup(t)
+	(&storagelite.StoreManagerLite{}).Connect("", "", "", "", "")
+	storage.Sqlite()
+
+	// Unverified accounts can share an address; none of them holds it.
+	email := "u1@example.com"
+	first := &models.User{Username: "u1", Password: "hash", Email: email}
+	second := &models.User{Username: "u2", Password: "hash", Email: email}
+	_, err := storage.UserManager.CreateUser(first)
+	assert.Nil(t, err)
+	_, err = storage.UserManager.CreateUser(second)
+	assert.Nil(t, err)
+	_, err = storage.UserManager.GetUserByVerifiedEmail(email)
+	assert.EqualError(t, err, messages.UserNotFound)
+
+	first.EmailVerified, first.VerifiedEmail = true, &email
+	_, err = storage.UserManager.UpdateUser(first)
+	assert.Nil(t, err)
+	holder, err := storage.UserManager.GetUserByVerifiedEmail(email)
+	if assert.Nil(t, err) {
+		assert.Equal(t, first.Id, holder.Id)
+	}
+
+	second.EmailVerified, second.VerifiedEmail = true, &email
+	_, err = storage.UserManager.UpdateUser(second)
+	assert.NotNil(t, err)
+}